@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -20,6 +21,20 @@ type Config struct {
 	DBSSLMode        string
 	JWTSecret        string
 	TelegramBotToken string
+
+	// Хранилище вложений к расходам
+	AttachmentStorage string // local или s3
+	AttachmentDir     string // Каталог для local
+	AttachmentMaxSize int64  // Максимальный размер файла в байтах
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKey       string
+	S3SecretKey       string
+	S3PathStyle       bool
+
+	// Корзина удаленных записей
+	TrashRetentionDays int // Через сколько дней удаленные записи удаляются безвозвратно, 0 — хранить без срока
 }
 
 func Load() (*Config, error) {
@@ -56,7 +71,28 @@ func Load() (*Config, error) {
 		UseDatabaseURL: useURL,
 		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", "8567102489:AAFACiJvXn4-DYXDFwhnQ1HhrlfJciGnxV8"),
 
+		AttachmentStorage: getEnv("ATTACHMENT_STORAGE", "local"),
+		AttachmentDir:     getEnv("ATTACHMENT_DIR", "./data/attachments"),
+		S3Endpoint:        getEnv("S3_ENDPOINT", ""),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:          getEnv("S3_BUCKET", ""),
+		S3AccessKey:       getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:       getEnv("S3_SECRET_KEY", ""),
+		S3PathStyle:       getEnv("S3_PATH_STYLE", "false") == "true",
+
+	}
+
+	maxSize, err := strconv.ParseInt(getEnv("ATTACHMENT_MAX_SIZE", "10485760"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("ATTACHMENT_MAX_SIZE: %w", err)
 	}
+	cfg.AttachmentMaxSize = maxSize
+
+	retentionDays, err := strconv.Atoi(getEnv("TRASH_RETENTION_DAYS", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("TRASH_RETENTION_DAYS: %w", err)
+	}
+	cfg.TrashRetentionDays = retentionDays
 
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("валидация конфигурации: %w", err)
@@ -69,6 +105,12 @@ func (c *Config) validate() error {
 	if c.ServerAddress == "" {
 		return fmt.Errorf("SERVER_ADDRESS не может быть пустым")
 	}
+	if c.AttachmentMaxSize <= 0 {
+		return fmt.Errorf("ATTACHMENT_MAX_SIZE должен быть больше нуля")
+	}
+	if c.TrashRetentionDays < 0 {
+		return fmt.Errorf("TRASH_RETENTION_DAYS не может быть отрицательным")
+	}
 	return nil
 }
 
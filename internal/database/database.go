@@ -108,15 +108,158 @@ func Migrate() error {
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.Category{},
+		&models.Account{},
+		&models.Transfer{},
+		&models.ExchangeRate{},
+		&models.Tag{},
+		&models.CategoryRule{},
 		&models.Expense{},
+		&models.ExpenseSplit{},
+		&models.Attachment{},
+		&models.Income{},
 		&models.Budget{},
+		&models.BudgetCategory{},
 		&models.RecurringExpense{},
+		&models.InstallmentPlan{},
 		&models.ActivityHistory{},
 	)
 	if err != nil {
 		return fmt.Errorf("ошибка миграции: %w", err)
 	}
 
+	if err := migrateExchangeRates(); err != nil {
+		return fmt.Errorf("ошибка миграции курсов валют: %w", err)
+	}
+
+	if err := migrateMoneyColumns(); err != nil {
+		return fmt.Errorf("ошибка миграции денежных столбцов: %w", err)
+	}
+
+	if err := migrateExpenseSearch(); err != nil {
+		return fmt.Errorf("ошибка миграции полнотекстового поиска: %w", err)
+	}
+
+	// Расходы, созданные до поддержки валют, записаны в базовой валюте
+	if err := DB.Exec("UPDATE expenses SET original_amount = amount WHERE original_amount = 0").Error; err != nil {
+		return fmt.Errorf("ошибка миграции сумм расходов: %w", err)
+	}
+
+	return nil
+}
+
+// migrateExchangeRates переводит общую таблицу курсов на курсы пользователей: старый уникальный индекс
+// без user_id удаляется, а курсы, созданные до привязки к пользователям, копируются каждому пользователю.
+// Повторный запуск ничего не делает.
+func migrateExchangeRates() error {
+	migrator := DB.Migrator()
+	if migrator.HasIndex(&models.ExchangeRate{}, "idx_exchange_rate_pair_date") {
+		if err := migrator.DropIndex(&models.ExchangeRate{}, "idx_exchange_rate_pair_date"); err != nil {
+			return err
+		}
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO exchange_rates (created_at, updated_at, user_id, from_currency, to_currency, date, rate)
+			SELECT r.created_at, r.updated_at, u.id, r.from_currency, r.to_currency, r.date, r.rate
+			FROM exchange_rates r CROSS JOIN users u
+			WHERE r.user_id = 0 AND r.deleted_at IS NULL AND u.deleted_at IS NULL
+			ON CONFLICT DO NOTHING`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM exchange_rates WHERE user_id = 0").Error
+	})
+}
+
+// migrateExpenseSearch создает поисковый документ расходов search_vector с GIN-индексом. Документ включает
+// название категории, поэтому его нельзя описать индексом по выражению: столбец заполняют триггеры
+// при изменении описания или категории расхода и при переименовании категории.
+// Повторный запуск ничего не меняет.
+func migrateExpenseSearch() error {
+	statements := []string{
+		`ALTER TABLE expenses ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+		`CREATE OR REPLACE FUNCTION expense_search_vector(description text, category_id bigint) RETURNS tsvector AS $$
+			SELECT to_tsvector('russian', concat_ws(' ', $1,
+				(SELECT categories.name FROM categories WHERE categories.id = $2)))
+		$$ LANGUAGE sql STABLE`,
+		`CREATE OR REPLACE FUNCTION expenses_search_vector_trigger() RETURNS trigger AS $$
+		BEGIN
+			NEW.search_vector := expense_search_vector(NEW.description, NEW.category_id);
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS expenses_search_vector ON expenses`,
+		`CREATE TRIGGER expenses_search_vector BEFORE INSERT OR UPDATE OF description, category_id ON expenses
+			FOR EACH ROW EXECUTE FUNCTION expenses_search_vector_trigger()`,
+		`CREATE OR REPLACE FUNCTION categories_search_vector_trigger() RETURNS trigger AS $$
+		BEGIN
+			UPDATE expenses SET search_vector = expense_search_vector(expenses.description, expenses.category_id)
+			WHERE expenses.category_id = NEW.id;
+			RETURN NULL;
+		END
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS categories_search_vector ON categories`,
+		`CREATE TRIGGER categories_search_vector AFTER UPDATE OF name ON categories
+			FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name) EXECUTE FUNCTION categories_search_vector_trigger()`,
+		`UPDATE expenses SET search_vector = expense_search_vector(description, category_id) WHERE search_vector IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_expenses_search_vector ON expenses USING GIN (search_vector)`,
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateMoneyColumns расширяет денежные столбцы, созданные как decimal(10,2), до numeric(14,2).
+// Значения сохраняются без изменений, повторный запуск ничего не делает.
+func migrateMoneyColumns() error {
+	columns := []struct {
+		model interface{}
+		field string
+	}{
+		{&models.Expense{}, "Amount"},
+		{&models.Expense{}, "OriginalAmount"},
+		{&models.Income{}, "Amount"},
+		{&models.Budget{}, "Amount"},
+		{&models.BudgetCategory{}, "Amount"},
+		{&models.RecurringExpense{}, "Amount"},
+		{&models.Account{}, "OpeningBalance"},
+		{&models.Transfer{}, "Amount"},
+	}
+
+	migrator := DB.Migrator()
+	for _, column := range columns {
+		columnTypes, err := migrator.ColumnTypes(column.model)
+		if err != nil {
+			return err
+		}
+
+		stmt := &gorm.Statement{DB: DB}
+		if err := stmt.Parse(column.model); err != nil {
+			return err
+		}
+		field := stmt.Schema.LookUpField(column.field)
+		if field == nil {
+			return fmt.Errorf("поле %s не найдено", column.field)
+		}
+
+		for _, columnType := range columnTypes {
+			if columnType.Name() != field.DBName {
+				continue
+			}
+			if precision, scale, ok := columnType.DecimalSize(); ok && (precision != 14 || scale != 2) {
+				if err := migrator.AlterColumn(column.model, column.field); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
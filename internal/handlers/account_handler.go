@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	service services.AccountService
+	logger  *slog.Logger
+}
+
+func NewAccountHandler(service services.AccountService, logger *slog.Logger) *AccountHandler {
+	return &AccountHandler{service: service, logger: logger}
+}
+
+func (h *AccountHandler) RegisterRoutes(r *gin.RouterGroup) {
+	accounts := r.Group("/accounts")
+	{
+		accounts.GET("", h.List)
+		accounts.POST("", h.Create)
+		accounts.GET("/:id", h.Get)
+		accounts.PATCH("/:id", h.Update)
+		accounts.DELETE("/:id", h.Delete)
+	}
+
+	transfers := r.Group("/transfers")
+	{
+		transfers.GET("", h.ListTransfers)
+		transfers.POST("", h.CreateTransfer)
+		transfers.GET("/:id", h.GetTransfer)
+		transfers.DELETE("/:id", h.DeleteTransfer)
+	}
+}
+
+// -------- ACCOUNTS --------
+
+func (h *AccountHandler) List(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	accounts, err := h.service.GetAccountList(userID)
+	if err != nil {
+		h.logger.Error("failed to get account list",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+func (h *AccountHandler) Create(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.CreateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := h.service.CreateAccount(userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+func (h *AccountHandler) Get(c *gin.Context) {
+	account, ok := h.loadAccount(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *AccountHandler) Update(c *gin.Context) {
+	account, ok := h.loadAccount(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.UpdateAccount(account.ID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *AccountHandler) Delete(c *gin.Context) {
+	account, ok := h.loadAccount(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAccount(account.ID); err != nil {
+		if errors.Is(err, services.ErrAccountInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account deleted"})
+}
+
+// -------- TRANSFERS --------
+
+func (h *AccountHandler) ListTransfers(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var accountID *uint
+	if v := c.Query("account_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account_id"})
+			return
+		}
+		value := uint(id)
+		accountID = &value
+	}
+
+	transfers, err := h.service.GetTransferList(userID, accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+func (h *AccountHandler) CreateTransfer(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.CreateTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.service.CreateTransfer(userID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrAccountNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+func (h *AccountHandler) GetTransfer(c *gin.Context) {
+	transfer, ok := h.loadTransfer(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *AccountHandler) DeleteTransfer(c *gin.Context) {
+	transfer, ok := h.loadTransfer(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTransfer(transfer.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// -------- HELPERS --------
+
+// loadAccount читает счет из параметра :id и проверяет, что он принадлежит текущему пользователю
+func (h *AccountHandler) loadAccount(c *gin.Context) (*models.Account, bool) {
+	userID := c.GetUint("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+
+	account, err := h.service.GetAccountByID(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrAccountNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if account.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	return account, true
+}
+
+// loadTransfer читает перевод из параметра :id и проверяет, что он принадлежит текущему пользователю
+func (h *AccountHandler) loadTransfer(c *gin.Context) (*models.Transfer, bool) {
+	userID := c.GetUint("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+
+	transfer, err := h.service.GetTransferByID(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrTransferNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if transfer.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	return transfer, true
+}
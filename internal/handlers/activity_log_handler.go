@@ -6,7 +6,6 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -21,11 +20,11 @@ func NewActivityLogHandler(service services.ActivityLogService, logger *slog.Log
 	return &ActivityLogHandler{service: service, logger: logger}
 }
 
-func (h *ActivityLogHandler) RegisterRoutes(r *gin.Engine) {
+func (h *ActivityLogHandler) RegisterRoutes(r *gin.RouterGroup) {
 	logs := r.Group("/logs")
 	{
+		// Записи журнала создают только сервисы, поэтому маршрута на создание нет
 		logs.GET("", h.Get)
-		logs.POST("", h.Create)
 	}
 }
 
@@ -53,12 +52,20 @@ func (h *ActivityLogHandler) Get(c *gin.Context) {
 		slog.Any("entity_type", filter.EntityType),
 		slog.Any("start_date", filter.StartDate),
 		slog.Any("end_date", filter.EndDate),
-		slog.Any("limit", filter.Limit),
-		slog.Any("offset", filter.Offset),
 	)
 
-	logs, err := h.service.GetActivityLogs(filter)
+	page, err := parsePageRequest(c, []string{models.SortByCreatedAt}, models.SortByCreatedAt, models.SortDesc)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logs, err := h.service.GetActivityLogs(filter, page)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("service.GetActivityLogs failed",
 			slog.String("error", err.Error()),
 			slog.Uint64("user_id", uint64(filter.UserID)),
@@ -68,69 +75,18 @@ func (h *ActivityLogHandler) Get(c *gin.Context) {
 	}
 
 	h.logger.Info("activity logs returned",
-		slog.Int("count", len(logs)),
+		slog.Int("count", len(logs.Items)),
 		slog.Uint64("user_id", uint64(filter.UserID)),
 	)
 
 	c.JSON(http.StatusOK, logs)
 }
 
-func (h *ActivityLogHandler) Create(c *gin.Context) {
-	h.logger.Info("handling POST /logs",
-		slog.String("method", c.Request.Method),
-		slog.String("path", c.FullPath()),
-	)
-
-	var req models.CreateActivityLogRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("invalid JSON body",
-			slog.String("error", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON body"})
-		return
-	}
-
-	h.logger.Debug("request body parsed",
-		slog.Uint64("user_id", uint64(req.UserID)),
-		slog.String("activity_type", string(req.ActivityType)),
-		slog.String("entity_type", req.EntityType),
-		slog.Uint64("entity_id", uint64(req.EntityID)),
-	)
-
-	logEntry, err := h.service.CreateActivityLog(req)
-	if err != nil {
-		h.logger.Error("failed to create activity log",
-			slog.String("error", err.Error()),
-			slog.Uint64("user_id", uint64(req.UserID)),
-			slog.String("activity_type", string(req.ActivityType)),
-		)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create log entry"})
-		return
-	}
-
-	h.logger.Info("activity log created",
-		slog.Int("log_id", int(logEntry.ID)),
-		slog.Uint64("user_id", uint64(logEntry.UserID)),
-		slog.String("activity_type", string(logEntry.ActivityType)),
-	)
-
-	c.JSON(http.StatusCreated, logEntry)
-}
-
 func (h *ActivityLogHandler) parseActivityFilter(c *gin.Context) (models.ActivityFilter, error) {
 	var filter models.ActivityFilter
 
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		return filter, errors.New("user_id is required")
-	}
-
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil || userIDUint == 0 {
-		return filter, errors.New("invalid user_id")
-	}
-
-	filter.UserID = uint(userIDUint)
+	// Журнал доступен только владельцу, поэтому пользователь берется из токена, а не из запроса
+	filter.UserID = c.GetUint("user_id")
 
 	if v := c.Query("activity_type"); v != "" {
 		at := models.ActivityType(v)
@@ -158,21 +114,5 @@ func (h *ActivityLogHandler) parseActivityFilter(c *gin.Context) (models.Activit
 		filter.EndDate = &end
 	}
 
-	if v := c.Query("limit"); v != "" {
-		n, err := strconv.Atoi(v)
-		if err != nil || n <= 0 {
-			return filter, errors.New("invalid limit")
-		}
-		filter.Limit = &n
-	}
-
-	if v := c.Query("offset"); v != "" {
-		n, err := strconv.Atoi(v)
-		if err != nil || n < 0 {
-			return filter, errors.New("invalid offset")
-		}
-		filter.Offset = &n
-	}
-
 	return filter, nil
 }
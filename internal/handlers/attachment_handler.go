@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentFormOverhead запас на заголовки multipart-формы сверх размера файла
+const attachmentFormOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	service  services.AttachmentService
+	expenses services.ExpenseService
+	maxSize  int64
+	logger   *slog.Logger
+}
+
+func NewAttachmentHandler(service services.AttachmentService, expenses services.ExpenseService, maxSize int64, logger *slog.Logger) *AttachmentHandler {
+	return &AttachmentHandler{service: service, expenses: expenses, maxSize: maxSize, logger: logger}
+}
+
+func (h *AttachmentHandler) RegisterRoutes(r *gin.RouterGroup) {
+	attachments := r.Group("/expenses/:id/attachments")
+	{
+		attachments.GET("", h.List)
+		attachments.POST("", h.Upload)
+		attachments.GET("/:attachmentId", h.Download)
+		attachments.DELETE("/:attachmentId", h.Delete)
+	}
+}
+
+func (h *AttachmentHandler) List(c *gin.Context) {
+	expense, ok := h.loadExpense(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.service.GetAttachmentList(expense.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// Upload прикрепляет к расходу файл из поля формы "file"
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	expense, ok := h.loadExpense(c)
+	if !ok {
+		return
+	}
+
+	// Слишком большой запрос обрывается при чтении, не дожидаясь сохранения формы во временный файл
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+attachmentFormOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrAttachmentTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "файл не передан"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.service.UploadAttachment(expense, fileHeader.Filename, file, fileHeader.Size)
+	if err != nil {
+		h.logger.Warn("attachment upload failed",
+			slog.Uint64("expense_id", uint64(expense.ID)),
+			slog.String("filename", fileHeader.Filename),
+			slog.String("error", err.Error()),
+		)
+		switch {
+		case errors.Is(err, services.ErrAttachmentEmpty):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAttachmentTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAttachmentType):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAttachmentLimit):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// Download отдает содержимое вложения с исходным именем файла
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	attachment, ok := h.loadAttachment(c)
+	if !ok {
+		return
+	}
+
+	content, err := h.service.OpenAttachment(attachment)
+	if err != nil {
+		if errors.Is(err, services.ErrAttachmentFileMissing) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	disposition := "attachment"
+	if c.Query("inline") == "true" {
+		disposition = "inline"
+	}
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	attachment, ok := h.loadAttachment(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAttachment(attachment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "attachment deleted"})
+}
+
+// loadExpense читает расход из параметра :id и проверяет, что он принадлежит текущему пользователю
+func (h *AttachmentHandler) loadExpense(c *gin.Context) (*models.Expense, bool) {
+	userID := c.GetUint("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+
+	expense, err := h.expenses.GetExpenseByID(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if expense.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	return expense, true
+}
+
+// loadAttachment проверяет доступ к расходу и читает его вложение из параметра :attachmentId
+func (h *AttachmentHandler) loadAttachment(c *gin.Context) (*models.Attachment, bool) {
+	expense, ok := h.loadExpense(c)
+	if !ok {
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("attachmentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment id"})
+		return nil, false
+	}
+
+	attachment, err := h.service.GetAttachmentByID(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrAttachmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	// Вложение другого расхода считается ненайденным, чтобы не раскрывать его существование
+	if attachment.ExpenseID != expense.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrAttachmentNotFound.Error()})
+		return nil, false
+	}
+
+	return attachment, true
+}
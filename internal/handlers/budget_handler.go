@@ -3,9 +3,11 @@ package handlers
 import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/services"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,6 +32,8 @@ func (h *BudgetHandler) RegisterRoutes(r *gin.RouterGroup) {
 		budgets.GET("/:id", h.Get)
 		budgets.PATCH("/:id", h.Update)
 		budgets.DELETE("/:id", h.Delete)
+		budgets.PUT("/:id/categories", h.SetCategory)
+		budgets.DELETE("/:id/categories/:category_id", h.DeleteCategory)
 	}
 }
 
@@ -57,8 +61,18 @@ func (h *BudgetHandler) List(c *gin.Context) {
 	}
 	userID := uint(userIDUint)
 
-	budgets, err := h.service.GetBudgetList(userID)
+	page, err := parsePageRequest(c, []string{models.SortByDate, models.SortByAmount, models.SortByCreatedAt}, models.SortByDate, models.SortDesc)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	budgets, err := h.service.GetBudgetList(userID, page)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("failed to get budget list",
 			slog.Uint64("user_id", uint64(userID)),
 			slog.String("error", err.Error()),
@@ -69,7 +83,7 @@ func (h *BudgetHandler) List(c *gin.Context) {
 
 	h.logger.Info("budget list retrieved",
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Int("count", len(budgets)),
+		slog.Int("count", len(budgets.Items)),
 	)
 
 	c.JSON(http.StatusOK, budgets)
@@ -133,6 +147,10 @@ func (h *BudgetHandler) Create(c *gin.Context) {
 			slog.Uint64("user_id", uint64(userID)),
 			slog.String("error", err.Error()),
 		)
+		if errors.Is(err, services.ErrBudgetPeriodConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -140,7 +158,8 @@ func (h *BudgetHandler) Create(c *gin.Context) {
 	h.logger.Info("budget created",
 		slog.Uint64("budget_id", uint64(budget.ID)),
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Float64("amount", budget.Amount),
+		slog.String("amount", budget.Amount.String()),
+		slog.String("period_type", string(budget.PeriodType)),
 		slog.Int("month", budget.Month),
 		slog.Int("year", budget.Year),
 	)
@@ -228,6 +247,10 @@ func (h *BudgetHandler) Update(c *gin.Context) {
 			slog.Uint64("budget_id", id),
 			slog.String("error", err.Error()),
 		)
+		if errors.Is(err, services.ErrBudgetPeriodConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -303,6 +326,37 @@ func (h *BudgetHandler) GetStatus(c *gin.Context) {
 	}
 	userID := uint(userIDUint)
 
+	// Статус периода, в который попадает дата (недельные, квартальные и другие периоды)
+	if dateStr := c.Query("date"); dateStr != "" {
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			h.logger.Warn("invalid date parameter",
+				slog.String("raw_date", dateStr),
+				slog.String("reason", err.Error()),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный date"})
+			return
+		}
+
+		status, err := h.service.GetBudgetStatusAt(userID, date)
+		if err != nil {
+			if err == services.ErrBudgetNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			h.logger.Error("failed to get budget status",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("date", dateStr),
+				slog.String("error", err.Error()),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, status)
+		return
+	}
+
 	monthStr := c.Query("month")
 	if monthStr == "" {
 		h.logger.Warn("missing month parameter")
@@ -362,8 +416,8 @@ func (h *BudgetHandler) GetStatus(c *gin.Context) {
 		slog.Uint64("user_id", uint64(userID)),
 		slog.Int("month", month),
 		slog.Int("year", year),
-		slog.Float64("spent", status.Spent),
-		slog.Float64("remaining", status.Remaining),
+		slog.String("spent", status.Spent.String()),
+		slog.String("remaining", status.Remaining.String()),
 		slog.Float64("percentage", status.Percentage),
 	)
 
@@ -458,3 +512,131 @@ func (h *BudgetHandler) GetByMonth(c *gin.Context) {
 
 	c.JSON(http.StatusOK, budget)
 }
+
+func (h *BudgetHandler) SetCategory(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid budget id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return
+	}
+
+	if !h.checkBudgetOwner(c, uint(id)) {
+		return
+	}
+
+	var req models.SetBudgetCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	budgetCategory, err := h.service.SetBudgetCategory(uint(id), req)
+	if err != nil {
+		if err == services.ErrBudgetNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Warn("failed to set budget category",
+			slog.Uint64("budget_id", id),
+			slog.Uint64("category_id", uint64(req.CategoryID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("budget category set",
+		slog.Uint64("budget_id", id),
+		slog.Uint64("category_id", uint64(req.CategoryID)),
+	)
+
+	c.JSON(http.StatusOK, budgetCategory)
+}
+
+func (h *BudgetHandler) DeleteCategory(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+		slog.String("raw_category_id", c.Param("category_id")),
+	)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid budget id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return
+	}
+
+	categoryID, err := strconv.ParseUint(c.Param("category_id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid category id",
+			slog.String("raw_category_id", c.Param("category_id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор категории"})
+		return
+	}
+
+	if !h.checkBudgetOwner(c, uint(id)) {
+		return
+	}
+
+	if err := h.service.DeleteBudgetCategory(uint(id), uint(categoryID)); err != nil {
+		if err == services.ErrBudgetCategoryNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to delete budget category",
+			slog.Uint64("budget_id", id),
+			slog.Uint64("category_id", categoryID),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("budget category deleted",
+		slog.Uint64("budget_id", id),
+		slog.Uint64("category_id", categoryID),
+	)
+
+	c.Status(http.StatusOK)
+}
+
+// checkBudgetOwner проверяет, что бюджет принадлежит текущему пользователю.
+// При ошибке ответ уже записан в контекст.
+func (h *BudgetHandler) checkBudgetOwner(c *gin.Context, id uint) bool {
+	budget, err := h.service.GetBudgetByID(id)
+	if err != nil {
+		if err == services.ErrBudgetNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if budget.UserID != c.GetUint("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return false
+	}
+
+	return true
+}
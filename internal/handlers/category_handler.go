@@ -3,6 +3,7 @@ package handlers
 import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/services"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -25,9 +26,11 @@ func (h *CategoryHandler) RegisterRoutes(r *gin.RouterGroup) {
 	{
 		categories.GET("", h.List)
 		categories.POST("", h.Create)
+		categories.GET("/tree", h.Tree)
 		categories.GET("/:id", h.Get)
 		categories.PATCH("/:id", h.Update)
 		categories.DELETE("/:id", h.Delete)
+		categories.POST("/:id/merge", h.Merge)
 	}
 }
 
@@ -49,6 +52,17 @@ func (h *CategoryHandler) List(c *gin.Context) {
 		return
 	}
 
+	// Фильтр по типу категории (expense или income)
+	if categoryType := c.Query("type"); categoryType != "" {
+		filtered := make([]models.Category, 0, len(categories))
+		for _, category := range categories {
+			if string(category.Type) == categoryType {
+				filtered = append(filtered, category)
+			}
+		}
+		categories = filtered
+	}
+
 	h.logger.Info("category list retrieved",
 		slog.Uint64("user_id", uint64(userID)),
 		slog.Int("count", len(categories)),
@@ -57,6 +71,23 @@ func (h *CategoryHandler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, categories)
 }
 
+// Tree возвращает категории пользователя в виде дерева (корневые категории с вложенными children)
+func (h *CategoryHandler) Tree(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	tree, err := h.service.GetCategoryTree(userID)
+	if err != nil {
+		h.logger.Error("failed to get category tree",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
 func (h *CategoryHandler) Create(c *gin.Context) {
 	h.logger.Info("incoming request",
 		slog.String("method", c.Request.Method),
@@ -173,11 +204,76 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteCategory(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// Категорию с операциями можно удалить только с переносом операций в target_category_id
+	var targetID *uint
+	if raw := c.Query("target_category_id"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target_category_id"})
+			return
+		}
+		target := uint(parsed)
+		targetID = &target
+	}
+
+	if err := h.service.DeleteCategory(uint(id), targetID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrCategoryInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		// Отсутствующая, чужая или неподходящая целевая категория
+		case errors.Is(err, services.ErrCategoryNotFound), errors.Is(err, services.ErrCategoryMergeInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "category deleted"})
 }
 
+// Merge переносит операции и подкатегории в целевую категорию и удаляет исходную
+func (h *CategoryHandler) Merge(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	category, err := h.service.GetCategoryByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if category.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	var req models.MergeCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.MergeCategory(uint(id), req.TargetCategoryID)
+	if err != nil {
+		h.logger.Warn("failed to merge category",
+			slog.Uint64("category_id", id),
+			slog.Uint64("target_category_id", uint64(req.TargetCategoryID)),
+			slog.String("error", err.Error()),
+		)
+		if errors.Is(err, services.ErrCategoryNotFound) || errors.Is(err, services.ErrCategoryMergeInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
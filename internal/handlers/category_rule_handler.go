@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryRuleHandler struct {
+	service services.CategoryRuleService
+	logger  *slog.Logger
+}
+
+func NewCategoryRuleHandler(service services.CategoryRuleService, logger *slog.Logger) *CategoryRuleHandler {
+	return &CategoryRuleHandler{service: service, logger: logger}
+}
+
+func (h *CategoryRuleHandler) RegisterRoutes(r *gin.RouterGroup) {
+	rules := r.Group("/category-rules")
+	{
+		rules.GET("", h.List)
+		rules.POST("", h.Create)
+		rules.POST("/apply", h.Apply)
+		rules.GET("/:id", h.Get)
+		rules.PATCH("/:id", h.Update)
+		rules.DELETE("/:id", h.Delete)
+	}
+}
+
+func (h *CategoryRuleHandler) List(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	rules, err := h.service.GetRuleList(userID)
+	if err != nil {
+		h.logger.Error("failed to get category rule list",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *CategoryRuleHandler) Create(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.CreateCategoryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.service.CreateRule(userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *CategoryRuleHandler) Get(c *gin.Context) {
+	rule, ok := h.loadRule(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *CategoryRuleHandler) Update(c *gin.Context) {
+	rule, ok := h.loadRule(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCategoryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.UpdateRule(rule.ID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *CategoryRuleHandler) Delete(c *gin.Context) {
+	rule, ok := h.loadRule(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRule(rule.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category rule deleted"})
+}
+
+// Apply повторно распределяет по правилам расходы из указанной категории, например «Прочее»
+func (h *CategoryRuleHandler) Apply(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.ApplyCategoryRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.ApplyRules(userID, req)
+	if err != nil {
+		h.logger.Warn("failed to apply category rules",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// loadRule читает правило из параметра :id и проверяет, что оно принадлежит текущему пользователю
+func (h *CategoryRuleHandler) loadRule(c *gin.Context) (*models.CategoryRule, bool) {
+	userID := c.GetUint("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+
+	rule, err := h.service.GetRuleByID(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrCategoryRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if rule.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	return rule, true
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategorySuggestionHandler struct {
+	service services.CategorySuggestionService
+	logger  *slog.Logger
+}
+
+func NewCategorySuggestionHandler(service services.CategorySuggestionService, logger *slog.Logger) *CategorySuggestionHandler {
+	return &CategorySuggestionHandler{service: service, logger: logger}
+}
+
+func (h *CategorySuggestionHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/expenses/suggest-category", h.Suggest)
+}
+
+// Suggest возвращает вероятные категории для описания и суммы будущего расхода
+func (h *CategorySuggestionHandler) Suggest(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.CategorySuggestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.SuggestCategories(userID, req)
+	if err != nil {
+		h.logger.Error("failed to suggest categories",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CurrencyHandler struct {
+	service services.CurrencyService
+	logger  *slog.Logger
+}
+
+func NewCurrencyHandler(service services.CurrencyService, logger *slog.Logger) *CurrencyHandler {
+	return &CurrencyHandler{service: service, logger: logger}
+}
+
+func (h *CurrencyHandler) RegisterRoutes(r *gin.RouterGroup) {
+	currency := r.Group("/currency")
+	{
+		currency.GET("/base", h.GetBase)
+		currency.PUT("/base", h.SetBase)
+	}
+
+	rates := r.Group("/exchange-rates")
+	{
+		rates.GET("", h.ListRates)
+		rates.POST("", h.CreateRate)
+		rates.POST("/import", h.ImportRates)
+		rates.DELETE("/:id", h.DeleteRate)
+	}
+}
+
+// -------- BASE CURRENCY --------
+
+func (h *CurrencyHandler) GetBase(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	currency, err := h.service.GetBaseCurrency(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"currency": currency})
+}
+
+func (h *CurrencyHandler) SetBase(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.SetBaseCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.service.SetBaseCurrency(userID, req.Currency)
+	if err != nil {
+		h.logger.Warn("failed to set base currency",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"currency": user.BaseCurrency})
+}
+
+// -------- EXCHANGE RATES --------
+
+func (h *CurrencyHandler) ListRates(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	rates, err := h.service.GetRateList(userID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+func (h *CurrencyHandler) CreateRate(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.CreateExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate, err := h.service.CreateRate(userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+// ImportRates принимает CSV-файл в поле формы "file"
+func (h *CurrencyHandler) ImportRates(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "файл не передан"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportRates(userID, file)
+	if err != nil {
+		h.logger.Warn("exchange rate import failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("filename", fileHeader.Filename),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *CurrencyHandler) DeleteRate(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	rate, err := h.service.GetRateByID(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrExchangeRateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if rate.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	if err := h.service.DeleteRate(rate.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
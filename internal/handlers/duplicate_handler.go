@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DuplicateHandler struct {
+	service services.DuplicateService
+	logger  *slog.Logger
+}
+
+func NewDuplicateHandler(service services.DuplicateService, logger *slog.Logger) *DuplicateHandler {
+	return &DuplicateHandler{service: service, logger: logger}
+}
+
+func (h *DuplicateHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/expenses/duplicates", h.List)
+	r.POST("/expenses/duplicates/merge", h.Merge)
+}
+
+// List возвращает группы возможных дубликатов расходов текущего пользователя
+func (h *DuplicateHandler) List(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	filter, err := parseDuplicateFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.UserID = userID
+
+	groups, err := h.service.FindDuplicates(filter)
+	if err != nil {
+		h.logger.Warn("failed to find duplicate expenses",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// Merge оставляет расход keep_id и удаляет расходы duplicate_ids
+func (h *DuplicateHandler) Merge(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.MergeDuplicatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.MergeDuplicates(userID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func parseDuplicateFilter(c *gin.Context) (models.DuplicateFilter, error) {
+	filter := models.DuplicateFilter{
+		WindowDays:    models.DefaultDuplicateWindowDays,
+		MinSimilarity: models.DefaultDuplicateSimilarity,
+	}
+
+	if v := c.Query("days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 0 || days > 31 {
+			return filter, errors.New("days должен быть числом от 0 до 31")
+		}
+		filter.WindowDays = days
+	}
+	if v := c.Query("similarity"); v != "" {
+		similarity, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return filter, errors.New("similarity должен быть числом от 0 до 1")
+		}
+		filter.MinSimilarity = similarity
+	}
+	if v := c.Query("start_date"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			filter.StartDate = &t
+		}
+	}
+	if v := c.Query("end_date"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			filter.EndDate = &t
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExpenseBulkHandler struct {
+	service services.ExpenseService
+	logger  *slog.Logger
+}
+
+func NewExpenseBulkHandler(service services.ExpenseService, logger *slog.Logger) *ExpenseBulkHandler {
+	return &ExpenseBulkHandler{service: service, logger: logger}
+}
+
+func (h *ExpenseBulkHandler) RegisterRoutes(r *gin.RouterGroup) {
+	bulk := r.Group("/expenses/bulk")
+	{
+		bulk.POST("/recategorize", h.Recategorize)
+		bulk.POST("/describe", h.Describe)
+		bulk.POST("/shift-dates", h.ShiftDates)
+		bulk.POST("/delete", h.Delete)
+	}
+}
+
+// Recategorize переносит выбранные расходы в категорию category_id
+func (h *ExpenseBulkHandler) Recategorize(c *gin.Context) {
+	var req models.BulkRecategorizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := c.GetUint("user_id")
+	selection, ok := bindExpenseSelection(c, userID, req.BulkExpenseSelection)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.BulkRecategorize(userID, selection, req.CategoryID)
+	h.respond(c, result, err)
+}
+
+// Describe задает выбранным расходам описание description или заменяет в описаниях find на replace
+func (h *ExpenseBulkHandler) Describe(c *gin.Context) {
+	var req models.BulkDescribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := c.GetUint("user_id")
+	selection, ok := bindExpenseSelection(c, userID, req.BulkExpenseSelection)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.BulkRewriteDescriptions(userID, selection, req.DescriptionRewrite)
+	h.respond(c, result, err)
+}
+
+// ShiftDates сдвигает даты выбранных расходов на months месяцев и days дней
+func (h *ExpenseBulkHandler) ShiftDates(c *gin.Context) {
+	var req models.BulkShiftDatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := c.GetUint("user_id")
+	selection, ok := bindExpenseSelection(c, userID, req.BulkExpenseSelection)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.BulkShiftDates(userID, selection, req.Months, req.Days)
+	h.respond(c, result, err)
+}
+
+// Delete удаляет выбранные расходы в корзину
+func (h *ExpenseBulkHandler) Delete(c *gin.Context) {
+	var req models.BulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := c.GetUint("user_id")
+	selection, ok := bindExpenseSelection(c, userID, req.BulkExpenseSelection)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.BulkDelete(userID, selection)
+	h.respond(c, result, err)
+}
+
+func (h *ExpenseBulkHandler) respond(c *gin.Context, result *models.BulkExpenseResult, err error) {
+	if err != nil {
+		if errors.Is(err, services.ErrBulkExpenseRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// bindExpenseSelection превращает выбор расходов из тела запроса в выборку сервиса. Фильтр проверяется
+// так же строго, как параметры списка расходов, а пустой фильтр отклоняется, чтобы запрос
+// не затронул все расходы пользователя по ошибке.
+func bindExpenseSelection(c *gin.Context, userID uint, req models.BulkExpenseSelection) (models.ExpenseSelection, bool) {
+	selection := models.ExpenseSelection{IDs: req.IDs}
+	if req.Filter == nil {
+		return selection, true
+	}
+
+	filter, err := bulkExpenseFilter(*req.Filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return selection, false
+	}
+	filter.UserID = userID
+	selection.Filter = &filter
+	return selection, true
+}
+
+func bulkExpenseFilter(req models.BulkExpenseFilter) (models.ExpenseFilter, error) {
+	filter := models.ExpenseFilter{
+		AccountID:            req.AccountID,
+		CategoryID:           req.CategoryID,
+		IncludeSubcategories: req.IncludeSubcategories,
+		MinAmount:            req.MinAmount,
+		MaxAmount:            req.MaxAmount,
+		TagIDs:               req.TagIDs,
+		ExcludeTagIDs:        req.ExcludeTagIDs,
+	}
+
+	if req.StartDate != "" {
+		t, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return filter, errors.New("invalid filter.start_date, expected YYYY-MM-DD")
+		}
+		filter.StartDate = &t
+	}
+	if req.EndDate != "" {
+		t, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return filter, errors.New("invalid filter.end_date, expected YYYY-MM-DD")
+		}
+		filter.EndDate = &t
+	}
+	if v := strings.TrimSpace(req.Search); v != "" {
+		if len([]rune(v)) > maxExpenseSearchLength {
+			v = string([]rune(v)[:maxExpenseSearchLength])
+		}
+		filter.Search = v
+	}
+
+	if filter.AccountID == nil && filter.CategoryID == nil && filter.StartDate == nil && filter.EndDate == nil &&
+		filter.MinAmount == nil && filter.MaxAmount == nil && len(filter.TagIDs) == 0 &&
+		len(filter.ExcludeTagIDs) == 0 && filter.Search == "" {
+		return filter, errors.New("filter должен содержать хотя бы одно условие")
+	}
+
+	return filter, validateExpenseFilter(filter)
+}
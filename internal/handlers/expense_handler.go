@@ -3,10 +3,12 @@ package handlers
 import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/services"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -42,11 +44,25 @@ func (h *ExpenseHandler) List(c *gin.Context) {
 		return
 	}
 
-	filter, _ := h.parseExpenseFilter(c)
+	filter, err := parseExpenseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	filter.UserID = userID
 
-	expenses, err := h.service.GetExpenseList(filter)
+	page, err := parseExpensePage(c, filter)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	expenses, err := h.service.GetExpenseList(filter, page)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("failed to list expenses", slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -71,7 +87,6 @@ func (h *ExpenseHandler) Create(c *gin.Context) {
 	}
 
 	expense, err := h.service.CreateExpense(userID, req)
-	fmt.Println("categoryID",expense.CategoryID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -198,35 +213,110 @@ func (h *ExpenseHandler) Delete(c *gin.Context) {
 
 // -------- FILTER --------
 
-func (h *ExpenseHandler) parseExpenseFilter(c *gin.Context) (models.ExpenseFilter, error) {
+// maxExpenseSearchLength максимальная длина строки поиска q в символах, остаток отбрасывается
+const maxExpenseSearchLength = 200
+
+// parseExpenseFilter читает фильтр списка расходов. Некорректное значение параметра возвращается
+// ошибкой, а не пропускается: иначе запрос молча вернул бы расходы без части фильтров.
+func parseExpenseFilter(c *gin.Context) (models.ExpenseFilter, error) {
 	var filter models.ExpenseFilter
 
+	if v := c.Query("account_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return filter, errors.New("invalid account_id")
+		}
+		accountID := uint(id)
+		filter.AccountID = &accountID
+	}
 	if v := c.Query("category_id"); v != "" {
-		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
-			categoryID := uint(id)
-			filter.CategoryID = &categoryID
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return filter, errors.New("invalid category_id")
 		}
+		categoryID := uint(id)
+		filter.CategoryID = &categoryID
+	}
+	if v := c.Query("include_subcategories"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("invalid include_subcategories")
+		}
+		filter.IncludeSubcategories = include
+	}
+	if v := c.Query("tag_ids"); v != "" {
+		ids, err := parseIDList(v)
+		if err != nil {
+			return filter, errors.New("invalid tag_ids")
+		}
+		filter.TagIDs = ids
+	}
+	if v := c.Query("exclude_tag_ids"); v != "" {
+		ids, err := parseIDList(v)
+		if err != nil {
+			return filter, errors.New("invalid exclude_tag_ids")
+		}
+		filter.ExcludeTagIDs = ids
 	}
 	if v := c.Query("start_date"); v != "" {
-		if t, err := time.Parse("2006-01-02", v); err == nil {
-			filter.StartDate = &t
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return filter, errors.New("invalid start_date, expected YYYY-MM-DD")
 		}
+		filter.StartDate = &t
 	}
 	if v := c.Query("end_date"); v != "" {
-		if t, err := time.Parse("2006-01-02", v); err == nil {
-			filter.EndDate = &t
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return filter, errors.New("invalid end_date, expected YYYY-MM-DD")
+		}
+		filter.EndDate = &t
+	}
+	if v := strings.TrimSpace(c.Query("q")); v != "" {
+		if len([]rune(v)) > maxExpenseSearchLength {
+			v = string([]rune(v)[:maxExpenseSearchLength])
 		}
+		filter.Search = v
 	}
-	if v := c.Query("limit"); v != "" {
-		if l, err := strconv.Atoi(v); err == nil {
-			filter.Limit = &l
+	if v := c.Query("min_amount"); v != "" {
+		amount, err := models.ParseMoney(v)
+		if err != nil {
+			return filter, errors.New("invalid min_amount")
 		}
+		filter.MinAmount = &amount
 	}
-	if v := c.Query("offset"); v != "" {
-		if o, err := strconv.Atoi(v); err == nil {
-			filter.Offset = &o
+	if v := c.Query("max_amount"); v != "" {
+		amount, err := models.ParseMoney(v)
+		if err != nil {
+			return filter, errors.New("invalid max_amount")
 		}
+		filter.MaxAmount = &amount
 	}
 
-	return filter, nil
+	return filter, validateExpenseFilter(filter)
+}
+
+// validateExpenseFilter проверяет согласованность границ периода и суммы фильтра расходов
+func validateExpenseFilter(filter models.ExpenseFilter) error {
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return errors.New("end_date must not be before start_date")
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MaxAmount < *filter.MinAmount {
+		return errors.New("max_amount must not be less than min_amount")
+	}
+	return nil
+}
+
+// parseExpensePage читает параметры страницы списка расходов. При поиске по умолчанию
+// сначала идут самые релевантные расходы, без поиска — самые новые.
+func parseExpensePage(c *gin.Context, filter models.ExpenseFilter) (models.PageRequest, error) {
+	sorts := []string{models.SortByDate, models.SortByAmount, models.SortByCreatedAt}
+	defaultSort := models.SortByDate
+	if filter.Search != "" {
+		sorts = append(sorts, models.SortByRelevance)
+		defaultSort = models.SortByRelevance
+	} else if c.Query("sort") == models.SortByRelevance {
+		return models.PageRequest{}, fmt.Errorf("sort=%s доступна только вместе с q", models.SortByRelevance)
+	}
+	return parsePageRequest(c, sorts, defaultSort, models.SortDesc)
 }
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportContentTypes типы содержимого ответа по форматам выгрузки
+var exportContentTypes = map[models.ExportFormat]string{
+	models.ExportFormatCSV:  "text/csv; charset=utf-8",
+	models.ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	models.ExportFormatJSON: "application/json; charset=utf-8",
+}
+
+type ExportHandler struct {
+	service services.ExportService
+	logger  *slog.Logger
+}
+
+func NewExportHandler(service services.ExportService, logger *slog.Logger) *ExportHandler {
+	return &ExportHandler{service: service, logger: logger}
+}
+
+func (h *ExportHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/expenses/export", h.ExportExpenses)
+}
+
+// ExportExpenses выгружает расходы по тем же фильтрам, что и список расходов.
+// Строки пишутся в ответ по мере чтения из БД.
+func (h *ExportHandler) ExportExpenses(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	format := models.ExportFormat(strings.ToLower(c.DefaultQuery("format", string(models.ExportFormatCSV))))
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format должен быть csv, xlsx или json"})
+		return
+	}
+
+	filter, err := parseExpenseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.UserID = userID
+
+	var columns []string
+	if v := c.Query("columns"); v != "" {
+		columns = strings.Split(v, ",")
+	}
+
+	filename := fmt.Sprintf("expenses-%s.%s", time.Now().Format("2006-01-02"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if err := h.service.ExportExpenses(c.Writer, filter, format, columns); err != nil {
+		// После начала записи статус ответа уже отправлен, остается только прервать выгрузку
+		if c.Writer.Written() {
+			h.logger.Error("expense export interrupted",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("error", err.Error()),
+			)
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		if errors.Is(err, services.ErrInvalidExport) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImportHandler struct {
+	service services.ImportService
+	logger  *slog.Logger
+}
+
+func NewImportHandler(service services.ImportService, logger *slog.Logger) *ImportHandler {
+	return &ImportHandler{service: service, logger: logger}
+}
+
+func (h *ImportHandler) RegisterRoutes(r *gin.RouterGroup) {
+	imports := r.Group("/expenses/import")
+	{
+		imports.POST("/csv", h.ImportCSV)
+		imports.POST("/ofx", h.importStatement(models.StatementFormatOFX))
+		imports.POST("/qfx", h.importStatement(models.StatementFormatOFX))
+		imports.POST("/qif", h.importStatement(models.StatementFormatQIF))
+	}
+}
+
+// ImportCSV принимает CSV-файл в поле формы "file" и параметры сопоставления столбцов
+func (h *ImportHandler) ImportCSV(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.CSVImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "файл не передан"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportExpensesCSV(userID, file, req)
+	if err != nil {
+		h.logger.Warn("expense csv import failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("filename", fileHeader.Filename),
+			slog.String("error", err.Error()),
+		)
+		if errors.Is(err, services.ErrImportInvalid) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "result": result})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// importStatement принимает банковскую выписку в поле формы "file"
+func (h *ImportHandler) importStatement(format models.StatementFormat) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetUint("user_id")
+
+		var req models.StatementImportRequest
+		if err := c.ShouldBind(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "файл не передан"})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+
+		result, err := h.service.ImportStatement(userID, file, format, req)
+		if err != nil {
+			h.logger.Warn("statement import failed",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("format", string(format)),
+				slog.String("filename", fileHeader.Filename),
+				slog.String("error", err.Error()),
+			)
+			if errors.Is(err, services.ErrImportInvalid) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "result": result})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
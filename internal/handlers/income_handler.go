@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IncomeHandler struct {
+	service services.IncomeService
+	logger  *slog.Logger
+}
+
+func NewIncomeHandler(service services.IncomeService, logger *slog.Logger) *IncomeHandler {
+	return &IncomeHandler{service: service, logger: logger}
+}
+
+func (h *IncomeHandler) RegisterRoutes(r *gin.RouterGroup) {
+	incomes := r.Group("/incomes")
+	{
+		incomes.GET("", h.List)
+		incomes.POST("", h.Create)
+		incomes.GET("/:id", h.Get)
+		incomes.PATCH("/:id", h.Update)
+		incomes.DELETE("/:id", h.Delete)
+	}
+}
+
+// -------- LIST --------
+
+func (h *IncomeHandler) List(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	filter, _ := h.parseIncomeFilter(c)
+	filter.UserID = userID
+
+	incomes, err := h.service.GetIncomeList(filter)
+	if err != nil {
+		h.logger.Error("failed to list incomes", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, incomes)
+}
+
+// -------- CREATE --------
+
+func (h *IncomeHandler) Create(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req models.CreateIncomeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	income, err := h.service.CreateIncome(userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, income)
+}
+
+// -------- GET --------
+
+func (h *IncomeHandler) Get(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	income, err := h.service.GetIncomeByID(uint(id))
+	if err != nil {
+		if err == services.ErrIncomeNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if income.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	c.JSON(http.StatusOK, income)
+}
+
+// -------- UPDATE --------
+
+func (h *IncomeHandler) Update(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	income, err := h.service.GetIncomeByID(uint(id))
+	if err != nil {
+		if err == services.ErrIncomeNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if income.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	var req models.UpdateIncomeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.UpdateIncome(uint(id), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// -------- DELETE --------
+
+func (h *IncomeHandler) Delete(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	income, err := h.service.GetIncomeByID(uint(id))
+	if err != nil {
+		if err == services.ErrIncomeNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if income.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	if err := h.service.DeleteIncome(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// -------- FILTER --------
+
+func (h *IncomeHandler) parseIncomeFilter(c *gin.Context) (models.IncomeFilter, error) {
+	var filter models.IncomeFilter
+
+	if v := c.Query("account_id"); v != "" {
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+			accountID := uint(id)
+			filter.AccountID = &accountID
+		}
+	}
+	if v := c.Query("category_id"); v != "" {
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+			categoryID := uint(id)
+			filter.CategoryID = &categoryID
+		}
+	}
+	if v := c.Query("include_subcategories"); v != "" {
+		if include, err := strconv.ParseBool(v); err == nil {
+			filter.IncludeSubcategories = include
+		}
+	}
+	if v := c.Query("start_date"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			filter.StartDate = &t
+		}
+	}
+	if v := c.Query("end_date"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			filter.EndDate = &t
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if l, err := strconv.Atoi(v); err == nil {
+			filter.Limit = &l
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if o, err := strconv.Atoi(v); err == nil {
+			filter.Offset = &o
+		}
+	}
+
+	return filter, nil
+}
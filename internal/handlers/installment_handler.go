@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultUpcomingDays период ближайших платежей по умолчанию, в днях
+const defaultUpcomingDays = 30
+
+type InstallmentHandler struct {
+	service services.InstallmentService
+	logger  *slog.Logger
+}
+
+func NewInstallmentHandler(service services.InstallmentService, logger *slog.Logger) *InstallmentHandler {
+	return &InstallmentHandler{service: service, logger: logger}
+}
+
+func (h *InstallmentHandler) RegisterRoutes(r *gin.RouterGroup) {
+	installments := r.Group("/installments")
+	{
+		installments.GET("", h.List)
+		installments.POST("", h.Create)
+		installments.GET("/upcoming", h.Upcoming)
+		installments.GET("/:id", h.Get)
+		installments.GET("/:id/schedule", h.Schedule)
+		installments.PATCH("/:id", h.Update)
+		installments.DELETE("/:id", h.Delete)
+	}
+}
+
+func (h *InstallmentHandler) List(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	plans, err := h.service.GetInstallmentPlanList(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+func (h *InstallmentHandler) Create(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.CreateInstallmentPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.service.CreateInstallmentPlan(userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// Upcoming возвращает ближайшие платежи по всем планам пользователя за days дней (по умолчанию 30)
+func (h *InstallmentHandler) Upcoming(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	days := defaultUpcomingDays
+	if v := c.Query("days"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > 366 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days должен быть числом от 1 до 366"})
+			return
+		}
+		days = parsed
+	}
+
+	payments, err := h.service.GetUpcomingPayments(userID, time.Now().AddDate(0, 0, days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+func (h *InstallmentHandler) Get(c *gin.Context) {
+	plan, ok := h.loadPlan(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// Schedule возвращает все платежи плана, созданные расходами отмечены paid
+func (h *InstallmentHandler) Schedule(c *gin.Context) {
+	plan, ok := h.loadPlan(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, h.service.GetInstallmentSchedule(plan))
+}
+
+func (h *InstallmentHandler) Update(c *gin.Context) {
+	plan, ok := h.loadPlan(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateInstallmentPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.UpdateInstallmentPlan(plan.ID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *InstallmentHandler) Delete(c *gin.Context) {
+	plan, ok := h.loadPlan(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteInstallmentPlan(plan.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadPlan читает план рассрочки из параметра :id и проверяет, что он принадлежит текущему пользователю
+func (h *InstallmentHandler) loadPlan(c *gin.Context) (*models.InstallmentPlan, bool) {
+	userID := c.GetUint("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+
+	plan, err := h.service.GetInstallmentPlanByID(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrInstallmentPlanNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if plan.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	return plan, true
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePageRequest читает параметры страницы limit, sort, direction и cursor. sorts — допустимые
+// поля сортировки списка. Смещение offset не поддерживается: при добавлении записей страницы
+// по смещению сдвигаются, поэтому запрос с ним отклоняется, а не выполняется молча без него.
+func parsePageRequest(c *gin.Context, sorts []string, defaultSort string, defaultDirection models.SortDirection) (models.PageRequest, error) {
+	page := models.PageRequest{
+		Limit:     models.DefaultPageLimit,
+		Sort:      defaultSort,
+		Direction: defaultDirection,
+	}
+
+	if _, ok := c.GetQuery("offset"); ok {
+		return page, errors.New("offset не поддерживается, используйте cursor из next_cursor")
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > models.MaxPageLimit {
+			return page, fmt.Errorf("limit должен быть от 1 до %d", models.MaxPageLimit)
+		}
+		page.Limit = limit
+	}
+
+	if v := c.Query("sort"); v != "" {
+		if !containsString(sorts, v) {
+			return page, fmt.Errorf("sort должен быть одним из: %s", strings.Join(sorts, ", "))
+		}
+		page.Sort = v
+	}
+
+	if v := c.Query("direction"); v != "" {
+		switch direction := models.SortDirection(strings.ToLower(v)); direction {
+		case models.SortAsc, models.SortDesc:
+			page.Direction = direction
+		default:
+			return page, errors.New("direction должен быть asc или desc")
+		}
+	}
+
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := models.DecodeCursor(v)
+		if err != nil {
+			return page, err
+		}
+		// Курсор хранит значение поля сортировки, поэтому он действителен только для той же сортировки
+		if cursor.Sort != page.Sort || cursor.Direction != page.Direction {
+			return page, fmt.Errorf("%w: курсор получен для другой сортировки", models.ErrInvalidCursor)
+		}
+		page.Cursor = cursor
+	}
+
+	return page, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/services"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -57,8 +58,18 @@ func (h *RecurringExpenseHandler) List(c *gin.Context) {
 	}
 	userID := uint(userIDUint)
 
-	recurringExpenses, err := h.service.GetRecurringExpenseList(userID)
+	page, err := parsePageRequest(c, []string{models.SortByDate, models.SortByAmount, models.SortByCreatedAt}, models.SortByDate, models.SortAsc)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recurringExpenses, err := h.service.GetRecurringExpenseList(userID, page)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("failed to get recurring expense list",
 			slog.Uint64("user_id", uint64(userID)),
 			slog.String("error", err.Error()),
@@ -69,7 +80,7 @@ func (h *RecurringExpenseHandler) List(c *gin.Context) {
 
 	h.logger.Info("recurring expense list retrieved",
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Int("count", len(recurringExpenses)),
+		slog.Int("count", len(recurringExpenses.Items)),
 	)
 
 	c.JSON(http.StatusOK, recurringExpenses)
@@ -5,6 +5,7 @@ import (
 	"cashcontrol/internal/middleware"
 	"cashcontrol/internal/repository"
 	"cashcontrol/internal/services"
+	"cashcontrol/internal/storage"
 	"log/slog"
 	"time"
 
@@ -17,22 +18,54 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, logger *slog.Logger, cfg *config
 	userRepo := repository.NewUserRepository(db, logger)
 	categoryRepo := repository.NewCategoryRepository(db, logger)
 	expenseRepo := repository.NewExpenseRepository(db, logger)
+	incomeRepo := repository.NewIncomeRepository(db, logger)
+	accountRepo := repository.NewAccountRepository(db, logger)
+	transferRepo := repository.NewTransferRepository(db, logger)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db, logger)
+	tagRepo := repository.NewTagRepository(db, logger)
+	categoryRuleRepo := repository.NewCategoryRuleRepository(db, logger)
 	budgetRepo := repository.NewBudgetRepository(db, logger)
 	recurringExpenseRepo := repository.NewRecurringExpenseRepository(db, logger)
-	_ = repository.NewActivityLogRepository(db, logger)
+	statsRepo := repository.NewStatisticsRepository(db)
+	userDataRepo := repository.NewUserDataRepository(db, logger)
+	activityLogRepo := repository.NewActivityLogRepository(db, logger)
+	attachmentRepo := repository.NewAttachmentRepository(db, logger)
+	trashRepo := repository.NewTrashRepository(db, logger)
+	installmentPlanRepo := repository.NewInstallmentPlanRepository(db, logger)
 	_ = repository.NewRecurringExpenseRepository(db, logger)
 
+	// ---------- storage ----------
+	attachmentStorage, err := storage.New(cfg)
+	if err != nil {
+		logger.Error("attachment storage init failed", slog.String("error", err.Error()))
+		panic(err)
+	}
+
 	// ---------- services ----------
 	userService := services.NewUserService(userRepo, logger)
 	categoryService := services.NewCategoryService(categoryRepo, logger)
-	expenseService := services.NewExpenseService(expenseRepo, categoryRepo, logger)
+	currencyService := services.NewCurrencyService(exchangeRateRepo, userRepo, expenseRepo, logger)
+	attachmentService := services.NewAttachmentService(attachmentRepo, attachmentStorage, cfg.AttachmentMaxSize, logger)
+	expenseService := services.NewExpenseService(expenseRepo, categoryRepo, accountRepo, tagRepo, categoryRuleRepo, currencyService, logger)
+	incomeService := services.NewIncomeService(incomeRepo, categoryRepo, accountRepo, logger)
+	accountService := services.NewAccountService(accountRepo, transferRepo, logger)
+	tagService := services.NewTagService(tagRepo, logger)
+	categoryRuleService := services.NewCategoryRuleService(categoryRuleRepo, categoryRepo, expenseRepo, logger)
+	activityLogService := services.NewActivityLogService(activityLogRepo, logger)
+	duplicateService := services.NewDuplicateService(expenseRepo, attachmentRepo, activityLogService, logger)
+	categorySuggestionService := services.NewCategorySuggestionService(expenseRepo, categoryRepo, categoryRuleRepo, logger)
+	importService := services.NewImportService(expenseRepo, categoryRepo, accountRepo, categoryRuleRepo, currencyService, logger)
+	exportService := services.NewExportService(expenseRepo, logger)
+	userDataService := services.NewUserDataService(userDataRepo, userRepo, budgetRepo, attachmentService, logger)
 	notificationService, err := services.NewNotificationService(cfg.TelegramBotToken, userRepo, logger)
 	if err != nil {
 		logger.Warn("notification service init failed", slog.String("error", err.Error()))
 	}
 
-	budgetService := services.NewBudgetService(budgetRepo, expenseRepo, notificationService, logger)
-	recurringExpenseService := services.NewRecurringExpenseService(recurringExpenseRepo, expenseRepo, notificationService, logger)
+	budgetService := services.NewBudgetService(budgetRepo, expenseRepo, categoryRepo, statsRepo, notificationService, logger)
+	recurringExpenseService := services.NewRecurringExpenseService(recurringExpenseRepo, expenseRepo, tagRepo, currencyService, notificationService, logger)
+	trashService := services.NewTrashService(trashRepo, categoryRepo, accountRepo, budgetRepo, recurringExpenseService, attachmentService, cfg.TrashRetentionDays, logger)
+	installmentService := services.NewInstallmentService(installmentPlanRepo, expenseRepo, categoryRepo, accountRepo, currencyService, logger)
 
 	// ---------- API root ----------
 	api := r.Group("/api")
@@ -56,24 +89,68 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, logger *slog.Logger, cfg *config
 	userHandler := NewUserHandler(userService, logger)
 	userHandler.RegisterRoutes(protected)
 
+	userDataHandler := NewUserDataHandler(userDataService, logger)
+	userDataHandler.RegisterRoutes(protected)
+
 	categoryHandler := NewCategoryHandler(categoryService, logger)
 	categoryHandler.RegisterRoutes(protected)
 
 	expenseHandler := NewExpenseHandler(expenseService, logger)
 	expenseHandler.RegisterRoutes(protected)
 
+	attachmentHandler := NewAttachmentHandler(attachmentService, expenseService, cfg.AttachmentMaxSize, logger)
+	attachmentHandler.RegisterRoutes(protected)
+
+	importHandler := NewImportHandler(importService, logger)
+	importHandler.RegisterRoutes(protected)
+
+	exportHandler := NewExportHandler(exportService, logger)
+	exportHandler.RegisterRoutes(protected)
+
+	incomeHandler := NewIncomeHandler(incomeService, logger)
+	incomeHandler.RegisterRoutes(protected)
+
+	accountHandler := NewAccountHandler(accountService, logger)
+	accountHandler.RegisterRoutes(protected)
+
+	currencyHandler := NewCurrencyHandler(currencyService, logger)
+	currencyHandler.RegisterRoutes(protected)
+
+	tagHandler := NewTagHandler(tagService, logger)
+	tagHandler.RegisterRoutes(protected)
+
+	categoryRuleHandler := NewCategoryRuleHandler(categoryRuleService, logger)
+	categoryRuleHandler.RegisterRoutes(protected)
+
+	categorySuggestionHandler := NewCategorySuggestionHandler(categorySuggestionService, logger)
+	categorySuggestionHandler.RegisterRoutes(protected)
+
+	duplicateHandler := NewDuplicateHandler(duplicateService, logger)
+	duplicateHandler.RegisterRoutes(protected)
+
+	expenseBulkHandler := NewExpenseBulkHandler(expenseService, logger)
+	expenseBulkHandler.RegisterRoutes(protected)
+
 	budgetHandler := NewBudgetHandler(budgetService, logger)
 	budgetHandler.RegisterRoutes(protected)
 
 	recurringExpenseHandler := NewRecurringExpenseHandler(recurringExpenseService, logger)
 	recurringExpenseHandler.RegisterRoutes(protected)
 
+	activityLogHandler := NewActivityLogHandler(activityLogService, logger)
+	activityLogHandler.RegisterRoutes(protected)
+
+	trashHandler := NewTrashHandler(trashService, logger)
+	trashHandler.RegisterRoutes(protected)
+
+	installmentHandler := NewInstallmentHandler(installmentService, logger)
+	installmentHandler.RegisterRoutes(protected)
+
 	analyticsRepo := repository.NewAnalyticsRepository(db)
 	analyticsService := services.NewAnalyticsService(analyticsRepo, logger)
 	analyticsHandler := NewAnalyticsHandler(analyticsService, logger)
 	analyticsHandler.RegisterRoutes(protected)
 
-	statsRepo := repository.NewStatisticsRepository(db)
 	statsService := services.NewStatisticsService(statsRepo, logger)
 	statsHandler := NewStatisticsHandler(statsService, logger)
 	statsHandler.RegisterRoutes(protected) 
@@ -84,6 +161,14 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB, logger *slog.Logger, cfg *config
 		go startRecurringProcessor(recurringExpenseService, logger)
 	}
 
+	// Платежи по планам рассрочки создаются расходами в день списания
+	go startInstallmentProcessor(installmentService, logger)
+
+	// Безвозвратное удаление записей, которые лежат в корзине дольше срока хранения
+	if cfg.TrashRetentionDays > 0 {
+		go startTrashPurger(trashService, logger)
+	}
+
 }
 
 func startDailyExpenseReminder(notification services.NotificationService, users repository.UserRepository, logger *slog.Logger) {
@@ -131,3 +216,25 @@ func startRecurringProcessor(recurring services.RecurringExpenseService, logger
 		<-ticker.C
 	}
 }
+
+func startTrashPurger(trash services.TrashService, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if _, err := trash.PurgeExpired(time.Now()); err != nil {
+			logger.Warn("purge expired trash failed", slog.String("error", err.Error()))
+		}
+		<-ticker.C
+	}
+}
+
+func startInstallmentProcessor(installments services.InstallmentService, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if err := installments.ProcessDuePayments(time.Now()); err != nil {
+			logger.Warn("process installment payments failed", slog.String("error", err.Error()))
+		}
+		<-ticker.C
+	}
+}
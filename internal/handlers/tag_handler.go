@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	service services.TagService
+	logger  *slog.Logger
+}
+
+func NewTagHandler(service services.TagService, logger *slog.Logger) *TagHandler {
+	return &TagHandler{service: service, logger: logger}
+}
+
+func (h *TagHandler) RegisterRoutes(r *gin.RouterGroup) {
+	tags := r.Group("/tags")
+	{
+		tags.GET("", h.List)
+		tags.POST("", h.Create)
+		tags.GET("/:id", h.Get)
+		tags.PATCH("/:id", h.Update)
+		tags.DELETE("/:id", h.Delete)
+	}
+}
+
+func (h *TagHandler) List(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	tags, err := h.service.GetTagList(userID)
+	if err != nil {
+		h.logger.Error("failed to get tag list",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *TagHandler) Create(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.service.CreateTag(userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+func (h *TagHandler) Get(c *gin.Context) {
+	tag, ok := h.loadTag(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) Update(c *gin.Context) {
+	tag, ok := h.loadTag(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.UpdateTag(tag.ID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *TagHandler) Delete(c *gin.Context) {
+	tag, ok := h.loadTag(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTag(tag.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tag deleted"})
+}
+
+// loadTag читает тег из параметра :id и проверяет, что он принадлежит текущему пользователю
+func (h *TagHandler) loadTag(c *gin.Context) (*models.Tag, bool) {
+	userID := c.GetUint("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+
+	tag, err := h.service.GetTagByID(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrTagNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if tag.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	return tag, true
+}
+
+// parseIDList разбирает список идентификаторов через запятую, например "1,2,3"
+func parseIDList(raw string) ([]uint, error) {
+	var ids []uint
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
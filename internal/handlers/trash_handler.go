@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	service services.TrashService
+	logger  *slog.Logger
+}
+
+func NewTrashHandler(service services.TrashService, logger *slog.Logger) *TrashHandler {
+	return &TrashHandler{service: service, logger: logger}
+}
+
+func (h *TrashHandler) RegisterRoutes(r *gin.RouterGroup) {
+	trash := r.Group("/trash")
+	{
+		trash.GET("", h.List)
+		trash.DELETE("", h.Empty)
+		trash.POST("/:type/:id/restore", h.Restore)
+		trash.DELETE("/:type/:id", h.Purge)
+	}
+}
+
+// List возвращает удаленные записи текущего пользователя, параметр type оставляет записи одного вида
+func (h *TrashHandler) List(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var itemType models.TrashItemType
+	if v := c.Query("type"); v != "" {
+		parsed, ok := parseTrashItemType(v)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": trashTypeError()})
+			return
+		}
+		itemType = parsed
+	}
+
+	items, err := h.service.GetTrash(userID, itemType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *TrashHandler) Restore(c *gin.Context) {
+	item, ok := h.loadItem(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RestoreItem(item); err != nil {
+		switch {
+		case errors.Is(err, services.ErrTrashItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTrashRestore):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "restored", "type": item.Type, "id": item.ID})
+}
+
+func (h *TrashHandler) Purge(c *gin.Context) {
+	item, ok := h.loadItem(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.PurgeItem(item); err != nil {
+		switch {
+		case errors.Is(err, services.ErrTrashItemNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTrashItemInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Empty безвозвратно удаляет все записи корзины текущего пользователя
+func (h *TrashHandler) Empty(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	result, err := h.service.EmptyTrash(userID)
+	if err != nil {
+		h.logger.Error("empty trash failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// loadItem читает запись корзины из параметров :type и :id и проверяет, что она принадлежит текущему пользователю
+func (h *TrashHandler) loadItem(c *gin.Context) (*models.TrashItem, bool) {
+	userID := c.GetUint("user_id")
+
+	itemType, ok := parseTrashItemType(c.Param("type"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": trashTypeError()})
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+
+	item, err := h.service.GetTrashItem(itemType, uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrTrashItemNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if item.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	return item, true
+}
+
+func parseTrashItemType(value string) (models.TrashItemType, bool) {
+	for _, itemType := range models.TrashItemTypes {
+		if string(itemType) == value {
+			return itemType, true
+		}
+	}
+	return "", false
+}
+
+func trashTypeError() string {
+	types := make([]string, len(models.TrashItemTypes))
+	for i, itemType := range models.TrashItemTypes {
+		types[i] = string(itemType)
+	}
+	return "type должен быть одним из: " + strings.Join(types, ", ")
+}
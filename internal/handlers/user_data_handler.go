@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserDataHandler выгрузка, восстановление и удаление всех данных текущего пользователя
+type UserDataHandler struct {
+	service services.UserDataService
+	logger  *slog.Logger
+}
+
+func NewUserDataHandler(service services.UserDataService, logger *slog.Logger) *UserDataHandler {
+	return &UserDataHandler{service: service, logger: logger}
+}
+
+func (h *UserDataHandler) RegisterRoutes(r *gin.RouterGroup) {
+	me := r.Group("/users/me")
+	{
+		me.GET("/export", h.Export)
+		me.POST("/import", h.Import)
+		me.DELETE("", h.Delete)
+	}
+}
+
+// Export отдает архив с данными пользователя: format=zip (по умолчанию) или json
+func (h *UserDataHandler) Export(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	format := models.ExportFormat(strings.ToLower(c.DefaultQuery("format", string(models.ExportFormatZIP))))
+	contentType := "application/zip"
+	switch format {
+	case models.ExportFormatZIP:
+	case models.ExportFormatJSON:
+		contentType = "application/json; charset=utf-8"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format должен быть zip или json"})
+		return
+	}
+
+	filename := fmt.Sprintf("cashcontrol-%s.%s", time.Now().Format("2006-01-02"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if err := h.service.ExportUserData(c.Writer, userID, format); err != nil {
+		h.logger.Error("user data export failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		if c.Writer.Written() {
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Import восстанавливает данные из архива выгрузки, переданного в поле формы "file"
+func (h *UserDataHandler) Import(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "файл не передан"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportUserData(userID, file)
+	if err != nil {
+		h.logger.Warn("user data import failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("filename", fileHeader.Filename),
+			slog.String("error", err.Error()),
+		)
+		switch {
+		case errors.Is(err, services.ErrArchiveInvalid):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Delete безвозвратно удаляет учетную запись и все данные пользователя.
+// Требует тело {"confirm":"DELETE"}.
+func (h *UserDataHandler) Delete(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.DeleteUserData(userID, req); err != nil {
+		if errors.Is(err, services.ErrDeleteNotConfirmed) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("user account deleted",
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	c.Status(http.StatusNoContent)
+}
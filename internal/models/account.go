@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AccountType string
+
+const (
+	AccountTypeCash       AccountType = "cash"        // Наличные
+	AccountTypeDebitCard  AccountType = "debit_card"  // Дебетовая карта
+	AccountTypeCreditCard AccountType = "credit_card" // Кредитная карта
+	AccountTypeSavings    AccountType = "savings"     // Сберегательный счет
+)
+
+type Account struct {
+	gorm.Model
+
+	UserID         uint        `gorm:"not null;index" json:"user_id"`                                // Идентификатор пользователя
+	Name           string      `gorm:"not null" json:"name"`                                         // Название счета
+	Type           AccountType `gorm:"not null;default:cash" json:"type"`                            // Тип счета
+	OpeningBalance Money       `gorm:"not null;default:0;type:numeric(14,2)" json:"opening_balance"` // Начальный остаток
+	Balance        Money       `gorm:"-" json:"balance"`                                             // Текущий остаток вычисляется по операциям
+	// Связи
+	User User `gorm:"foreignKey:UserID" json:"-"` // Пользователь владелец счета
+}
+
+// Transfer перевод между счетами пользователя, не является расходом или доходом
+type Transfer struct {
+	gorm.Model
+
+	UserID        uint      `gorm:"not null;index" json:"user_id"`             // Идентификатор пользователя
+	FromAccountID uint      `gorm:"not null;index" json:"from_account_id"`     // Счет списания
+	ToAccountID   uint      `gorm:"not null;index" json:"to_account_id"`       // Счет зачисления
+	Amount        Money     `gorm:"not null;type:numeric(14,2)" json:"amount"` // Сумма перевода
+	Description   string    `json:"description"`                               // Описание перевода
+	Date          time.Time `gorm:"not null;index" json:"date"`                // Дата перевода
+	// Связи
+	User        User    `gorm:"foreignKey:UserID" json:"-"`                   // Пользователь владелец перевода
+	FromAccount Account `gorm:"foreignKey:FromAccountID" json:"from_account"` // Счет списания
+	ToAccount   Account `gorm:"foreignKey:ToAccountID" json:"to_account"`     // Счет зачисления
+}
+
+type CreateAccountRequest struct {
+	Name           string      `json:"name" binding:"required"`                                            // Название счета
+	Type           AccountType `json:"type" binding:"omitempty,oneof=cash debit_card credit_card savings"` // Тип счета (по умолчанию cash)
+	OpeningBalance Money       `json:"opening_balance"`                                                    // Начальный остаток
+}
+
+type UpdateAccountRequest struct {
+	Name           *string      `json:"name,omitempty"`                                                               // Новое название счета
+	Type           *AccountType `json:"type,omitempty" binding:"omitempty,oneof=cash debit_card credit_card savings"` // Новый тип счета
+	OpeningBalance *Money       `json:"opening_balance,omitempty"`                                                    // Новый начальный остаток
+}
+
+type CreateTransferRequest struct {
+	FromAccountID uint      `json:"from_account_id" binding:"required"` // Счет списания
+	ToAccountID   uint      `json:"to_account_id" binding:"required"`   // Счет зачисления
+	Amount        Money     `json:"amount" binding:"required,gt=0"`     // Сумма перевода должна быть больше нуля
+	Description   string    `json:"description"`                        // Описание перевода
+	Date          time.Time `json:"date" binding:"required"`            // Дата перевода
+}
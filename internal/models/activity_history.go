@@ -12,6 +12,7 @@ const (
 	ActivityTypeExpenseCreated   ActivityType = "expense_created"
 	ActivityTypeExpenseUpdated   ActivityType = "expense_updated"
 	ActivityTypeExpenseDeleted   ActivityType = "expense_deleted"
+	ActivityTypeExpenseMerged    ActivityType = "expense_merged"
 	ActivityTypeCategoryCreated  ActivityType = "category_created"
 	ActivityTypeCategoryUpdated  ActivityType = "category_updated"
 	ActivityTypeCategoryDeleted  ActivityType = "category_deleted"
@@ -50,6 +51,4 @@ type ActivityFilter struct {
 	EntityType   *string       // Тип сущности для фильтрации
 	StartDate    *time.Time    // Начальная дата периода для фильтрации
 	EndDate      *time.Time    // Конечная дата периода для фильтрации
-	Limit        *int          // количество записей
-	Offset       *int          // смещение
 }
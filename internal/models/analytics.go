@@ -3,9 +3,12 @@ package models
 import "time"
 
 type AnalyticsPoint struct {
-	Date   time.Time `json:"date"`
-	Total  float64   `json:"total"`
-	Count  int       `json:"count"`
+	Date    time.Time `json:"date"`    // Начало интервала
+	Total   Money     `json:"total"`   // Сумма расходов в интервале
+	Count   int       `json:"count"`   // Количество расходов в интервале
+	Expense Money     `json:"expense"` // Сумма расходов в интервале
+	Income  Money     `json:"income"`  // Сумма доходов в интервале
+	Net     Money     `json:"net"`     // Доходы минус расходы
 }
 
 type AnalyticsPeriod string
@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/gorm"
+
+// Attachment файл, прикрепленный к расходу: фото чека, PDF счета или гарантийного талона.
+// Содержимое лежит в хранилище вложений, в базе только описание файла.
+type Attachment struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index" json:"user_id"`          // Идентификатор пользователя владельца
+	ExpenseID   uint   `gorm:"not null;index" json:"expense_id"`       // Идентификатор расхода
+	FileName    string `gorm:"not null;size:255" json:"file_name"`     // Имя файла при загрузке
+	ContentType string `gorm:"not null;size:100" json:"content_type"`  // MIME-тип, определенный по содержимому
+	Size        int64  `gorm:"not null" json:"size"`                   // Размер в байтах
+	Checksum    string `gorm:"not null;size:64" json:"checksum"`       // SHA-256 содержимого
+	StorageKey  string `gorm:"not null;size:255;uniqueIndex" json:"-"` // Ключ объекта в хранилище
+}
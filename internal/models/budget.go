@@ -1,44 +1,107 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+type BudgetPeriodType string
+
+const (
+	BudgetPeriodMonthly   BudgetPeriodType = "monthly"   // Календарный месяц
+	BudgetPeriodWeekly    BudgetPeriodType = "weekly"    // Неделя от даты привязки
+	BudgetPeriodBiweekly  BudgetPeriodType = "biweekly"  // Две недели от даты привязки
+	BudgetPeriodQuarterly BudgetPeriodType = "quarterly" // Три месяца от даты привязки
+	BudgetPeriodPayCycle  BudgetPeriodType = "pay_cycle" // Месяц, начинающийся в день даты привязки (например, 25-го)
+)
+
 type Budget struct {
 	gorm.Model
-	UserID uint    `gorm:"not null;index" json:"user_id"`             // Идентификатор пользователя
-	Amount float64 `gorm:"not null;type:decimal(10,2)" json:"amount"` // Сумма месячного бюджета
-	Month  int     `gorm:"not null" json:"month"`                     // Номер месяца от 1 до 12 (валидация в сервисе)
-	Year   int     `gorm:"not null" json:"year"`                      // Год бюджета
+	UserID   uint  `gorm:"not null;index" json:"user_id"`             // Идентификатор пользователя
+	Amount   Money `gorm:"not null;type:numeric(14,2)" json:"amount"` // Сумма бюджета за период
+	Month    int   `gorm:"not null" json:"month"`                     // Номер месяца от 1 до 12 (для немесячных бюджетов месяц даты привязки)
+	Year     int   `gorm:"not null" json:"year"`                      // Год бюджета
+	Rollover bool  `gorm:"not null;default:false" json:"rollover"`    // Перенос остатка или перерасхода предыдущего периода в лимит
+
+	PeriodType BudgetPeriodType `gorm:"not null;default:monthly;index" json:"period_type"` // Тип периода бюджета
+	AnchorDate *time.Time       `json:"anchor_date,omitempty"`                             // Начало первого периода для немесячных бюджетов
+	EndDate    *time.Time       `json:"end_date,omitempty"`                                // Последний день действия немесячного бюджета, пусто — без окончания
 
 	// Связи
-	User User `gorm:"foreignKey:UserID" json:"-"` // Пользователь владелец бюджета
+	User       User             `gorm:"foreignKey:UserID" json:"-"`                      // Пользователь владелец бюджета
+	Categories []BudgetCategory `gorm:"foreignKey:BudgetID" json:"categories,omitempty"` // Лимиты по категориям внутри бюджета
+}
+
+type BudgetCategory struct {
+	gorm.Model
+	BudgetID   uint  `gorm:"not null;index" json:"budget_id"`           // Идентификатор бюджета
+	CategoryID uint  `gorm:"not null;index" json:"category_id"`         // Идентификатор категории
+	Amount     Money `gorm:"not null;type:numeric(14,2)" json:"amount"` // Лимит расходов по категории
+
+	// Связи
+	Category Category `gorm:"foreignKey:CategoryID" json:"category"` // Категория лимита
 }
 
 type BudgetSummary struct {
-	Limit     float64 `json:"limit"`
-	Spent     float64 `json:"spent"`
-	Remaining float64 `json:"remaining"`
-	Percent   int     `json:"percent"`
+	Limit     Money `json:"limit"`
+	Spent     Money `json:"spent"`
+	Remaining Money `json:"remaining"`
+	Percent   int   `json:"percent"`
 }
 
 type CreateBudgetRequest struct {
-	Amount float64 `json:"amount" binding:"required,gt=0"`        // Сумма бюджета должна быть больше нуля
-	Month  int     `json:"month" binding:"required,min=1,max=12"` // Номер месяца от 1 до 12
-	Year   int     `json:"year" binding:"required"`               // Год бюджета
+	Amount     Money                      `json:"amount" binding:"required,gt=0"`         // Сумма бюджета должна быть больше нуля
+	Month      int                        `json:"month" binding:"omitempty,min=1,max=12"` // Номер месяца от 1 до 12 (для месячного бюджета)
+	Year       int                        `json:"year"`                                   // Год бюджета (для месячного бюджета)
+	PeriodType BudgetPeriodType           `json:"period_type"`                            // Тип периода (по умолчанию monthly)
+	AnchorDate *time.Time                 `json:"anchor_date"`                            // Дата начала первого периода для немесячных бюджетов
+	EndDate    *time.Time                 `json:"end_date"`                               // Последний день действия немесячного бюджета (опционально)
+	Rollover   bool                       `json:"rollover"`                               // Переносить остаток предыдущего периода
+	Categories []SetBudgetCategoryRequest `json:"categories,omitempty"`                   // Лимиты по категориям (опционально)
+}
+
+type SetBudgetCategoryRequest struct {
+	CategoryID uint  `json:"category_id" binding:"required"` // Идентификатор категории
+	Amount     Money `json:"amount" binding:"required,gt=0"` // Лимит по категории должен быть больше нуля
 }
 
 type UpdateBudgetRequest struct {
-	Amount *float64 `json:"amount,omitempty"` // Новая сумма бюджета
-	Month  *int     `json:"month,omitempty"`  // Новый номер месяца
-	Year   *int     `json:"year,omitempty"`   // Новый год бюджета
+	Amount       *Money            `json:"amount,omitempty"`      // Новая сумма бюджета
+	Month        *int              `json:"month,omitempty"`       // Новый номер месяца
+	Year         *int              `json:"year,omitempty"`        // Новый год бюджета
+	Rollover     *bool             `json:"rollover,omitempty"`    // Включение или отключение переноса остатка
+	PeriodType   *BudgetPeriodType `json:"period_type,omitempty"` // Новый тип периода
+	AnchorDate   *time.Time        `json:"anchor_date,omitempty"` // Новая дата привязки периода
+	EndDate      *time.Time        `json:"end_date,omitempty"`    // Новый последний день действия немесячного бюджета
+	ClearEndDate bool              `json:"clear_end_date"`        // Снять дату окончания, бюджет действует бессрочно
 }
 
 type BudgetStatus struct {
-	Budget      *Budget `json:"budget"`        // Информация о бюджете
-	Spent       float64 `json:"spent"`         // Потраченная сумма за период
-	Remaining   float64 `json:"remaining"`     // Оставшаяся сумма бюджета
-	Percentage  float64 `json:"percentage"`    // Процент использования бюджета
-	IsExceeded  bool    `json:"is_exceeded"`   // Флаг превышения бюджета
-	IsNearLimit bool    `json:"is_near_limit"` // Флаг приближения к лимиту бюджета
+	Budget         *Budget          `json:"budget"`          // Информация о бюджете
+	PeriodType     BudgetPeriodType `json:"period_type"`     // Тип периода бюджета
+	PeriodStart    time.Time        `json:"period_start"`    // Начало активного периода
+	PeriodEnd      time.Time        `json:"period_end"`      // Конец активного периода
+	BaseAmount     Money            `json:"base_amount"`     // Сумма бюджета без переноса
+	CarriedAmount  Money            `json:"carried_amount"`  // Перенесенный остаток предыдущих периодов (отрицательный при перерасходе)
+	EffectiveLimit Money            `json:"effective_limit"` // Эффективный лимит с учетом переноса
+	Spent          Money            `json:"spent"`           // Потраченная сумма за период
+	Remaining      Money            `json:"remaining"`       // Оставшаяся сумма бюджета
+	Percentage     float64          `json:"percentage"`      // Процент использования бюджета
+	IsExceeded     bool             `json:"is_exceeded"`     // Флаг превышения бюджета
+	IsNearLimit    bool             `json:"is_near_limit"`   // Флаг приближения к лимиту бюджета
+
+	ByCategory []CategoryBudgetStatus `json:"by_category"` // Статус лимитов по категориям
+}
+
+type CategoryBudgetStatus struct {
+	CategoryID    uint    `json:"category_id"`    // Идентификатор категории
+	CategoryName  string  `json:"category_name"`  // Название категории
+	CategoryColor string  `json:"category_color"` // Цвет категории
+	Limit         Money   `json:"limit"`          // Лимит по категории
+	Spent         Money   `json:"spent"`          // Потраченная сумма по категории
+	Remaining     Money   `json:"remaining"`      // Оставшаяся сумма лимита
+	Percentage    float64 `json:"percentage"`     // Процент использования лимита
+	IsExceeded    bool    `json:"is_exceeded"`    // Флаг превышения лимита
+	IsNearLimit   bool    `json:"is_near_limit"`  // Флаг приближения к лимиту
 }
@@ -4,6 +4,16 @@ import (
 	"gorm.io/gorm"
 )
 
+type CategoryType string
+
+const (
+	CategoryTypeExpense CategoryType = "expense" // Категория расходов
+	CategoryTypeIncome  CategoryType = "income"  // Категория доходов
+)
+
+// MaxCategoryDepth максимальная глубина вложенности категорий (корневая категория имеет глубину 1)
+const MaxCategoryDepth = 3
+
 type Category struct {
 	gorm.Model
 	UserID    uint   `gorm:"not null;index" json:"user_id"` // Идентификатор пользователя владельца категории
@@ -12,19 +22,45 @@ type Category struct {
 	Icon      string `json:"icon"`                          // Иконка категории
 	IsDefault bool   `json:"is_default"`                    // Флаг системной категории по умолчанию (default false)
 
+	Type     CategoryType `gorm:"not null;default:expense;index" json:"type"` // Тип категории расходы или доходы
+	ParentID *uint        `gorm:"index" json:"parent_id"`                     // Родительская категория (nil для корневой)
+
 	// Связи
-	User     User      `gorm:"foreignKey:UserID" json:"-"`     // Пользователь владелец категории
-	Expenses []Expense `gorm:"foreignKey:CategoryID" json:"-"` // Все расходы в этой категории
+	User     User       `gorm:"foreignKey:UserID" json:"-"`                    // Пользователь владелец категории
+	Children []Category `gorm:"foreignKey:ParentID" json:"children,omitempty"` // Подкатегории, заполняются только для дерева
+	Expenses []Expense  `gorm:"foreignKey:CategoryID" json:"-"`                // Все расходы в этой категории
+	Incomes  []Income   `gorm:"foreignKey:CategoryID" json:"-"`                // Все доходы в этой категории
 }
 
 type CreateCategoryRequest struct {
-	Name  string `json:"name" binding:"required"` // Название новой категории
-	Color string `json:"color"`                   // Цвет категории
-	Icon  string `json:"icon"`                    // Иконка категории
+	Name  string       `json:"name" binding:"required"`                       // Название новой категории
+	Color string       `json:"color"`                                         // Цвет категории
+	Icon  string       `json:"icon"`                                          // Иконка категории
+	Type  CategoryType `json:"type" binding:"omitempty,oneof=expense income"` // Тип категории (по умолчанию expense)
+
+	ParentID *uint `json:"parent_id,omitempty"` // Родительская категория
 }
 
 type UpdateCategoryRequest struct {
 	Name  *string `json:"name,omitempty"`  // Новое название категории
 	Color *string `json:"color,omitempty"` // Новый цвет категории
 	Icon  *string `json:"icon,omitempty"`  // Новая иконка категории
+
+	ParentID *uint `json:"parent_id,omitempty"` // Новая родительская категория, 0 делает категорию корневой
+}
+
+type MergeCategoryRequest struct {
+	TargetCategoryID uint `json:"target_category_id" binding:"required"` // Категория, в которую переносятся операции
+}
+
+// CategoryMergeResult количество операций, перенесенных в целевую категорию
+type CategoryMergeResult struct {
+	TargetCategoryID  uint  `json:"target_category_id"` // Целевая категория
+	Expenses          int64 `json:"expenses"`           // Перенесено расходов
+	Splits            int64 `json:"splits"`             // Перенесено частей разделенных расходов
+	Incomes           int64 `json:"incomes"`            // Перенесено доходов
+	RecurringExpenses int64 `json:"recurring_expenses"` // Перенесено регулярных расходов
+	InstallmentPlans  int64 `json:"installment_plans"`  // Перенесено планов рассрочки
+	BudgetLimits      int64 `json:"budget_limits"`      // Перенесено или объединено лимитов бюджетов
+	Rules             int64 `json:"rules"`              // Перенесено правил категоризации
 }
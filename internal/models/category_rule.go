@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CategoryRule правило автоматического выбора категории расхода.
+// Условия объединяются по И, незаданные условия не проверяются. Правила проверяются по возрастанию
+// приоритета, срабатывает первое подходящее.
+type CategoryRule struct {
+	gorm.Model
+
+	UserID              uint    `gorm:"not null;index" json:"user_id"`        // Идентификатор пользователя владельца правила
+	Name                string  `gorm:"not null" json:"name"`                 // Название правила
+	Priority            int     `gorm:"not null;default:0" json:"priority"`   // Порядок проверки, меньшее значение проверяется раньше
+	IsActive            bool    `gorm:"not null" json:"is_active"`            // Правило применяется (без default: GORM подставил бы true вместо false)
+	DescriptionContains string  `json:"description_contains"`                 // Описание содержит подстроку (без учета регистра)
+	DescriptionRegex    string  `json:"description_regex"`                    // Описание соответствует регулярному выражению
+	MinAmount           *Money  `gorm:"type:numeric(14,2)" json:"min_amount"` // Сумма в базовой валюте не меньше
+	MaxAmount           *Money  `gorm:"type:numeric(14,2)" json:"max_amount"` // Сумма в базовой валюте не больше
+	Weekdays            []int   `gorm:"serializer:json" json:"weekdays"`      // Дни недели даты расхода, 0 — воскресенье
+	CategoryID          uint    `gorm:"not null;index" json:"category_id"`    // Назначаемая категория
+	SetDescription      *string `json:"set_description"`                      // Новое описание, для regex допускаются группы $1, ${name}
+
+	// Связи
+	User     User     `gorm:"foreignKey:UserID" json:"-"`            // Пользователь владелец правила
+	Category Category `gorm:"foreignKey:CategoryID" json:"category"` // Назначаемая категория
+}
+
+type CreateCategoryRuleRequest struct {
+	Name                string  `json:"name" binding:"required"`                                 // Название правила
+	Priority            int     `json:"priority"`                                                // Порядок проверки
+	IsActive            *bool   `json:"is_active,omitempty"`                                     // Правило применяется (по умолчанию true)
+	DescriptionContains string  `json:"description_contains"`                                    // Подстрока описания
+	DescriptionRegex    string  `json:"description_regex"`                                       // Регулярное выражение для описания
+	MinAmount           *Money  `json:"min_amount,omitempty"`                                    // Минимальная сумма
+	MaxAmount           *Money  `json:"max_amount,omitempty"`                                    // Максимальная сумма
+	Weekdays            []int   `json:"weekdays,omitempty" binding:"omitempty,dive,min=0,max=6"` // Дни недели, 0 — воскресенье
+	CategoryID          uint    `json:"category_id" binding:"required"`                          // Назначаемая категория
+	SetDescription      *string `json:"set_description,omitempty"`                               // Новое описание расхода
+}
+
+type UpdateCategoryRuleRequest struct {
+	Name                *string `json:"name,omitempty"`                                          // Новое название
+	Priority            *int    `json:"priority,omitempty"`                                      // Новый порядок проверки
+	IsActive            *bool   `json:"is_active,omitempty"`                                     // Включение или отключение правила
+	DescriptionContains *string `json:"description_contains,omitempty"`                          // Новая подстрока, пустая строка снимает условие
+	DescriptionRegex    *string `json:"description_regex,omitempty"`                             // Новое регулярное выражение, пустая строка снимает условие
+	MinAmount           *Money  `json:"min_amount,omitempty"`                                    // Новая минимальная сумма
+	MaxAmount           *Money  `json:"max_amount,omitempty"`                                    // Новая максимальная сумма
+	Weekdays            *[]int  `json:"weekdays,omitempty" binding:"omitempty,dive,min=0,max=6"` // Новые дни недели, пустой список снимает условие
+	CategoryID          *uint   `json:"category_id,omitempty"`                                   // Новая категория
+	SetDescription      *string `json:"set_description,omitempty"`                               // Новое описание, пустая строка отключает замену
+}
+
+// ApplyCategoryRulesRequest повторное применение правил к уже созданным расходам
+type ApplyCategoryRulesRequest struct {
+	CategoryID uint       `json:"category_id" binding:"required"` // Категория, в которой лежат нераспределенные расходы (например, «Прочее»)
+	StartDate  *time.Time `json:"start_date,omitempty"`           // Начало периода
+	EndDate    *time.Time `json:"end_date,omitempty"`             // Конец периода
+	DryRun     bool       `json:"dry_run"`                        // Только показать изменения
+}
+
+// CategoryRuleChange изменение расхода, выполненное правилом
+type CategoryRuleChange struct {
+	ExpenseID   uint   `json:"expense_id"`  // Расход
+	RuleID      uint   `json:"rule_id"`     // Сработавшее правило
+	CategoryID  uint   `json:"category_id"` // Новая категория
+	Description string `json:"description"` // Описание после применения правила
+}
+
+// ApplyCategoryRulesResult результат повторного применения правил
+type ApplyCategoryRulesResult struct {
+	DryRun  bool                 `json:"dry_run"` // Изменения не сохранены
+	Checked int                  `json:"checked"` // Проверено расходов
+	Updated int                  `json:"updated"` // Изменено расходов
+	Changes []CategoryRuleChange `json:"changes"` // Изменения по расходам
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// CategorySuggestionSource источник подсказки категории
+type CategorySuggestionSource string
+
+const (
+	CategorySuggestionSourceRule    CategorySuggestionSource = "rule"    // Сработало правило категоризации
+	CategorySuggestionSourceHistory CategorySuggestionSource = "history" // Предсказание по истории расходов
+)
+
+// CategorySample расход из истории пользователя, на котором обучается подсказка категорий
+type CategorySample struct {
+	CategoryID  uint   // Категория расхода
+	Description string // Описание расхода
+	Amount      Money  // Сумма в базовой валюте
+}
+
+type CategorySuggestionRequest struct {
+	Description string     `json:"description" binding:"required"`         // Описание расхода
+	Amount      Money      `json:"amount" binding:"required,gt=0"`         // Сумма в базовой валюте
+	Date        *time.Time `json:"date,omitempty"`                         // Дата расхода для правил по дням недели (по умолчанию сегодня)
+	Limit       int        `json:"limit" binding:"omitempty,min=1,max=10"` // Количество подсказок (по умолчанию 3)
+}
+
+// CategorySuggestion вариант категории для расхода
+type CategorySuggestion struct {
+	CategoryID   uint                     `json:"category_id"`       // Категория
+	CategoryName string                   `json:"category_name"`     // Название категории
+	Confidence   float64                  `json:"confidence"`        // Уверенность от 0 до 1
+	Source       CategorySuggestionSource `json:"source"`            // Источник подсказки
+	RuleID       *uint                    `json:"rule_id,omitempty"` // Сработавшее правило
+}
+
+// CategorySuggestionResult подсказки категорий в порядке убывания уверенности
+type CategorySuggestionResult struct {
+	Suggestions []CategorySuggestion `json:"suggestions"` // Подсказки
+	Samples     int                  `json:"samples"`     // Количество расходов, на которых обучена модель
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultCurrency базовая валюта пользователя по умолчанию
+const DefaultCurrency = "RUB"
+
+// ExchangeRate курс валюты пользователя на дату: 1 единица FromCurrency = Rate единиц ToCurrency
+type ExchangeRate struct {
+	gorm.Model
+
+	UserID       uint      `gorm:"not null;default:0;uniqueIndex:idx_exchange_rate_user_pair_date" json:"user_id"`    // Идентификатор пользователя
+	FromCurrency string    `gorm:"not null;size:3;uniqueIndex:idx_exchange_rate_user_pair_date" json:"from_currency"` // Исходная валюта
+	ToCurrency   string    `gorm:"not null;size:3;uniqueIndex:idx_exchange_rate_user_pair_date" json:"to_currency"`   // Целевая валюта
+	Date         time.Time `gorm:"not null;uniqueIndex:idx_exchange_rate_user_pair_date" json:"date"`                 // Дата, с которой действует курс
+	Rate         float64   `gorm:"not null;type:decimal(18,8)" json:"rate"`                                           // Курс
+}
+
+type CreateExchangeRateRequest struct {
+	FromCurrency string    `json:"from_currency" binding:"required,len=3"` // Исходная валюта
+	ToCurrency   string    `json:"to_currency" binding:"required,len=3"`   // Целевая валюта
+	Date         time.Time `json:"date" binding:"required"`                // Дата курса
+	Rate         float64   `json:"rate" binding:"required,gt=0"`           // Курс должен быть больше нуля
+}
+
+type SetBaseCurrencyRequest struct {
+	Currency string `json:"currency" binding:"required,len=3"` // Новая базовая валюта
+}
+
+type ExchangeRateImportResult struct {
+	Imported int      `json:"imported"` // Количество загруженных курсов
+	Errors   []string `json:"errors"`   // Ошибки по строкам файла
+}
+
+var currencySymbols = map[string]string{
+	"RUB": "₽",
+	"USD": "$",
+	"EUR": "€",
+	"TRY": "₺",
+	"GBP": "£",
+	"KZT": "₸",
+	"CNY": "¥",
+}
+
+// FormatAmount форматирует сумму с символом валюты, для неизвестных валют выводит код
+func FormatAmount(amount Money, currency string) string {
+	if symbol, ok := currencySymbols[currency]; ok {
+		return fmt.Sprintf("%s %s", amount, symbol)
+	}
+	return fmt.Sprintf("%s %s", amount, currency)
+}
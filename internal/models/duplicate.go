@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	DefaultDuplicateWindowDays = 1   // Разница дат дубликатов по умолчанию, в днях
+	DefaultDuplicateSimilarity = 0.5 // Минимальное сходство описаний дубликатов по умолчанию
+)
+
+// DuplicateFilter параметры поиска возможных дубликатов расходов
+type DuplicateFilter struct {
+	UserID        uint       // Идентификатор пользователя
+	WindowDays    int        // Максимальная разница дат расходов в днях
+	MinSimilarity float64    // Минимальное сходство описаний от 0 до 1
+	StartDate     *time.Time // Начальная дата периода
+	EndDate       *time.Time // Конечная дата периода
+}
+
+// DuplicateGroup группа расходов с одинаковой суммой, близкими датами и похожими описаниями
+type DuplicateGroup struct {
+	Amount     Money     `json:"amount"`     // Сумма каждого расхода группы в базовой валюте
+	Similarity float64   `json:"similarity"` // Наименьшее сходство описаний внутри группы
+	Expenses   []Expense `json:"expenses"`   // Расходы группы, первым идет самый ранний
+}
+
+type MergeDuplicatesRequest struct {
+	KeepID        uint     `json:"keep_id" binding:"required"`                           // Расход, который остается
+	DuplicateIDs  []uint   `json:"duplicate_ids" binding:"required,min=1"`               // Удаляемые дубликаты
+	WindowDays    *int     `json:"days,omitempty" binding:"omitempty,min=0,max=31"`      // Окно дат, с которым найдена группа (по умолчанию 1)
+	MinSimilarity *float64 `json:"similarity,omitempty" binding:"omitempty,min=0,max=1"` // Сходство описаний, с которым найдена группа (по умолчанию 0.5)
+}
+
+// MergeDuplicatesResult результат объединения дубликатов
+type MergeDuplicatesResult struct {
+	Expense    *Expense `json:"expense"`     // Оставшийся расход
+	DeletedIDs []uint   `json:"deleted_ids"` // Удаленные дубликаты
+}
@@ -9,45 +9,71 @@ import (
 type Expense struct {
 	gorm.Model
 
-	UserID      uint      `gorm:"not null;index" json:"user_id"`             // Идентификатор пользователя
-	CategoryID  uint      `gorm:"not null;index" json:"category_id"`         // Идентификатор категории расхода
-	Amount      float64   `gorm:"not null;type:decimal(10,2)" json:"amount"` // Сумма расхода
-	Description string    `json:"description"`                               // Описание расхода
-	Date        time.Time `gorm:"not null;index" json:"date"`                // Дата расхода
+	UserID            uint      `gorm:"not null;index" json:"user_id"`                                // Идентификатор пользователя
+	AccountID         *uint     `gorm:"index" json:"account_id"`                                      // Идентификатор счета списания
+	CategoryID        uint      `gorm:"not null;index" json:"category_id"`                            // Идентификатор категории расхода
+	Amount            Money     `gorm:"not null;type:numeric(14,2)" json:"amount"`                    // Сумма расхода в базовой валюте пользователя
+	Currency          string    `gorm:"not null;size:3;default:RUB" json:"currency"`                  // Валюта, в которой совершен расход
+	OriginalAmount    Money     `gorm:"not null;default:0;type:numeric(14,2)" json:"original_amount"` // Сумма в исходной валюте
+	ExchangeRate      float64   `gorm:"not null;default:1;type:decimal(18,8)" json:"exchange_rate"`   // Курс пересчета в базовую валюту
+	Description       string    `json:"description"`                                                  // Описание расхода
+	Date              time.Time `gorm:"not null;index" json:"date"`                                   // Дата расхода
+	ExternalID        *string   `gorm:"size:255;index" json:"external_id,omitempty"`                  // Идентификатор операции в банковской выписке
+	InstallmentPlanID *uint     `gorm:"index" json:"installment_plan_id,omitempty"`                   // План рассрочки, платежом по которому является расход
+
+	// Результаты полнотекстового поиска, заполняются только при поиске по q
+	SearchRank    *float64 `gorm:"->;-:migration" json:"search_rank,omitempty"`    // Релевантность расхода запросу
+	SearchSnippet *string  `gorm:"->;-:migration" json:"search_snippet,omitempty"` // Описание и категория с найденными словами в <mark>
+
 	// Связи
-	User     User     `gorm:"foreignKey:UserID" json:"-"`            // Пользователь владелец расхода
-	Category Category `gorm:"foreignKey:CategoryID" json:"category"` // Категория расхода
+	User     User           `gorm:"foreignKey:UserID" json:"-"`            // Пользователь владелец расхода
+	Category Category       `gorm:"foreignKey:CategoryID" json:"category"` // Категория расхода
+	Tags     []Tag          `gorm:"many2many:expense_tags;" json:"tags"`   // Теги расхода
+	Splits   []ExpenseSplit `gorm:"foreignKey:ExpenseID" json:"splits"`    // Части расхода по категориям
 }
 
 type CreateExpenseRequest struct {
-	CategoryID  uint      `json:"category_id" binding:"required"` // Идентификатор категории расхода
-	Amount      float64   `json:"amount" binding:"required,gt=0"` // Сумма расхода должна быть больше нуля
-	Description string    `json:"description"`                    // Описание расхода
-	Date        time.Time `json:"date" binding:"required"`        // Дата расхода
+	AccountID   *uint                 `json:"account_id,omitempty"`                      // Идентификатор счета (опционально)
+	CategoryID  uint                  `json:"category_id"`                               // Идентификатор категории расхода, без нее категорию выбирают правила
+	Amount      Money                 `json:"amount" binding:"required,gt=0"`            // Сумма расхода должна быть больше нуля
+	Currency    string                `json:"currency" binding:"omitempty,len=3"`        // Валюта расхода (по умолчанию базовая)
+	Description string                `json:"description"`                               // Описание расхода
+	Date        time.Time             `json:"date" binding:"required"`                   // Дата расхода
+	TagIDs      []uint                `json:"tag_ids,omitempty"`                         // Теги расхода (опционально)
+	Splits      []ExpenseSplitRequest `json:"splits,omitempty" binding:"omitempty,dive"` // Части расхода по категориям (опционально)
 }
 
 type UpdateExpenseRequest struct {
-	CategoryID  *uint      `json:"category_id,omitempty"` // Новый идентификатор категории
-	Amount      *float64   `json:"amount,omitempty"`      // Новая сумма расхода
-	Description *string    `json:"description,omitempty"` // Новое описание расхода
-	Date        *time.Time `json:"date,omitempty"`        // Новая дата расхода
+	AccountID   *uint                  `json:"account_id,omitempty"`                         // Новый идентификатор счета
+	CategoryID  *uint                  `json:"category_id,omitempty"`                        // Новый идентификатор категории
+	Amount      *Money                 `json:"amount,omitempty"`                             // Новая сумма расхода
+	Currency    *string                `json:"currency,omitempty" binding:"omitempty,len=3"` // Новая валюта расхода
+	Description *string                `json:"description,omitempty"`                        // Новое описание расхода
+	Date        *time.Time             `json:"date,omitempty"`                               // Новая дата расхода
+	TagIDs      *[]uint                `json:"tag_ids,omitempty"`                            // Новый набор тегов, пустой список снимает все теги
+	Splits      *[]ExpenseSplitRequest `json:"splits,omitempty" binding:"omitempty,dive"`    // Новые части расхода, пустой список отменяет разделение
 }
 
 type ExpenseFilter struct {
-	UserID     uint       // Идентификатор пользователя для фильтрации
-	CategoryID *uint      // Идентификатор категории для фильтрации
-	StartDate  *time.Time // Начальная дата периода для фильтрации
-	EndDate    *time.Time // Конечная дата периода для фильтрации
-	MinAmount  *float64   // Минимальная сумма для фильтрации
-	MaxAmount  *float64   // Максимальная сумма для фильтрации
-	Limit      *int       // количество записей
-	Offset     *int       // смещение
+	UserID               uint       // Идентификатор пользователя для фильтрации
+	AccountID            *uint      // Идентификатор счета для фильтрации
+	CategoryID           *uint      // Идентификатор категории для фильтрации
+	IncludeSubcategories bool       // Учитывать подкатегории при фильтрации по категории
+	StartDate            *time.Time // Начальная дата периода для фильтрации
+	EndDate              *time.Time // Конечная дата периода для фильтрации
+	MinAmount            *Money     // Минимальная сумма для фильтрации
+	MaxAmount            *Money     // Максимальная сумма для фильтрации
+	TagIDs               []uint     // Расходы, отмеченные хотя бы одним из тегов
+	ExcludeTagIDs        []uint     // Расходы без указанных тегов
+	Search               string     // Строка полнотекстового поиска по описанию и категории
+	Limit                *int       // количество записей
+	Offset               *int       // смещение
 }
 
 type ExpenseGroup struct {
 	Period   string    `json:"period"`   // Период группировки день неделя месяц
 	Date     time.Time `json:"date"`     // Дата начала периода
-	Total    float64   `json:"total"`    // Общая сумма расходов за период
+	Total    Money     `json:"total"`    // Общая сумма расходов за период
 	Count    int       `json:"count"`    // Количество расходов за период
 	Expenses []Expense `json:"expenses"` // Список расходов в этом периоде
 }
@@ -0,0 +1,85 @@
+package models
+
+// MaxBulkExpenses наибольшее число расходов в одной массовой операции
+const MaxBulkExpenses = 1000
+
+// BulkExpenseStatus итог массовой операции для одного расхода
+type BulkExpenseStatus string
+
+const (
+	BulkExpenseUpdated   BulkExpenseStatus = "updated"   // Расход изменен
+	BulkExpenseDeleted   BulkExpenseStatus = "deleted"   // Расход удален в корзину
+	BulkExpenseUnchanged BulkExpenseStatus = "unchanged" // Расход уже в нужном состоянии
+	BulkExpenseNotFound  BulkExpenseStatus = "not_found" // Расход не найден
+	BulkExpenseForbidden BulkExpenseStatus = "forbidden" // Расход принадлежит другому пользователю
+	BulkExpenseFailed    BulkExpenseStatus = "failed"    // Изменение нельзя применить к расходу
+)
+
+// ExpenseSelection расходы массовой операции: список идентификаторов или фильтр
+type ExpenseSelection struct {
+	IDs    []uint         // Идентификаторы расходов
+	Filter *ExpenseFilter // Фильтр расходов пользователя
+}
+
+// BulkExpenseFilter фильтр массовой операции, поля совпадают с параметрами списка расходов
+type BulkExpenseFilter struct {
+	AccountID            *uint  `json:"account_id,omitempty"`            // Идентификатор счета
+	CategoryID           *uint  `json:"category_id,omitempty"`           // Идентификатор категории
+	IncludeSubcategories bool   `json:"include_subcategories,omitempty"` // Учитывать подкатегории категории
+	StartDate            string `json:"start_date,omitempty"`            // Начальная дата периода в формате YYYY-MM-DD
+	EndDate              string `json:"end_date,omitempty"`              // Конечная дата периода в формате YYYY-MM-DD
+	MinAmount            *Money `json:"min_amount,omitempty"`            // Минимальная сумма
+	MaxAmount            *Money `json:"max_amount,omitempty"`            // Максимальная сумма
+	TagIDs               []uint `json:"tag_ids,omitempty"`               // Расходы, отмеченные хотя бы одним из тегов
+	ExcludeTagIDs        []uint `json:"exclude_tag_ids,omitempty"`       // Расходы без указанных тегов
+	Search               string `json:"q,omitempty"`                     // Строка полнотекстового поиска
+}
+
+// BulkExpenseSelection выбор расходов в теле запроса массовой операции, задается ровно одно из полей
+type BulkExpenseSelection struct {
+	IDs    []uint             `json:"ids,omitempty"`    // Идентификаторы расходов
+	Filter *BulkExpenseFilter `json:"filter,omitempty"` // Фильтр расходов
+}
+
+type BulkRecategorizeRequest struct {
+	BulkExpenseSelection
+	CategoryID uint `json:"category_id" binding:"required"` // Новая категория расходов
+}
+
+type BulkDescribeRequest struct {
+	BulkExpenseSelection
+	DescriptionRewrite
+}
+
+// DescriptionRewrite изменение описаний: новое описание целиком или замена подстроки find на replace
+type DescriptionRewrite struct {
+	Description *string `json:"description,omitempty"` // Новое описание, пустая строка очищает описание
+	Find        string  `json:"find,omitempty"`        // Заменяемая подстрока с учетом регистра
+	Replace     string  `json:"replace"`               // Строка, на которую заменяется find
+}
+
+type BulkShiftDatesRequest struct {
+	BulkExpenseSelection
+	Months int `json:"months"` // Сдвиг в месяцах, может быть отрицательным
+	Days   int `json:"days"`   // Сдвиг в днях, может быть отрицательным
+}
+
+type BulkDeleteRequest struct {
+	BulkExpenseSelection
+}
+
+// BulkExpenseItemResult итог массовой операции для одного расхода
+type BulkExpenseItemResult struct {
+	ID     uint              `json:"id"`              // Идентификатор расхода
+	Status BulkExpenseStatus `json:"status"`          // Итог операции
+	Error  string            `json:"error,omitempty"` // Причина, по которой расход не изменен
+}
+
+// BulkExpenseResult отчет массовой операции
+type BulkExpenseResult struct {
+	Matched   int                     `json:"matched"`   // Выбрано расходов
+	Changed   int                     `json:"changed"`   // Изменено или удалено
+	Unchanged int                     `json:"unchanged"` // Уже были в нужном состоянии
+	Failed    int                     `json:"failed"`    // Не найдены, чужие или не могут быть изменены
+	Items     []BulkExpenseItemResult `json:"items"`     // Итог по каждому расходу
+}
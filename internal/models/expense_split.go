@@ -0,0 +1,23 @@
+package models
+
+import "gorm.io/gorm"
+
+// ExpenseSplit часть расхода, отнесенная к отдельной категории, например продукты и бытовая химия
+// из одного чека. Суммы частей в сумме дают сумму расхода.
+type ExpenseSplit struct {
+	gorm.Model
+	ExpenseID      uint   `gorm:"not null;index" json:"expense_id"`                   // Идентификатор расхода
+	CategoryID     uint   `gorm:"not null;index" json:"category_id"`                  // Категория части
+	Amount         Money  `gorm:"not null;type:numeric(14,2)" json:"amount"`          // Сумма части в базовой валюте
+	OriginalAmount Money  `gorm:"not null;type:numeric(14,2)" json:"original_amount"` // Сумма части в валюте расхода
+	Description    string `json:"description"`                                        // Описание части
+
+	// Связи
+	Category Category `gorm:"foreignKey:CategoryID" json:"category"` // Категория части
+}
+
+type ExpenseSplitRequest struct {
+	CategoryID  uint   `json:"category_id" binding:"required"` // Категория части
+	Amount      Money  `json:"amount" binding:"required,gt=0"` // Сумма части в валюте расхода
+	Description string `json:"description"`                    // Описание части (опционально)
+}
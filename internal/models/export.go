@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ExportFormat формат выгрузки расходов
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"  // CSV с заголовком
+	ExportFormatXLSX ExportFormat = "xlsx" // Книга Excel с одним листом
+	ExportFormatJSON ExportFormat = "json" // Массив объектов JSON
+	ExportFormatZIP  ExportFormat = "zip"  // ZIP-архив, используется для выгрузки данных пользователя
+)
+
+// ExpenseExportRow строка выгрузки расходов с данными категории, счета и тегов
+type ExpenseExportRow struct {
+	ID             uint      // Идентификатор расхода
+	Date           time.Time // Дата расхода
+	Amount         Money     // Сумма в базовой валюте
+	Currency       string    // Валюта расхода
+	OriginalAmount Money     // Сумма в валюте расхода
+	ExchangeRate   float64   // Курс пересчета в базовую валюту
+	Description    string    // Описание
+	CategoryID     uint      // Идентификатор категории
+	CategoryName   string    // Название категории
+	CategoryColor  string    // Цвет категории
+	AccountID      *uint     // Идентификатор счета
+	AccountName    string    // Название счета
+	Tags           string    // Названия тегов через запятую
+}
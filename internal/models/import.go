@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// CSVImportRequest параметры импорта расходов из CSV, передаются полями multipart-формы вместе с файлом.
+// Столбец задается номером (с 1) или названием из строки заголовка.
+type CSVImportRequest struct {
+	DateColumn        string `form:"date_column" binding:"required"`   // Столбец даты
+	AmountColumn      string `form:"amount_column" binding:"required"` // Столбец суммы
+	DescriptionColumn string `form:"description_column"`               // Столбец описания (опционально)
+	CategoryColumn    string `form:"category_column"`                  // Столбец названия категории (опционально)
+
+	HasHeader         bool   `form:"has_header"`                          // Первая строка файла содержит заголовки
+	Delimiter         string `form:"delimiter" binding:"omitempty,len=1"` // Разделитель столбцов (по умолчанию ",")
+	DateFormat        string `form:"date_format"`                         // Формат даты, например DD.MM.YYYY (по умолчанию YYYY-MM-DD)
+	Currency          string `form:"currency" binding:"omitempty,len=3"`  // Валюта сумм (по умолчанию базовая)
+	AccountID         *uint  `form:"account_id"`                          // Счет для всех импортируемых расходов
+	DefaultCategoryID *uint  `form:"default_category_id"`                 // Категория для строк без категории
+	CreateCategories  bool   `form:"create_categories"`                   // Создавать отсутствующие категории при загрузке
+	DryRun            bool   `form:"dry_run"`                             // Только проверить файл без сохранения
+}
+
+// StatementFormat формат банковской выписки
+type StatementFormat string
+
+const (
+	StatementFormatOFX StatementFormat = "ofx" // OFX и QFX
+	StatementFormatQIF StatementFormat = "qif" // Quicken Interchange Format
+)
+
+// StatementImportRequest параметры импорта банковской выписки, передаются полями multipart-формы вместе с файлом
+type StatementImportRequest struct {
+	CategoryID *uint  `form:"category_id"`                        // Категория для операций без подходящей категории
+	AccountID  *uint  `form:"account_id"`                         // Счет для всех импортируемых расходов
+	Currency   string `form:"currency" binding:"omitempty,len=3"` // Валюта, если она не указана в выписке (по умолчанию базовая)
+	DateFormat string `form:"date_format"`                        // Формат даты QIF, например DD.MM.YYYY (по умолчанию MM/DD/YYYY)
+	DryRun     bool   `form:"dry_run"`                            // Только проверить файл без сохранения
+}
+
+// ImportRow строка файла, успешно разобранная в расход
+type ImportRow struct {
+	Line         int       `json:"line"`                  // Номер строки в файле
+	Date         time.Time `json:"date"`                  // Дата расхода
+	Amount       Money     `json:"amount"`                // Сумма в валюте файла
+	Description  string    `json:"description"`           // Описание
+	CategoryName string    `json:"category_name"`         // Название категории из файла
+	CategoryID   *uint     `json:"category_id,omitempty"` // Найденная категория (пусто для неизвестной)
+	ExternalID   string    `json:"external_id,omitempty"` // Идентификатор операции в банковской выписке
+}
+
+// ImportResult результат проверки или загрузки файла
+type ImportResult struct {
+	DryRun            bool        `json:"dry_run"`            // Файл только проверен
+	Total             int         `json:"total"`              // Количество строк с данными
+	Valid             int         `json:"valid"`              // Количество корректных строк
+	Imported          int         `json:"imported"`           // Количество сохраненных расходов
+	Rows              []ImportRow `json:"rows"`               // Разобранные строки
+	UnknownCategories []string    `json:"unknown_categories"` // Категории из файла, которых нет у пользователя
+	Errors            []string    `json:"errors"`             // Ошибки по строкам файла
+
+	Duplicates int         `json:"duplicates,omitempty"` // Операции выписки, импортированные ранее и пропущенные
+	Credits    []ImportRow `json:"credits,omitempty"`    // Поступления и возвраты из выписки, не сохраняются как расходы
+}
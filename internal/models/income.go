@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Income struct {
+	gorm.Model
+
+	UserID      uint      `gorm:"not null;index" json:"user_id"`             // Идентификатор пользователя
+	AccountID   *uint     `gorm:"index" json:"account_id"`                   // Идентификатор счета зачисления
+	CategoryID  uint      `gorm:"not null;index" json:"category_id"`         // Идентификатор категории дохода
+	Amount      Money     `gorm:"not null;type:numeric(14,2)" json:"amount"` // Сумма дохода
+	Description string    `json:"description"`                               // Описание дохода
+	Date        time.Time `gorm:"not null;index" json:"date"`                // Дата поступления
+	// Связи
+	User     User     `gorm:"foreignKey:UserID" json:"-"`            // Пользователь получатель дохода
+	Category Category `gorm:"foreignKey:CategoryID" json:"category"` // Категория дохода
+}
+
+type CreateIncomeRequest struct {
+	AccountID   *uint     `json:"account_id,omitempty"`           // Идентификатор счета (опционально)
+	CategoryID  uint      `json:"category_id" binding:"required"` // Идентификатор категории дохода
+	Amount      Money     `json:"amount" binding:"required,gt=0"` // Сумма дохода должна быть больше нуля
+	Description string    `json:"description"`                    // Описание дохода
+	Date        time.Time `json:"date" binding:"required"`        // Дата поступления
+}
+
+type UpdateIncomeRequest struct {
+	AccountID   *uint      `json:"account_id,omitempty"`  // Новый идентификатор счета
+	CategoryID  *uint      `json:"category_id,omitempty"` // Новый идентификатор категории
+	Amount      *Money     `json:"amount,omitempty"`      // Новая сумма дохода
+	Description *string    `json:"description,omitempty"` // Новое описание дохода
+	Date        *time.Time `json:"date,omitempty"`        // Новая дата поступления
+}
+
+type IncomeFilter struct {
+	UserID               uint       // Идентификатор пользователя для фильтрации
+	AccountID            *uint      // Идентификатор счета для фильтрации
+	CategoryID           *uint      // Идентификатор категории для фильтрации
+	IncludeSubcategories bool       // Учитывать подкатегории при фильтрации по категории
+	StartDate            *time.Time // Начальная дата периода для фильтрации
+	EndDate              *time.Time // Конечная дата периода для фильтрации
+	MinAmount            *Money     // Минимальная сумма для фильтрации
+	MaxAmount            *Money     // Максимальная сумма для фильтрации
+	Limit                *int       // количество записей
+	Offset               *int       // смещение
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxInstallmentPayments наибольшее число ежемесячных платежей плана рассрочки
+const MaxInstallmentPayments = 360
+
+// InstallmentPlan покупка в рассрочку или в кредит, которая оплачивается ежемесячными платежами.
+// Платежи создаются расходами в день списания, поэтому бюджеты учитывают только ежемесячный платеж.
+type InstallmentPlan struct {
+	gorm.Model
+
+	UserID       uint       `gorm:"not null;index" json:"user_id"`                   // Идентификатор пользователя
+	CategoryID   uint       `gorm:"not null;index" json:"category_id"`               // Категория расходов-платежей
+	AccountID    *uint      `gorm:"index" json:"account_id"`                         // Счет списания платежей
+	Description  string     `json:"description"`                                     // Описание покупки
+	TotalAmount  Money      `gorm:"not null;type:numeric(14,2)" json:"total_amount"` // Полная сумма к оплате, для кредита вместе с переплатой
+	Payments     int        `gorm:"not null" json:"payments"`                        // Количество ежемесячных платежей
+	StartDate    time.Time  `gorm:"not null" json:"start_date"`                      // Дата первого платежа, следующие в тот же день месяца
+	PaymentsMade int        `gorm:"not null;default:0" json:"payments_made"`         // Сколько платежей уже создано расходами
+	NextDate     *time.Time `gorm:"index" json:"next_date"`                          // Дата следующего платежа, пусто после последнего
+
+	// Вычисляются по графику платежей
+	PaymentAmount     Money `gorm:"-" json:"payment_amount"`     // Ежемесячный платеж, последний может отличаться на копейки округления
+	PaidAmount        Money `gorm:"-" json:"paid_amount"`        // Сумма существующих расходов-платежей
+	RemainingAmount   Money `gorm:"-" json:"remaining_amount"`   // Остаток к оплате
+	RemainingPayments int   `gorm:"-" json:"remaining_payments"` // Осталось платежей за вычетом существующих расходов-платежей
+
+	// Связи
+	User     User     `gorm:"foreignKey:UserID" json:"-"`            // Пользователь владелец плана
+	Category Category `gorm:"foreignKey:CategoryID" json:"category"` // Категория платежей
+}
+
+// InstallmentPayment платеж по графику плана рассрочки
+type InstallmentPayment struct {
+	PlanID      uint      `json:"plan_id"`     // Идентификатор плана
+	Description string    `json:"description"` // Описание покупки
+	Number      int       `json:"number"`      // Номер платежа, начиная с 1
+	Date        time.Time `json:"date"`        // Дата списания
+	Amount      Money     `json:"amount"`      // Сумма платежа
+	Paid        bool      `json:"paid"`        // Платеж уже создан расходом
+}
+
+// InstallmentPaid существующие расходы-платежи плана рассрочки
+type InstallmentPaid struct {
+	Amount   Money // Сумма платежей
+	Payments int   // Количество платежей
+}
+
+type CreateInstallmentPlanRequest struct {
+	CategoryID  uint      `json:"category_id" binding:"required"`            // Категория расходов-платежей
+	AccountID   *uint     `json:"account_id,omitempty"`                      // Счет списания (опционально)
+	Description string    `json:"description"`                               // Описание покупки
+	TotalAmount Money     `json:"total_amount" binding:"required,gt=0"`      // Полная сумма к оплате должна быть больше нуля
+	Payments    int       `json:"payments" binding:"required,min=1,max=360"` // Количество ежемесячных платежей
+	StartDate   time.Time `json:"start_date" binding:"required"`             // Дата первого платежа
+}
+
+type UpdateInstallmentPlanRequest struct {
+	CategoryID  *uint   `json:"category_id,omitempty"` // Новая категория следующих платежей
+	AccountID   *uint   `json:"account_id,omitempty"`  // Новый счет списания следующих платежей
+	Description *string `json:"description,omitempty"` // Новое описание покупки
+}
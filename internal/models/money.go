@@ -0,0 +1,183 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money денежная сумма в минимальных единицах валюты (копейках, центах).
+// В JSON и в БД представляется десятичным числом с двумя знаками после запятой,
+// поэтому арифметика над суммами выполняется без ошибок округления float64.
+type Money int64
+
+var errInvalidMoney = errors.New("некорректная денежная сумма")
+
+// MoneyFromFloat переводит сумму с плавающей точкой в Money с математическим округлением
+func MoneyFromFloat(value float64) Money {
+	return Money(math.Round(value * 100))
+}
+
+// ParseMoney разбирает десятичную запись суммы, например "1234.5" или "-0.01".
+// Знаки после второго округляются половиной от нуля.
+func ParseMoney(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.Contains(value, "/") {
+		return 0, errInvalidMoney
+	}
+
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return 0, errInvalidMoney
+	}
+	return moneyFromRat(rat)
+}
+
+func moneyFromRat(rat *big.Rat) (Money, error) {
+	scaled := new(big.Rat).Mul(rat, big.NewRat(100, 1))
+
+	num := new(big.Int).Set(scaled.Num())
+	den := scaled.Denom()
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	// Округление половиной от нуля
+	if rem.Sign() != 0 {
+		twice := new(big.Int).Abs(rem)
+		twice.Mul(twice, big.NewInt(2))
+		if twice.Cmp(den) >= 0 {
+			if num.Sign() < 0 {
+				quo.Sub(quo, big.NewInt(1))
+			} else {
+				quo.Add(quo, big.NewInt(1))
+			}
+		}
+	}
+
+	if !quo.IsInt64() {
+		return 0, errInvalidMoney
+	}
+	return Money(quo.Int64()), nil
+}
+
+// Minor возвращает сумму в минимальных единицах
+func (m Money) Minor() int64 {
+	return int64(m)
+}
+
+// Float64 возвращает сумму в основных единицах, используется только для вычисления долей и процентов
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// MulRate умножает сумму на курс или коэффициент с округлением до минимальных единиц
+func (m Money) MulRate(rate float64) Money {
+	rat := new(big.Rat).SetFloat64(rate)
+	if rat == nil {
+		return 0
+	}
+	rat.Mul(rat, big.NewRat(int64(m), 100))
+	result, err := moneyFromRat(rat)
+	if err != nil {
+		return 0
+	}
+	return result
+}
+
+// Div делит сумму на n частей с округлением, например для среднего значения
+func (m Money) Div(n int) Money {
+	if n == 0 {
+		return 0
+	}
+	result, err := moneyFromRat(big.NewRat(int64(m), int64(n)*100))
+	if err != nil {
+		return 0
+	}
+	return result
+}
+
+// Percent возвращает долю суммы от total в процентах
+func (m Money) Percent(total Money) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(m) / float64(total) * 100
+}
+
+func (m Money) String() string {
+	sign := ""
+	value := int64(m)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/100, value%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON принимает сумму как число (1000.5) или строку ("1000.50")
+func (m *Money) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+
+	value, err := ParseMoney(raw)
+	if err != nil {
+		return err
+	}
+	*m = value
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		value, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = value
+		return nil
+	case string:
+		value, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = value
+		return nil
+	// Целое число БД возвращает для целочисленных выражений (например, COALESCE(..., 0)):
+	// это сумма в основных единицах, поэтому она переводится в копейки с проверкой переполнения
+	case int64:
+		value, err := moneyFromRat(new(big.Rat).SetInt64(v))
+		if err != nil {
+			return err
+		}
+		*m = value
+		return nil
+	case float64:
+		if math.IsNaN(v) || math.Abs(v*100) >= math.MaxInt64 {
+			return errInvalidMoney
+		}
+		*m = MoneyFromFloat(v)
+		return nil
+	default:
+		return fmt.Errorf("%w: неподдерживаемый тип %T", errInvalidMoney, src)
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultPageLimit = 50  // Размер страницы по умолчанию
+	MaxPageLimit     = 200 // Максимальный размер страницы
+)
+
+// SortDirection направление сортировки списка
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"  // По возрастанию
+	SortDesc SortDirection = "desc" // По убыванию
+)
+
+// Поля сортировки списков. Какие из них доступны, зависит от списка.
+const (
+	SortByDate      = "date"       // Дата операции (для бюджетов — месяц, для регулярных расходов — следующая дата)
+	SortByAmount    = "amount"     // Сумма
+	SortByCreatedAt = "created_at" // Время создания записи
+	SortByRelevance = "relevance"  // Релевантность полнотекстовому поиску
+)
+
+var ErrInvalidCursor = errors.New("некорректный курсор")
+
+// PageRequest параметры страницы списка с пагинацией по курсору
+type PageRequest struct {
+	Limit     int           // Размер страницы
+	Sort      string        // Поле сортировки
+	Direction SortDirection // Направление сортировки
+	Cursor    *Cursor       // Позиция после последней записи предыдущей страницы, nil для первой страницы
+}
+
+// Cursor позиция в отсортированном списке: значение поля сортировки и идентификатор последней записи
+// предыдущей страницы. Следующая страница начинается строго после этой позиции, поэтому новые записи
+// не сдвигают страницы, как при смещении offset.
+type Cursor struct {
+	Sort      string          `json:"s"`  // Поле сортировки, для которого построен курсор
+	Direction SortDirection   `json:"d"`  // Направление сортировки
+	Value     json.RawMessage `json:"v"`  // Значение поля сортировки у последней записи
+	ID        uint            `json:"id"` // Идентификатор последней записи
+}
+
+// Encode кодирует курсор в непрозрачную строку для next_cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает строку, полученную из next_cursor
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 || len(cursor.Value) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Page страница списка
+type Page[T any] struct {
+	Items      []T     `json:"items"`       // Записи страницы
+	NextCursor *string `json:"next_cursor"` // Курсор следующей страницы, null на последней странице
+	HasMore    bool    `json:"has_more"`    // Есть ли записи после этой страницы
+	Total      int64   `json:"total"`       // Количество записей по фильтру на всех страницах
+	Limit      int     `json:"limit"`       // Размер страницы
+}
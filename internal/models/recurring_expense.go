@@ -19,7 +19,7 @@ type RecurringExpense struct {
 	gorm.Model
 	UserID      uint                 `gorm:"not null;index" json:"user_id"`             // Идентификатор пользователя
 	CategoryID  uint                 `gorm:"not null;index" json:"category_id"`         // Идентификатор категории расхода
-	Amount      float64              `gorm:"not null;type:decimal(10,2)" json:"amount"` // Сумма регулярного расхода
+	Amount      Money                `gorm:"not null;type:numeric(14,2)" json:"amount"` // Сумма регулярного расхода
 	Description string               `json:"description"`                               // Описание регулярного расхода
 	Type        RecurringExpenseType `gorm:"not null" json:"type"`                      // Тип повторения ежедневно еженедельно ежемесячно ежегодно
 	DayOfMonth  *int                 `json:"day_of_month"`                              // День месяца для ежемесячных расходов от 1 до 31
@@ -28,25 +28,28 @@ type RecurringExpense struct {
 	NextDate    time.Time            `gorm:"not null;index" json:"next_date"`           // Следующая дата автоматического создания расхода
 
 	// Связи
-	User     User     `gorm:"foreignKey:UserID" json:"-"`            // Пользователь владелец регулярного расхода
-	Category Category `gorm:"foreignKey:CategoryID" json:"category"` // Категория регулярного расхода
+	User     User     `gorm:"foreignKey:UserID" json:"-"`                    // Пользователь владелец регулярного расхода
+	Category Category `gorm:"foreignKey:CategoryID" json:"category"`         // Категория регулярного расхода
+	Tags     []Tag    `gorm:"many2many:recurring_expense_tags;" json:"tags"` // Теги, переносимые на создаваемые расходы
 }
 
 type CreateRecurringExpenseRequest struct {
 	CategoryID  uint                 `json:"category_id" binding:"required"`                            // Идентификатор категории расхода
-	Amount      float64              `json:"amount" binding:"required,gt=0"`                            // Сумма расхода должна быть больше нуля
+	Amount      Money                `json:"amount" binding:"required,gt=0"`                            // Сумма расхода должна быть больше нуля
 	Description string               `json:"description"`                                               // Описание регулярного расхода
 	Type        RecurringExpenseType `json:"type" binding:"required,oneof=daily weekly monthly yearly"` // Тип повторения
 	DayOfMonth  *int                 `json:"day_of_month"`                                              // День месяца для ежемесячных расходов
 	DayOfWeek   *int                 `json:"day_of_week"`                                               // День недели для еженедельных расходов
+	TagIDs      []uint               `json:"tag_ids,omitempty"`                                         // Теги регулярного расхода (опционально)
 }
 
 type UpdateRecurringExpenseRequest struct {
 	CategoryID  *uint                 `json:"category_id,omitempty"`  // Новый идентификатор категории
-	Amount      *float64              `json:"amount,omitempty"`       // Новая сумма расхода
+	Amount      *Money                `json:"amount,omitempty"`       // Новая сумма расхода
 	Description *string               `json:"description,omitempty"`  // Новое описание расхода
 	Type        *RecurringExpenseType `json:"type,omitempty"`         // Новый тип повторения
 	DayOfMonth  *int                  `json:"day_of_month,omitempty"` // Новый день месяца
 	DayOfWeek   *int                  `json:"day_of_week,omitempty"`  // Новый день недели
 	IsActive    *bool                 `json:"is_active,omitempty"`    // Новый статус активности
+	TagIDs      *[]uint               `json:"tag_ids,omitempty"`      // Новый набор тегов, пустой список снимает все теги
 }
@@ -15,26 +15,34 @@ type CategoryStatistics struct {
 	CategoryID    uint    `json:"category_id"`    // Идентификатор категории
 	CategoryName  string  `json:"category_name"`  // Название категории
 	CategoryColor string  `json:"category_color"` // Цвет категории
-	TotalAmount   float64 `json:"total_amount"`   // Общая сумма расходов в категории
-	Count         int     `json:"count"`          // Количество расходов в категории
+	TotalAmount   Money   `json:"total_amount"`   // Общая сумма расходов в категории вместе с подкатегориями
+	Count         int     `json:"count"`          // Количество расходов в категории вместе с подкатегориями
 	Percentage    float64 `json:"percentage"`     // Процент от общей суммы всех расходов
+
+	ParentID  *uint                `json:"parent_id"`          // Родительская категория
+	OwnAmount Money                `json:"own_amount"`         // Сумма расходов непосредственно в категории без подкатегорий
+	Children  []CategoryStatistics `json:"children,omitempty"` // Статистика подкатегорий
 }
 
 type PeriodStatistics struct {
 	Period        StatisticsPeriod     `json:"period"`         // Период статистики день неделя месяц год
 	StartDate     time.Time            `json:"start_date"`     // Начальная дата периода
 	EndDate       time.Time            `json:"end_date"`       // Конечная дата периода
-	TotalAmount   float64              `json:"total_amount"`   // Общая сумма расходов за период
+	TotalAmount   Money                `json:"total_amount"`   // Общая сумма расходов за период
 	Count         int                  `json:"count"`          // Количество расходов за период
-	AverageAmount float64              `json:"average_amount"` // Средняя сумма расхода за период
-	ByCategory    []CategoryStatistics `json:"by_category"`    // Статистика по каждой категории
+	AverageAmount Money                `json:"average_amount"` // Средняя сумма расхода за период
+	TotalIncome   Money                `json:"total_income"`   // Общая сумма доходов за период
+	NetAmount     Money                `json:"net_amount"`     // Чистый денежный поток доходы минус расходы
+	SavingsRate   float64              `json:"savings_rate"`   // Доля сбережений от доходов в процентах
+	ByCategory    []CategoryStatistics `json:"by_category"`    // Статистика по корневым категориям с вложенными подкатегориями
+	ByTag         []TagStatistics      `json:"by_tag"`         // Статистика по тегам, расход с несколькими тегами учитывается в каждом
 }
 
 type ExpenseDistribution struct {
 	CategoryID    uint    `json:"category_id"`    // Идентификатор категории
 	CategoryName  string  `json:"category_name"`  // Название категории
 	CategoryColor string  `json:"category_color"` // Цвет категории
-	Amount        float64 `json:"amount"`         // Сумма расходов в категории
+	Amount        Money   `json:"amount"`         // Сумма расходов в категории
 	Percentage    float64 `json:"percentage"`     // Процент расходов в категории от общей суммы
 }
 
@@ -0,0 +1,33 @@
+package models
+
+import "gorm.io/gorm"
+
+// Tag пользовательская метка для группировки расходов вне иерархии категорий
+type Tag struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;uniqueIndex:idx_tag_user_name" json:"user_id"`      // Идентификатор пользователя владельца тега
+	Name   string `gorm:"not null;size:64;uniqueIndex:idx_tag_user_name" json:"name"` // Название тега
+	Color  string `json:"color"`                                                      // Цвет тега
+
+	// Связи
+	User User `gorm:"foreignKey:UserID" json:"-"` // Пользователь владелец тега
+}
+
+type CreateTagRequest struct {
+	Name  string `json:"name" binding:"required,max=64"` // Название тега
+	Color string `json:"color"`                          // Цвет тега
+}
+
+type UpdateTagRequest struct {
+	Name  *string `json:"name,omitempty" binding:"omitempty,max=64"` // Новое название тега
+	Color *string `json:"color,omitempty"`                           // Новый цвет тега
+}
+
+type TagStatistics struct {
+	TagID       uint    `json:"tag_id"`       // Идентификатор тега
+	TagName     string  `json:"tag_name"`     // Название тега
+	TagColor    string  `json:"tag_color"`    // Цвет тега
+	TotalAmount Money   `json:"total_amount"` // Общая сумма расходов с тегом
+	Count       int     `json:"count"`        // Количество расходов с тегом
+	Percentage  float64 `json:"percentage"`   // Процент от общей суммы всех расходов
+}
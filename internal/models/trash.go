@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// TrashItemType вид записи в корзине
+type TrashItemType string
+
+const (
+	TrashItemExpense          TrashItemType = "expense"           // Расход
+	TrashItemCategory         TrashItemType = "category"          // Категория
+	TrashItemBudget           TrashItemType = "budget"            // Бюджет
+	TrashItemRecurringExpense TrashItemType = "recurring_expense" // Регулярный расход
+)
+
+// TrashItemTypes все виды записей, которые попадают в корзину
+var TrashItemTypes = []TrashItemType{
+	TrashItemExpense,
+	TrashItemCategory,
+	TrashItemBudget,
+	TrashItemRecurringExpense,
+}
+
+// TrashItem мягко удаленная запись пользователя
+type TrashItem struct {
+	Type      TrashItemType `json:"type"`               // Вид записи
+	ID        uint          `json:"id"`                 // Идентификатор записи
+	UserID    uint          `json:"user_id"`            // Идентификатор пользователя владельца записи
+	Title     string        `json:"title"`              // Описание расхода, название категории или месяц бюджета в виде MM.YYYY
+	Amount    *Money        `json:"amount,omitempty"`   // Сумма расхода или бюджета, у категорий отсутствует
+	DeletedAt time.Time     `json:"deleted_at"`         // Время удаления
+	PurgeAt   *time.Time    `json:"purge_at,omitempty"` // Когда запись будет удалена безвозвратно, если не восстановить ее
+}
+
+// TrashPurgeResult результат безвозвратного удаления записей из корзины
+type TrashPurgeResult struct {
+	Purged  int `json:"purged"`  // Удалено записей
+	Skipped int `json:"skipped"` // Пропущено категорий, на которые еще ссылаются другие записи
+}
@@ -18,9 +18,14 @@ type User struct {
 	Username *string `gorm:"uniqueIndex" json:"username,omitempty"`
 	Password *string `json:"-"`
 
+	BaseCurrency string `gorm:"not null;size:3;default:RUB" json:"base_currency"` // Базовая валюта для сумм и статистики
+
 	// Связи
 	Expenses          []Expense          `gorm:"foreignKey:UserID" json:"-"`
+	Incomes           []Income           `gorm:"foreignKey:UserID" json:"-"`
 	Categories        []Category         `gorm:"foreignKey:UserID" json:"-"`
+	Accounts          []Account          `gorm:"foreignKey:UserID" json:"-"`
+	Tags              []Tag              `gorm:"foreignKey:UserID" json:"-"`
 	Budgets           []Budget           `gorm:"foreignKey:UserID" json:"-"`
 	RecurringExpenses []RecurringExpense `gorm:"foreignKey:UserID" json:"-"`
 	ActivityHistory   []ActivityHistory  `gorm:"foreignKey:UserID" json:"-"`
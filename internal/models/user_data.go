@@ -0,0 +1,214 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	UserDataArchiveFormat  = "cashcontrol.user_data" // Признак архива данных пользователя
+	UserDataArchiveVersion = 2                       // Версия формата, меняется при изменениях структуры
+	UserDataArchiveFile    = "data.json"             // Имя файла с данными внутри ZIP-архива
+
+	// DeleteAccountConfirmation фраза, которую нужно передать для удаления учетной записи
+	DeleteAccountConfirmation = "DELETE"
+)
+
+// UserData все записи пользователя, загруженные из БД для выгрузки
+type UserData struct {
+	User              *User
+	Categories        []Category
+	Accounts          []Account
+	Tags              []Tag
+	CategoryRules     []CategoryRule
+	Expenses          []Expense
+	Incomes           []Income
+	Transfers         []Transfer
+	Budgets           []Budget
+	RecurringExpenses []RecurringExpense
+	InstallmentPlans  []InstallmentPlan
+	ActivityHistory   []ActivityHistory
+}
+
+// UserDataArchive переносимый архив данных пользователя.
+// Идентификаторы записей в архиве исходные и используются только для связей между разделами архива.
+// Версия 2 добавила правила категоризации, части расходов, планы рассрочки и дату окончания бюджетов,
+// в архивах версии 1 этих разделов нет.
+type UserDataArchive struct {
+	Format            string                    `json:"format"`             // Всегда UserDataArchiveFormat
+	Version           int                       `json:"version"`            // Версия формата архива
+	ExportedAt        time.Time                 `json:"exported_at"`        // Время выгрузки
+	Profile           ArchiveProfile            `json:"profile"`            // Профиль пользователя
+	Categories        []ArchiveCategory         `json:"categories"`         // Категории расходов и доходов
+	Accounts          []ArchiveAccount          `json:"accounts"`           // Счета
+	Tags              []ArchiveTag              `json:"tags"`               // Теги
+	CategoryRules     []ArchiveCategoryRule     `json:"category_rules"`     // Правила категоризации
+	Expenses          []ArchiveExpense          `json:"expenses"`           // Расходы
+	Incomes           []ArchiveIncome           `json:"incomes"`            // Доходы
+	Transfers         []ArchiveTransfer         `json:"transfers"`          // Переводы между счетами
+	Budgets           []ArchiveBudget           `json:"budgets"`            // Бюджеты с лимитами по категориям
+	RecurringExpenses []ArchiveRecurringExpense `json:"recurring_expenses"` // Регулярные расходы
+	InstallmentPlans  []ArchiveInstallmentPlan  `json:"installment_plans"`  // Планы рассрочки
+	ActivityHistory   []ArchiveActivity         `json:"activity_history"`   // История действий
+}
+
+type ArchiveProfile struct {
+	Email        *string   `json:"email,omitempty"`       // Электронная почта
+	Username     *string   `json:"username,omitempty"`    // Имя пользователя
+	TelegramID   *int64    `json:"telegram_id,omitempty"` // Идентификатор Telegram
+	BaseCurrency string    `json:"base_currency"`         // Базовая валюта
+	CreatedAt    time.Time `json:"created_at"`            // Дата регистрации
+}
+
+type ArchiveCategory struct {
+	ID        uint         `json:"id"`                  // Идентификатор в архиве
+	ParentID  *uint        `json:"parent_id,omitempty"` // Родительская категория
+	Name      string       `json:"name"`                // Название
+	Color     string       `json:"color"`               // Цвет
+	Icon      string       `json:"icon"`                // Иконка
+	Type      CategoryType `json:"type"`                // Тип категории
+	IsDefault bool         `json:"is_default"`          // Категория по умолчанию
+}
+
+type ArchiveAccount struct {
+	ID             uint        `json:"id"`              // Идентификатор в архиве
+	Name           string      `json:"name"`            // Название
+	Type           AccountType `json:"type"`            // Тип счета
+	OpeningBalance Money       `json:"opening_balance"` // Начальный остаток
+}
+
+type ArchiveTag struct {
+	ID    uint   `json:"id"`    // Идентификатор в архиве
+	Name  string `json:"name"`  // Название
+	Color string `json:"color"` // Цвет
+}
+
+type ArchiveCategoryRule struct {
+	ID                  uint    `json:"id"`                             // Идентификатор в архиве
+	Name                string  `json:"name"`                           // Название
+	Priority            int     `json:"priority"`                       // Порядок проверки
+	IsActive            bool    `json:"is_active"`                      // Правило применяется
+	DescriptionContains string  `json:"description_contains,omitempty"` // Подстрока описания
+	DescriptionRegex    string  `json:"description_regex,omitempty"`    // Регулярное выражение для описания
+	MinAmount           *Money  `json:"min_amount,omitempty"`           // Минимальная сумма
+	MaxAmount           *Money  `json:"max_amount,omitempty"`           // Максимальная сумма
+	Weekdays            []int   `json:"weekdays,omitempty"`             // Дни недели
+	CategoryID          uint    `json:"category_id"`                    // Назначаемая категория
+	SetDescription      *string `json:"set_description,omitempty"`      // Новое описание расхода
+}
+
+type ArchiveExpense struct {
+	ID             uint      `json:"id"`                    // Идентификатор в архиве
+	AccountID      *uint     `json:"account_id,omitempty"`  // Счет списания
+	CategoryID     uint      `json:"category_id"`           // Категория
+	Amount         Money     `json:"amount"`                // Сумма в базовой валюте
+	Currency       string    `json:"currency"`              // Валюта расхода
+	OriginalAmount Money     `json:"original_amount"`       // Сумма в валюте расхода
+	ExchangeRate   float64   `json:"exchange_rate"`         // Курс пересчета
+	Description    string    `json:"description"`           // Описание
+	Date           time.Time `json:"date"`                  // Дата расхода
+	ExternalID     *string   `json:"external_id,omitempty"` // Идентификатор операции банковской выписки
+	TagIDs         []uint    `json:"tag_ids,omitempty"`     // Теги
+
+	InstallmentPlanID *uint `json:"installment_plan_id,omitempty"` // План рассрочки, платежом по которому является расход
+
+	Splits []ArchiveExpenseSplit `json:"splits,omitempty"` // Части разделенного расхода
+}
+
+type ArchiveExpenseSplit struct {
+	CategoryID     uint   `json:"category_id"`     // Категория части
+	Amount         Money  `json:"amount"`          // Сумма в базовой валюте
+	OriginalAmount Money  `json:"original_amount"` // Сумма в валюте расхода
+	Description    string `json:"description"`     // Описание
+}
+
+type ArchiveIncome struct {
+	ID          uint      `json:"id"`                   // Идентификатор в архиве
+	AccountID   *uint     `json:"account_id,omitempty"` // Счет зачисления
+	CategoryID  uint      `json:"category_id"`          // Категория
+	Amount      Money     `json:"amount"`               // Сумма
+	Description string    `json:"description"`          // Описание
+	Date        time.Time `json:"date"`                 // Дата поступления
+}
+
+type ArchiveTransfer struct {
+	ID            uint      `json:"id"`              // Идентификатор в архиве
+	FromAccountID uint      `json:"from_account_id"` // Счет списания
+	ToAccountID   uint      `json:"to_account_id"`   // Счет зачисления
+	Amount        Money     `json:"amount"`          // Сумма
+	Description   string    `json:"description"`     // Описание
+	Date          time.Time `json:"date"`            // Дата перевода
+}
+
+type ArchiveBudget struct {
+	ID         uint                    `json:"id"`                    // Идентификатор в архиве
+	Amount     Money                   `json:"amount"`                // Сумма бюджета за период
+	Month      int                     `json:"month"`                 // Месяц
+	Year       int                     `json:"year"`                  // Год
+	Rollover   bool                    `json:"rollover"`              // Перенос остатка
+	PeriodType BudgetPeriodType        `json:"period_type"`           // Тип периода
+	AnchorDate *time.Time              `json:"anchor_date,omitempty"` // Начало первого периода
+	EndDate    *time.Time              `json:"end_date,omitempty"`    // Последний день действия
+	Categories []ArchiveBudgetCategory `json:"categories,omitempty"`  // Лимиты по категориям
+}
+
+type ArchiveBudgetCategory struct {
+	CategoryID uint  `json:"category_id"` // Категория
+	Amount     Money `json:"amount"`      // Лимит
+}
+
+type ArchiveRecurringExpense struct {
+	ID          uint                 `json:"id"`                     // Идентификатор в архиве
+	CategoryID  uint                 `json:"category_id"`            // Категория
+	Amount      Money                `json:"amount"`                 // Сумма
+	Description string               `json:"description"`            // Описание
+	Type        RecurringExpenseType `json:"type"`                   // Тип повторения
+	DayOfMonth  *int                 `json:"day_of_month,omitempty"` // День месяца
+	DayOfWeek   *int                 `json:"day_of_week,omitempty"`  // День недели
+	IsActive    bool                 `json:"is_active"`              // Активен ли регулярный расход
+	NextDate    time.Time            `json:"next_date"`              // Следующая дата создания расхода
+	TagIDs      []uint               `json:"tag_ids,omitempty"`      // Теги
+}
+
+type ArchiveInstallmentPlan struct {
+	ID           uint       `json:"id"`                   // Идентификатор в архиве
+	CategoryID   uint       `json:"category_id"`          // Категория платежей
+	AccountID    *uint      `json:"account_id,omitempty"` // Счет списания
+	Description  string     `json:"description"`          // Описание покупки
+	TotalAmount  Money      `json:"total_amount"`         // Полная сумма к оплате
+	Payments     int        `json:"payments"`             // Количество платежей
+	StartDate    time.Time  `json:"start_date"`           // Дата первого платежа
+	PaymentsMade int        `json:"payments_made"`        // Создано платежей
+	NextDate     *time.Time `json:"next_date,omitempty"`  // Дата следующего платежа
+}
+
+type ArchiveActivity struct {
+	ActivityType ActivityType    `json:"activity_type"`      // Тип действия
+	EntityType   string          `json:"entity_type"`        // Тип сущности
+	EntityID     uint            `json:"entity_id"`          // Идентификатор сущности на момент действия
+	Description  string          `json:"description"`        // Описание действия
+	Metadata     json.RawMessage `json:"metadata,omitempty"` // Дополнительные данные
+	CreatedAt    time.Time       `json:"created_at"`         // Время действия
+}
+
+// DeleteAccountRequest подтверждение безвозвратного удаления учетной записи
+type DeleteAccountRequest struct {
+	Confirm string `json:"confirm" binding:"required"` // Должно совпадать с DeleteAccountConfirmation
+}
+
+// UserDataImportResult результат восстановления данных из архива
+type UserDataImportResult struct {
+	Categories        int      `json:"categories"`         // Создано категорий
+	Accounts          int      `json:"accounts"`           // Создано счетов
+	Tags              int      `json:"tags"`               // Создано тегов
+	CategoryRules     int      `json:"category_rules"`     // Создано правил категоризации
+	Matched           int      `json:"matched"`            // Категории, счета и теги архива, сопоставленные с существующими по названию
+	Expenses          int      `json:"expenses"`           // Создано расходов
+	Incomes           int      `json:"incomes"`            // Создано доходов
+	Transfers         int      `json:"transfers"`          // Создано переводов
+	Budgets           int      `json:"budgets"`            // Создано бюджетов
+	RecurringExpenses int      `json:"recurring_expenses"` // Создано регулярных расходов
+	InstallmentPlans  int      `json:"installment_plans"`  // Создано планов рассрочки
+	Duplicates        int      `json:"duplicates"`         // Пропущено записей, которые у пользователя уже есть
+	Skipped           []string `json:"skipped"`            // Пропущенные записи с причиной
+}
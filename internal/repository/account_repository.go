@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var errAccountNil error = errors.New("account is nil")
+
+type AccountRepository interface {
+	GetByID(id uint) (*models.Account, error)
+	GetByUserID(userID uint) ([]models.Account, error)
+	Create(account *models.Account) error
+	Update(account *models.Account) error
+	Delete(id uint) error
+	// GetNetMovement возвращает изменение остатка счета по доходам, расходам и переводам
+	GetNetMovement(accountID uint) (models.Money, error)
+	// CountTransactions возвращает количество расходов, доходов, переводов и планов рассрочки счета
+	CountTransactions(id uint) (int64, error)
+	WithTx(tx TxProvider) AccountRepository
+}
+
+type gormAccountRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewAccountRepository(db *gorm.DB, logger *slog.Logger) AccountRepository {
+	return &gormAccountRepository{db: db, logger: logger}
+}
+
+func (r *gormAccountRepository) WithTx(tx TxProvider) AccountRepository {
+	return &gormAccountRepository{db: tx.DB(), logger: r.logger}
+}
+
+func (r *gormAccountRepository) GetByID(id uint) (*models.Account, error) {
+	r.logger.Debug("repo.account.get_by_id",
+		slog.String("op", "repo.account.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var account models.Account
+	if err := r.db.First(&account, id).Error; err != nil {
+		r.logger.Error("repo.account.get_by_id failed",
+			slog.String("op", "repo.account.get_by_id"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *gormAccountRepository) GetByUserID(userID uint) ([]models.Account, error) {
+	r.logger.Debug("repo.account.get_by_user_id",
+		slog.String("op", "repo.account.get_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var accounts []models.Account
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&accounts).Error; err != nil {
+		r.logger.Error("repo.account.get_by_user_id failed",
+			slog.String("op", "repo.account.get_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *gormAccountRepository) Create(account *models.Account) error {
+	if account == nil {
+		return errAccountNil
+	}
+
+	r.logger.Debug("repo.account.create",
+		slog.String("op", "repo.account.create"),
+		slog.Uint64("user_id", uint64(account.UserID)),
+		slog.String("name", account.Name),
+	)
+
+	if err := r.db.Create(account).Error; err != nil {
+		r.logger.Error("repo.account.create failed",
+			slog.String("op", "repo.account.create"),
+			slog.Uint64("user_id", uint64(account.UserID)),
+			slog.String("name", account.Name),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormAccountRepository) Update(account *models.Account) error {
+	if account == nil {
+		return errAccountNil
+	}
+	r.logger.Debug("repo.account.update",
+		slog.String("op", "repo.account.update"),
+		slog.Uint64("id", uint64(account.ID)),
+	)
+
+	if err := r.db.Save(account).Error; err != nil {
+		r.logger.Error("repo.account.update failed",
+			slog.String("op", "repo.account.update"),
+			slog.Uint64("id", uint64(account.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormAccountRepository) Delete(id uint) error {
+	r.logger.Debug("repo.account.delete",
+		slog.String("op", "repo.account.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Delete(&models.Account{}, id).Error; err != nil {
+		r.logger.Error("repo.account.delete failed",
+			slog.String("op", "repo.account.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormAccountRepository) GetNetMovement(accountID uint) (models.Money, error) {
+	r.logger.Debug("repo.account.get_net_movement",
+		slog.String("op", "repo.account.get_net_movement"),
+		slog.Uint64("id", uint64(accountID)),
+	)
+
+	var movement struct {
+		Amount models.Money
+	}
+	query := `
+		SELECT (
+			(SELECT COALESCE(SUM(amount), 0) FROM incomes WHERE account_id = @id AND deleted_at IS NULL)
+			- (SELECT COALESCE(SUM(amount), 0) FROM expenses WHERE account_id = @id AND deleted_at IS NULL)
+			+ (SELECT COALESCE(SUM(amount), 0) FROM transfers WHERE to_account_id = @id AND deleted_at IS NULL)
+			- (SELECT COALESCE(SUM(amount), 0) FROM transfers WHERE from_account_id = @id AND deleted_at IS NULL)
+		) AS amount
+	`
+	if err := r.db.Raw(query, map[string]interface{}{"id": accountID}).Scan(&movement).Error; err != nil {
+		r.logger.Error("repo.account.get_net_movement failed",
+			slog.String("op", "repo.account.get_net_movement"),
+			slog.Uint64("id", uint64(accountID)),
+			slog.String("error", err.Error()),
+		)
+		return 0, err
+	}
+	return movement.Amount, nil
+}
+
+func (r *gormAccountRepository) CountTransactions(id uint) (int64, error) {
+	r.logger.Debug("repo.account.count_transactions",
+		slog.String("op", "repo.account.count_transactions"),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	var usage struct {
+		Count int64
+	}
+	query := `
+		SELECT (
+			(SELECT COUNT(*) FROM expenses WHERE account_id = @id AND deleted_at IS NULL)
+			+ (SELECT COUNT(*) FROM incomes WHERE account_id = @id AND deleted_at IS NULL)
+			+ (SELECT COUNT(*) FROM transfers WHERE (from_account_id = @id OR to_account_id = @id) AND deleted_at IS NULL)
+			+ (SELECT COUNT(*) FROM installment_plans WHERE account_id = @id AND deleted_at IS NULL)
+		) AS count
+	`
+	if err := r.db.Raw(query, map[string]interface{}{"id": id}).Scan(&usage).Error; err != nil {
+		r.logger.Error("repo.account.count_transactions failed",
+			slog.String("op", "repo.account.count_transactions"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return 0, err
+	}
+	return usage.Count, nil
+}
@@ -11,8 +11,10 @@ import (
 var errActivityLogNil = errors.New("activity log is nil")
 
 type ActivityLogRepository interface {
-	Get(filter models.ActivityFilter) ([]models.ActivityHistory, error)
+	// Get возвращает страницу записей по фильтру с пагинацией по курсору
+	Get(filter models.ActivityFilter, page models.PageRequest) (*models.Page[models.ActivityHistory], error)
 	Create(logEntry *models.ActivityHistory) error
+	WithTx(tx TxProvider) ActivityLogRepository
 }
 
 type activityLogRepository struct {
@@ -24,6 +26,10 @@ func NewActivityLogRepository(db *gorm.DB, logger *slog.Logger) ActivityLogRepos
 	return &activityLogRepository{db: db, logger: logger}
 }
 
+func (r *activityLogRepository) WithTx(tx TxProvider) ActivityLogRepository {
+	return &activityLogRepository{db: tx.DB(), logger: r.logger}
+}
+
 // Create сохраняет запись об активности
 func (r *activityLogRepository) Create(logEntry *models.ActivityHistory) error {
 	const op = "repo.activity_log.create"
@@ -67,8 +73,8 @@ func (r *activityLogRepository) Create(logEntry *models.ActivityHistory) error {
 	return nil
 }
 
-// Get возвращает список записей по фильтру
-func (r *activityLogRepository) Get(filter models.ActivityFilter) ([]models.ActivityHistory, error) {
+// Get возвращает страницу записей по фильтру
+func (r *activityLogRepository) Get(filter models.ActivityFilter, page models.PageRequest) (*models.Page[models.ActivityHistory], error) {
 	const op = "repo.activity_log.get"
 
 	r.logger.Debug("retrieving activity logs",
@@ -100,7 +106,6 @@ func (r *activityLogRepository) Get(filter models.ActivityFilter) ([]models.Acti
 		}()),
 	)
 
-	var logs []models.ActivityHistory
 	query := r.db.Model(&models.ActivityHistory{}).Where("user_id = ?", filter.UserID)
 
 	if filter.ActivityType != nil {
@@ -116,14 +121,19 @@ func (r *activityLogRepository) Get(filter models.ActivityFilter) ([]models.Acti
 		query = query.Where("created_at <= ?", *filter.EndDate)
 	}
 
-	if filter.Limit != nil {
-		query = query.Limit(*filter.Limit)
-	}
-	if filter.Offset != nil {
-		query = query.Offset(*filter.Offset)
-	}
-
-	if err := query.Order("created_at DESC").Find(&logs).Error; err != nil {
+	logs, err := pageQuery[models.ActivityHistory]{
+		query:    query,
+		idColumn: "activity_histories.id",
+		keys: map[string]pageKey[models.ActivityHistory]{
+			models.SortByCreatedAt: {
+				expr:   "activity_histories.created_at",
+				value:  func(l *models.ActivityHistory) interface{} { return l.CreatedAt },
+				decode: decodeTimeCursor,
+			},
+		},
+		id: func(l *models.ActivityHistory) uint { return l.ID },
+	}.fetch(page)
+	if err != nil {
 		r.logger.Error("failed to retrieve activity logs",
 			slog.String("op", op),
 			slog.Uint64("user_id", uint64(filter.UserID)),
@@ -134,7 +144,7 @@ func (r *activityLogRepository) Get(filter models.ActivityFilter) ([]models.Acti
 
 	r.logger.Debug("retrieved activity logs",
 		slog.String("op", op),
-		slog.Int("count", len(logs)),
+		slog.Int("count", len(logs.Items)),
 	)
 
 	return logs, nil
@@ -44,17 +44,33 @@ func (r *gormAnalyticsRepository) GetAnalytics(
 
 	var result []models.AnalyticsPoint
 
+	// Расходы и доходы группируются по одному интервалу, чтобы получить чистый поток.
+	// Динамика не делится по категориям, поэтому расход учитывается целиком: части разделенного
+	// расхода в сумме равны ему, а в количестве он считается один раз.
 	err := r.db.Raw(`
 		SELECT
-			date_trunc(?, date) AS date,
-			SUM(amount) AS total,
-			COUNT(*) AS count
-		FROM expenses
-		WHERE user_id = ?
-		  AND date BETWEEN ? AND ?
-		GROUP BY date
-		ORDER BY date
-	`, trunc, userID, start, end).Scan(&result).Error
+			t.bucket AS date,
+			SUM(t.expense) AS total,
+			SUM(t.expense_count) AS count,
+			SUM(t.expense) AS expense,
+			SUM(t.income) AS income,
+			SUM(t.income) - SUM(t.expense) AS net
+		FROM (
+			SELECT date_trunc(?, date) AS bucket, amount AS expense, 1 AS expense_count, 0 AS income
+			FROM expenses
+			WHERE user_id = ?
+			  AND date BETWEEN ? AND ?
+			  AND deleted_at IS NULL
+			UNION ALL
+			SELECT date_trunc(?, date) AS bucket, 0 AS expense, 0 AS expense_count, amount AS income
+			FROM incomes
+			WHERE user_id = ?
+			  AND date BETWEEN ? AND ?
+			  AND deleted_at IS NULL
+		) t
+		GROUP BY t.bucket
+		ORDER BY t.bucket
+	`, trunc, userID, start, end, trunc, userID, start, end).Scan(&result).Error
 
 	return result, err
 }
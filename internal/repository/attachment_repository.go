@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var errAttachmentNil error = errors.New("attachment is nil")
+
+type AttachmentRepository interface {
+	GetByID(id uint) (*models.Attachment, error)
+	// GetByExpenseID возвращает вложения расхода в порядке загрузки
+	GetByExpenseID(expenseID uint) ([]models.Attachment, error)
+	// CountByExpenseID возвращает количество вложений расхода
+	CountByExpenseID(expenseID uint) (int64, error)
+	Create(attachment *models.Attachment) error
+	// Delete удаляет запись окончательно: файл к этому моменту уже удален из хранилища
+	Delete(id uint) error
+	// MoveToExpense переносит вложения указанных расходов к другому расходу
+	MoveToExpense(expenseIDs []uint, targetID uint) error
+	WithTx(tx TxProvider) AttachmentRepository
+}
+
+type gormAttachmentRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewAttachmentRepository(db *gorm.DB, logger *slog.Logger) AttachmentRepository {
+	return &gormAttachmentRepository{db: db, logger: logger}
+}
+
+func (r *gormAttachmentRepository) WithTx(tx TxProvider) AttachmentRepository {
+	return &gormAttachmentRepository{db: tx.DB(), logger: r.logger}
+}
+
+func (r *gormAttachmentRepository) GetByID(id uint) (*models.Attachment, error) {
+	r.logger.Debug("repo.attachment.get_by_id",
+		slog.String("op", "repo.attachment.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var attachment models.Attachment
+	if err := r.db.First(&attachment, id).Error; err != nil {
+		r.logger.Error("repo.attachment.get_by_id failed",
+			slog.String("op", "repo.attachment.get_by_id"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *gormAttachmentRepository) GetByExpenseID(expenseID uint) ([]models.Attachment, error) {
+	r.logger.Debug("repo.attachment.get_by_expense_id",
+		slog.String("op", "repo.attachment.get_by_expense_id"),
+		slog.Uint64("expense_id", uint64(expenseID)),
+	)
+	var attachments []models.Attachment
+	if err := r.db.Where("expense_id = ?", expenseID).Order("id").Find(&attachments).Error; err != nil {
+		r.logger.Error("repo.attachment.get_by_expense_id failed",
+			slog.String("op", "repo.attachment.get_by_expense_id"),
+			slog.Uint64("expense_id", uint64(expenseID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *gormAttachmentRepository) CountByExpenseID(expenseID uint) (int64, error) {
+	r.logger.Debug("repo.attachment.count_by_expense_id",
+		slog.String("op", "repo.attachment.count_by_expense_id"),
+		slog.Uint64("expense_id", uint64(expenseID)),
+	)
+	var count int64
+	if err := r.db.Model(&models.Attachment{}).Where("expense_id = ?", expenseID).Count(&count).Error; err != nil {
+		r.logger.Error("repo.attachment.count_by_expense_id failed",
+			slog.String("op", "repo.attachment.count_by_expense_id"),
+			slog.Uint64("expense_id", uint64(expenseID)),
+			slog.String("error", err.Error()),
+		)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *gormAttachmentRepository) Create(attachment *models.Attachment) error {
+	if attachment == nil {
+		return errAttachmentNil
+	}
+	r.logger.Debug("repo.attachment.create",
+		slog.String("op", "repo.attachment.create"),
+		slog.Uint64("expense_id", uint64(attachment.ExpenseID)),
+	)
+	if err := r.db.Create(attachment).Error; err != nil {
+		r.logger.Error("repo.attachment.create failed",
+			slog.String("op", "repo.attachment.create"),
+			slog.Uint64("expense_id", uint64(attachment.ExpenseID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormAttachmentRepository) Delete(id uint) error {
+	r.logger.Debug("repo.attachment.delete",
+		slog.String("op", "repo.attachment.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Unscoped().Delete(&models.Attachment{}, id).Error; err != nil {
+		r.logger.Error("repo.attachment.delete failed",
+			slog.String("op", "repo.attachment.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormAttachmentRepository) MoveToExpense(expenseIDs []uint, targetID uint) error {
+	r.logger.Debug("repo.attachment.move_to_expense",
+		slog.String("op", "repo.attachment.move_to_expense"),
+		slog.Uint64("target_id", uint64(targetID)),
+		slog.Int("expenses", len(expenseIDs)),
+	)
+	if len(expenseIDs) == 0 {
+		return nil
+	}
+	err := r.db.Model(&models.Attachment{}).
+		Where("expense_id IN ?", expenseIDs).
+		Update("expense_id", targetID).Error
+	if err != nil {
+		r.logger.Error("repo.attachment.move_to_expense failed",
+			slog.String("op", "repo.attachment.move_to_expense"),
+			slog.Uint64("target_id", uint64(targetID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
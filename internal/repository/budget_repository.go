@@ -4,20 +4,28 @@ import (
 	"cashcontrol/internal/models"
 	"errors"
 	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 var errBudgetNil error = errors.New("budget is nil")
+var errBudgetCategoryNil error = errors.New("budget category is nil")
 
 type BudgetRepository interface {
 	List() ([]models.Budget, error)
 	GetByID(id uint) (*models.Budget, error)
 	GetByUserIDAndMonth(userID uint, month, year int) (*models.Budget, error)
+	GetPeriodicByUserIDAndDate(userID uint, date time.Time) (*models.Budget, error)
 	GetByUserID(userID uint) ([]models.Budget, error)
+	// ListPage возвращает страницу бюджетов пользователя с пагинацией по курсору
+	ListPage(userID uint, page models.PageRequest) (*models.Page[models.Budget], error)
 	Create(budget *models.Budget) error
 	Update(budget *models.Budget) error
 	Delete(id uint) error
+	GetBudgetCategory(budgetID, categoryID uint) (*models.BudgetCategory, error)
+	SaveBudgetCategory(budgetCategory *models.BudgetCategory) error
+	DeleteBudgetCategory(id uint) error
 }
 
 type gormBudgetRepository struct {
@@ -50,7 +58,7 @@ func (r *gormBudgetRepository) GetByID(id uint) (*models.Budget, error) {
 		slog.Uint64("id", uint64(id)),
 	)
 	var budget models.Budget
-	if err := r.db.First(&budget, id).Error; err != nil {
+	if err := r.db.Preload("Categories.Category").First(&budget, id).Error; err != nil {
 		r.logger.Error("repo.budget.get_by_id failed",
 			slog.String("op", "repo.budget.get_by_id"),
 			slog.Uint64("id", uint64(id)),
@@ -69,7 +77,9 @@ func (r *gormBudgetRepository) GetByUserIDAndMonth(userID uint, month, year int)
 		slog.Int("year", year),
 	)
 	var budget models.Budget
-	if err := r.db.Where("user_id = ? AND month = ? AND year = ?", userID, month, year).First(&budget).Error; err != nil {
+	if err := r.db.Preload("Categories.Category").
+		Where("user_id = ? AND month = ? AND year = ? AND period_type = ?", userID, month, year, models.BudgetPeriodMonthly).
+		First(&budget).Error; err != nil {
 		r.logger.Error("repo.budget.get_by_user_id_and_month failed",
 			slog.String("op", "repo.budget.get_by_user_id_and_month"),
 			slog.Uint64("user_id", uint64(userID)),
@@ -82,6 +92,34 @@ func (r *gormBudgetRepository) GetByUserIDAndMonth(userID uint, month, year int)
 	return &budget, nil
 }
 
+// GetPeriodicByUserIDAndDate возвращает немесячный бюджет, действующий на указанную дату:
+// дата привязки не позже даты, а дата окончания не задана или не раньше дня даты
+func (r *gormBudgetRepository) GetPeriodicByUserIDAndDate(userID uint, date time.Time) (*models.Budget, error) {
+	r.logger.Debug("repo.budget.get_periodic_by_user_id_and_date",
+		slog.String("op", "repo.budget.get_periodic_by_user_id_and_date"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Time("date", date),
+	)
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	var budget models.Budget
+	if err := r.db.Preload("Categories.Category").
+		Where("user_id = ? AND period_type <> ? AND anchor_date <= ?", userID, models.BudgetPeriodMonthly, date).
+		Where("end_date IS NULL OR end_date >= ?", day).
+		Order("anchor_date DESC").
+		First(&budget).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.budget.get_periodic_by_user_id_and_date failed",
+				slog.String("op", "repo.budget.get_periodic_by_user_id_and_date"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Time("date", date),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &budget, nil
+}
+
 func (r *gormBudgetRepository) GetByUserID(userID uint) ([]models.Budget, error) {
 	r.logger.Debug("repo.budget.get_by_user_id",
 		slog.String("op", "repo.budget.get_by_user_id"),
@@ -99,6 +137,49 @@ func (r *gormBudgetRepository) GetByUserID(userID uint) ([]models.Budget, error)
 	return budgets, nil
 }
 
+func (r *gormBudgetRepository) ListPage(userID uint, page models.PageRequest) (*models.Page[models.Budget], error) {
+	r.logger.Debug("repo.budget.list_page",
+		slog.String("op", "repo.budget.list_page"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("sort", page.Sort),
+	)
+	budgets, err := pageQuery[models.Budget]{
+		query:    r.db.Model(&models.Budget{}).Where("user_id = ?", userID),
+		idColumn: "budgets.id",
+		keys: map[string]pageKey[models.Budget]{
+			// Месяц бюджета числом вида 202605: сравнение не зависит от часового пояса сессии, как было бы с датой
+			models.SortByDate: {
+				expr:   "budgets.year * 100 + budgets.month",
+				value:  func(b *models.Budget) interface{} { return b.Year*100 + b.Month },
+				decode: decodeIntCursor,
+			},
+			models.SortByAmount: {
+				expr:   "budgets.amount",
+				value:  func(b *models.Budget) interface{} { return b.Amount },
+				decode: decodeMoneyCursor,
+			},
+			models.SortByCreatedAt: {
+				expr:   "budgets.created_at",
+				value:  func(b *models.Budget) interface{} { return b.CreatedAt },
+				decode: decodeTimeCursor,
+			},
+		},
+		id: func(b *models.Budget) uint { return b.ID },
+		load: func(query *gorm.DB) *gorm.DB {
+			return query.Preload("Categories.Category")
+		},
+	}.fetch(page)
+	if err != nil {
+		r.logger.Error("repo.budget.list_page failed",
+			slog.String("op", "repo.budget.list_page"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return budgets, nil
+}
+
 func (r *gormBudgetRepository) Create(budget *models.Budget) error {
 	if budget == nil {
 		return errBudgetNil
@@ -107,7 +188,7 @@ func (r *gormBudgetRepository) Create(budget *models.Budget) error {
 	r.logger.Debug("repo.budget.create",
 		slog.String("op", "repo.budget.create"),
 		slog.Uint64("user_id", uint64(budget.UserID)),
-		slog.Float64("amount", budget.Amount),
+		slog.String("amount", budget.Amount.String()),
 		slog.Int("month", budget.Month),
 		slog.Int("year", budget.Year),
 	)
@@ -116,7 +197,7 @@ func (r *gormBudgetRepository) Create(budget *models.Budget) error {
 		r.logger.Error("repo.budget.create failed",
 			slog.String("op", "repo.budget.create"),
 			slog.Uint64("user_id", uint64(budget.UserID)),
-			slog.Float64("amount", budget.Amount),
+			slog.String("amount", budget.Amount.String()),
 			slog.Int("month", budget.Month),
 			slog.Int("year", budget.Year),
 			slog.String("error", err.Error()),
@@ -135,7 +216,7 @@ func (r *gormBudgetRepository) Update(budget *models.Budget) error {
 		slog.Uint64("id", uint64(budget.ID)),
 	)
 
-	if err := r.db.Save(budget).Error; err != nil {
+	if err := r.db.Omit("Categories").Save(budget).Error; err != nil {
 		r.logger.Error("repo.budget.update failed",
 			slog.String("op", "repo.budget.update"),
 			slog.Uint64("id", uint64(budget.ID)),
@@ -161,3 +242,61 @@ func (r *gormBudgetRepository) Delete(id uint) error {
 	}
 	return nil
 }
+
+func (r *gormBudgetRepository) GetBudgetCategory(budgetID, categoryID uint) (*models.BudgetCategory, error) {
+	r.logger.Debug("repo.budget.get_budget_category",
+		slog.String("op", "repo.budget.get_budget_category"),
+		slog.Uint64("budget_id", uint64(budgetID)),
+		slog.Uint64("category_id", uint64(categoryID)),
+	)
+	var budgetCategory models.BudgetCategory
+	if err := r.db.Where("budget_id = ? AND category_id = ?", budgetID, categoryID).First(&budgetCategory).Error; err != nil {
+		r.logger.Error("repo.budget.get_budget_category failed",
+			slog.String("op", "repo.budget.get_budget_category"),
+			slog.Uint64("budget_id", uint64(budgetID)),
+			slog.Uint64("category_id", uint64(categoryID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &budgetCategory, nil
+}
+
+func (r *gormBudgetRepository) SaveBudgetCategory(budgetCategory *models.BudgetCategory) error {
+	if budgetCategory == nil {
+		return errBudgetCategoryNil
+	}
+	r.logger.Debug("repo.budget.save_budget_category",
+		slog.String("op", "repo.budget.save_budget_category"),
+		slog.Uint64("budget_id", uint64(budgetCategory.BudgetID)),
+		slog.Uint64("category_id", uint64(budgetCategory.CategoryID)),
+		slog.String("amount", budgetCategory.Amount.String()),
+	)
+
+	if err := r.db.Omit("Category").Save(budgetCategory).Error; err != nil {
+		r.logger.Error("repo.budget.save_budget_category failed",
+			slog.String("op", "repo.budget.save_budget_category"),
+			slog.Uint64("budget_id", uint64(budgetCategory.BudgetID)),
+			slog.Uint64("category_id", uint64(budgetCategory.CategoryID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormBudgetRepository) DeleteBudgetCategory(id uint) error {
+	r.logger.Debug("repo.budget.delete_budget_category",
+		slog.String("op", "repo.budget.delete_budget_category"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Delete(&models.BudgetCategory{}, id).Error; err != nil {
+		r.logger.Error("repo.budget.delete_budget_category failed",
+			slog.String("op", "repo.budget.delete_budget_category"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
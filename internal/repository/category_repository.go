@@ -10,6 +10,18 @@ import (
 
 var errCategoryNil error = errors.New("category is nil")
 
+// categorySubtreeSQL выбирает идентификаторы категории и всех ее подкатегорий
+const categorySubtreeSQL = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM categories WHERE id = ? AND deleted_at IS NULL
+		UNION ALL
+		SELECT c.id FROM categories c
+		INNER JOIN subtree s ON c.parent_id = s.id
+		WHERE c.deleted_at IS NULL
+	)
+	SELECT id FROM subtree
+`
+
 type CategoryRepository interface {
 	List() ([]models.Category, error)
 	GetByID(id uint) (*models.Category, error)
@@ -17,6 +29,17 @@ type CategoryRepository interface {
 	Create(category *models.Category) error
 	Update(category *models.Category) error
 	Delete(id uint) error
+	// GetSubtreeIDs возвращает идентификаторы категории и всех ее подкатегорий
+	GetSubtreeIDs(id uint) ([]uint, error)
+	// ReparentChildren переносит прямых потомков категории под нового родителя (nil делает их корневыми)
+	ReparentChildren(parentID uint, newParentID *uint) error
+	// CountTransactions возвращает количество расходов, доходов и регулярных расходов в категории
+	CountTransactions(id uint) (int64, error)
+	// ReassignTransactions переносит расходы, доходы, регулярные расходы, правила категоризации и лимиты бюджетов
+	// из категории fromID в toID. Лимиты одного бюджета по обеим категориям суммируются.
+	// Записи в корзине тоже переносятся, чтобы их можно было восстановить без удаленной категории.
+	ReassignTransactions(fromID, toID uint) (*models.CategoryMergeResult, error)
+	WithTx(tx TxProvider) CategoryRepository
 }
 
 type gormCategoryRepository struct {
@@ -28,6 +51,10 @@ func NewCategoryRepository(db *gorm.DB, logger *slog.Logger) CategoryRepository
 	return &gormCategoryRepository{db: db, logger: logger}
 }
 
+func (r *gormCategoryRepository) WithTx(tx TxProvider) CategoryRepository {
+	return &gormCategoryRepository{db: tx.DB(), logger: r.logger}
+}
+
 func (r *gormCategoryRepository) List() ([]models.Category, error) {
 	r.logger.Debug("repo.category.list",
 		slog.String("op", "repo.category.list"),
@@ -125,7 +152,14 @@ func (r *gormCategoryRepository) Delete(id uint) error {
 		slog.String("op", "repo.category.delete"),
 		slog.Uint64("id", uint64(id)),
 	)
-	if err := r.db.Delete(&models.Category{}, id).Error; err != nil {
+	// Правила категоризации без категории теряют смысл и удаляются вместе с ней. Отметка времени
+	// удаления у них общая с категорией, по ней правила восстанавливаются из корзины вместе с категорией.
+	deletedAt := r.db.NowFunc()
+	err := r.db.Model(&models.CategoryRule{}).Where("category_id = ?", id).Update("deleted_at", deletedAt).Error
+	if err == nil {
+		err = r.db.Model(&models.Category{}).Where("id = ?", id).Update("deleted_at", deletedAt).Error
+	}
+	if err != nil {
 		r.logger.Error("repo.category.delete failed",
 			slog.String("op", "repo.category.delete"),
 			slog.Uint64("id", uint64(id)),
@@ -135,3 +169,172 @@ func (r *gormCategoryRepository) Delete(id uint) error {
 	}
 	return nil
 }
+
+func (r *gormCategoryRepository) GetSubtreeIDs(id uint) ([]uint, error) {
+	r.logger.Debug("repo.category.get_subtree_ids",
+		slog.String("op", "repo.category.get_subtree_ids"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var ids []uint
+	if err := r.db.Raw(categorySubtreeSQL, id).Scan(&ids).Error; err != nil {
+		r.logger.Error("repo.category.get_subtree_ids failed",
+			slog.String("op", "repo.category.get_subtree_ids"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *gormCategoryRepository) ReparentChildren(parentID uint, newParentID *uint) error {
+	r.logger.Debug("repo.category.reparent_children",
+		slog.String("op", "repo.category.reparent_children"),
+		slog.Uint64("parent_id", uint64(parentID)),
+	)
+	err := r.db.Model(&models.Category{}).
+		Where("parent_id = ?", parentID).
+		Update("parent_id", newParentID).Error
+	if err != nil {
+		r.logger.Error("repo.category.reparent_children failed",
+			slog.String("op", "repo.category.reparent_children"),
+			slog.Uint64("parent_id", uint64(parentID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormCategoryRepository) CountTransactions(id uint) (int64, error) {
+	r.logger.Debug("repo.category.count_transactions",
+		slog.String("op", "repo.category.count_transactions"),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	var usage struct {
+		Count int64
+	}
+	query := `
+		SELECT (
+			(SELECT COUNT(*) FROM expenses WHERE category_id = @id AND deleted_at IS NULL)
+			+ (SELECT COUNT(*) FROM expense_splits s
+				INNER JOIN expenses e ON e.id = s.expense_id
+				WHERE s.category_id = @id AND s.deleted_at IS NULL AND e.deleted_at IS NULL)
+			+ (SELECT COUNT(*) FROM incomes WHERE category_id = @id AND deleted_at IS NULL)
+			+ (SELECT COUNT(*) FROM recurring_expenses WHERE category_id = @id AND deleted_at IS NULL)
+			+ (SELECT COUNT(*) FROM installment_plans WHERE category_id = @id AND deleted_at IS NULL)
+		) AS count
+	`
+	if err := r.db.Raw(query, map[string]interface{}{"id": id}).Scan(&usage).Error; err != nil {
+		r.logger.Error("repo.category.count_transactions failed",
+			slog.String("op", "repo.category.count_transactions"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return 0, err
+	}
+	return usage.Count, nil
+}
+
+func (r *gormCategoryRepository) ReassignTransactions(fromID, toID uint) (*models.CategoryMergeResult, error) {
+	r.logger.Debug("repo.category.reassign_transactions",
+		slog.String("op", "repo.category.reassign_transactions"),
+		slog.Uint64("from_id", uint64(fromID)),
+		slog.Uint64("to_id", uint64(toID)),
+	)
+
+	result := &models.CategoryMergeResult{TargetCategoryID: toID}
+	fail := func(err error) (*models.CategoryMergeResult, error) {
+		r.logger.Error("repo.category.reassign_transactions failed",
+			slog.String("op", "repo.category.reassign_transactions"),
+			slog.Uint64("from_id", uint64(fromID)),
+			slog.Uint64("to_id", uint64(toID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	// Часть расхода, у которого уже есть часть в целевой категории, добавляется к ней:
+	// категории частей одного расхода не повторяются
+	mergedSplits := r.db.Exec(`
+		UPDATE expense_splits AS target
+		SET amount = target.amount + source.amount,
+			original_amount = target.original_amount + source.original_amount,
+			updated_at = NOW()
+		FROM expense_splits AS source
+		WHERE source.expense_id = target.expense_id
+			AND source.category_id = @from AND target.category_id = @to
+			AND source.deleted_at IS NULL AND target.deleted_at IS NULL`,
+		map[string]interface{}{"from": fromID, "to": toID})
+	if mergedSplits.Error != nil {
+		return fail(mergedSplits.Error)
+	}
+	err := r.db.Exec(`
+		DELETE FROM expense_splits AS source
+		WHERE source.category_id = @from AND source.deleted_at IS NULL AND EXISTS (
+			SELECT 1 FROM expense_splits AS target
+			WHERE target.expense_id = source.expense_id AND target.category_id = @to AND target.deleted_at IS NULL
+		)`,
+		map[string]interface{}{"from": fromID, "to": toID}).Error
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, target := range []struct {
+		model   interface{}
+		counter *int64
+	}{
+		{&models.Expense{}, &result.Expenses},
+		{&models.ExpenseSplit{}, &result.Splits},
+		{&models.Income{}, &result.Incomes},
+		{&models.RecurringExpense{}, &result.RecurringExpenses},
+		{&models.InstallmentPlan{}, &result.InstallmentPlans},
+		{&models.CategoryRule{}, &result.Rules},
+	} {
+		// Unscoped: записи в корзине должны ссылаться на целевую категорию
+		tx := r.db.Unscoped().Model(target.model).Where("category_id = ?", fromID).Update("category_id", toID)
+		if tx.Error != nil {
+			return fail(tx.Error)
+		}
+		*target.counter = tx.RowsAffected
+	}
+	result.Splits += mergedSplits.RowsAffected
+
+	var limits []models.BudgetCategory
+	if err := r.db.Unscoped().Where("category_id = ?", fromID).Find(&limits).Error; err != nil {
+		return fail(err)
+	}
+	for _, limit := range limits {
+		// Удаленный лимит только меняет категорию и не добавляется к действующему
+		if limit.DeletedAt.Valid {
+			if err := r.db.Unscoped().Model(&limit).Update("category_id", toID).Error; err != nil {
+				return fail(err)
+			}
+			continue
+		}
+
+		var existing models.BudgetCategory
+		err := r.db.Where("budget_id = ? AND category_id = ?", limit.BudgetID, toID).First(&existing).Error
+		switch {
+		case err == nil:
+			// В бюджете уже есть лимит по целевой категории, объединяем суммы
+			existing.Amount += limit.Amount
+			if err := r.db.Omit("Category").Save(&existing).Error; err != nil {
+				return fail(err)
+			}
+			if err := r.db.Delete(&models.BudgetCategory{}, limit.ID).Error; err != nil {
+				return fail(err)
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := r.db.Model(&limit).Update("category_id", toID).Error; err != nil {
+				return fail(err)
+			}
+		default:
+			return fail(err)
+		}
+		result.BudgetLimits++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var errCategoryRuleNil error = errors.New("category rule is nil")
+
+type CategoryRuleRepository interface {
+	GetByID(id uint) (*models.CategoryRule, error)
+	// GetByUserID возвращает правила пользователя в порядке проверки
+	GetByUserID(userID uint) ([]models.CategoryRule, error)
+	// GetActiveByUserID возвращает включенные правила пользователя в порядке проверки
+	GetActiveByUserID(userID uint) ([]models.CategoryRule, error)
+	Create(rule *models.CategoryRule) error
+	Update(rule *models.CategoryRule) error
+	Delete(id uint) error
+}
+
+type gormCategoryRuleRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewCategoryRuleRepository(db *gorm.DB, logger *slog.Logger) CategoryRuleRepository {
+	return &gormCategoryRuleRepository{db: db, logger: logger}
+}
+
+func (r *gormCategoryRuleRepository) GetByID(id uint) (*models.CategoryRule, error) {
+	r.logger.Debug("repo.category_rule.get_by_id",
+		slog.String("op", "repo.category_rule.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var rule models.CategoryRule
+	if err := r.db.Preload("Category").First(&rule, id).Error; err != nil {
+		r.logger.Error("repo.category_rule.get_by_id failed",
+			slog.String("op", "repo.category_rule.get_by_id"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *gormCategoryRuleRepository) GetByUserID(userID uint) ([]models.CategoryRule, error) {
+	r.logger.Debug("repo.category_rule.get_by_user_id",
+		slog.String("op", "repo.category_rule.get_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var rules []models.CategoryRule
+	if err := r.db.Preload("Category").Where("user_id = ?", userID).Order("priority, id").Find(&rules).Error; err != nil {
+		r.logger.Error("repo.category_rule.get_by_user_id failed",
+			slog.String("op", "repo.category_rule.get_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *gormCategoryRuleRepository) GetActiveByUserID(userID uint) ([]models.CategoryRule, error) {
+	r.logger.Debug("repo.category_rule.get_active_by_user_id",
+		slog.String("op", "repo.category_rule.get_active_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var rules []models.CategoryRule
+	if err := r.db.Where("user_id = ? AND is_active", userID).Order("priority, id").Find(&rules).Error; err != nil {
+		r.logger.Error("repo.category_rule.get_active_by_user_id failed",
+			slog.String("op", "repo.category_rule.get_active_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *gormCategoryRuleRepository) Create(rule *models.CategoryRule) error {
+	if rule == nil {
+		return errCategoryRuleNil
+	}
+	r.logger.Debug("repo.category_rule.create",
+		slog.String("op", "repo.category_rule.create"),
+		slog.Uint64("user_id", uint64(rule.UserID)),
+		slog.String("name", rule.Name),
+	)
+	if err := r.db.Omit("Category").Create(rule).Error; err != nil {
+		r.logger.Error("repo.category_rule.create failed",
+			slog.String("op", "repo.category_rule.create"),
+			slog.Uint64("user_id", uint64(rule.UserID)),
+			slog.String("name", rule.Name),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormCategoryRuleRepository) Update(rule *models.CategoryRule) error {
+	if rule == nil {
+		return errCategoryRuleNil
+	}
+	r.logger.Debug("repo.category_rule.update",
+		slog.String("op", "repo.category_rule.update"),
+		slog.Uint64("id", uint64(rule.ID)),
+	)
+	if err := r.db.Omit("Category").Save(rule).Error; err != nil {
+		r.logger.Error("repo.category_rule.update failed",
+			slog.String("op", "repo.category_rule.update"),
+			slog.Uint64("id", uint64(rule.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormCategoryRuleRepository) Delete(id uint) error {
+	r.logger.Debug("repo.category_rule.delete",
+		slog.String("op", "repo.category_rule.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Delete(&models.CategoryRule{}, id).Error; err != nil {
+		r.logger.Error("repo.category_rule.delete failed",
+			slog.String("op", "repo.category_rule.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
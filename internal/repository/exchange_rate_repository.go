@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errExchangeRateNil error = errors.New("exchange rate is nil")
+
+type ExchangeRateRepository interface {
+	List(userID uint, fromCurrency, toCurrency string) ([]models.ExchangeRate, error)
+	GetByID(id uint) (*models.ExchangeRate, error)
+	// FindLatest возвращает последний курс пары пользователя, действующий на дату
+	FindLatest(userID uint, fromCurrency, toCurrency string, date time.Time) (*models.ExchangeRate, error)
+	// Upsert создает курс или обновляет существующий курс пользователя той же пары на ту же дату
+	Upsert(rate *models.ExchangeRate) error
+	Delete(id uint) error
+}
+
+type gormExchangeRateRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewExchangeRateRepository(db *gorm.DB, logger *slog.Logger) ExchangeRateRepository {
+	return &gormExchangeRateRepository{db: db, logger: logger}
+}
+
+func (r *gormExchangeRateRepository) List(userID uint, fromCurrency, toCurrency string) ([]models.ExchangeRate, error) {
+	r.logger.Debug("repo.exchange_rate.list",
+		slog.String("op", "repo.exchange_rate.list"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("from", fromCurrency),
+		slog.String("to", toCurrency),
+	)
+
+	var rates []models.ExchangeRate
+	query := r.db.Model(&models.ExchangeRate{}).Where("user_id = ?", userID)
+	if fromCurrency != "" {
+		query = query.Where("from_currency = ?", fromCurrency)
+	}
+	if toCurrency != "" {
+		query = query.Where("to_currency = ?", toCurrency)
+	}
+
+	if err := query.Order("date DESC").Find(&rates).Error; err != nil {
+		r.logger.Error("repo.exchange_rate.list failed",
+			slog.String("op", "repo.exchange_rate.list"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return rates, nil
+}
+
+func (r *gormExchangeRateRepository) GetByID(id uint) (*models.ExchangeRate, error) {
+	r.logger.Debug("repo.exchange_rate.get_by_id",
+		slog.String("op", "repo.exchange_rate.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var rate models.ExchangeRate
+	if err := r.db.First(&rate, id).Error; err != nil {
+		r.logger.Error("repo.exchange_rate.get_by_id failed",
+			slog.String("op", "repo.exchange_rate.get_by_id"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *gormExchangeRateRepository) FindLatest(userID uint, fromCurrency, toCurrency string, date time.Time) (*models.ExchangeRate, error) {
+	r.logger.Debug("repo.exchange_rate.find_latest",
+		slog.String("op", "repo.exchange_rate.find_latest"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("from", fromCurrency),
+		slog.String("to", toCurrency),
+		slog.Time("date", date),
+	)
+	var rate models.ExchangeRate
+	err := r.db.
+		Where("user_id = ? AND from_currency = ? AND to_currency = ? AND date <= ?", userID, fromCurrency, toCurrency, date).
+		Order("date DESC").
+		First(&rate).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.exchange_rate.find_latest failed",
+				slog.String("op", "repo.exchange_rate.find_latest"),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *gormExchangeRateRepository) Upsert(rate *models.ExchangeRate) error {
+	if rate == nil {
+		return errExchangeRateNil
+	}
+
+	r.logger.Debug("repo.exchange_rate.upsert",
+		slog.String("op", "repo.exchange_rate.upsert"),
+		slog.Uint64("user_id", uint64(rate.UserID)),
+		slog.String("from", rate.FromCurrency),
+		slog.String("to", rate.ToCurrency),
+		slog.Time("date", rate.Date),
+	)
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "from_currency"}, {Name: "to_currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error
+	if err != nil {
+		r.logger.Error("repo.exchange_rate.upsert failed",
+			slog.String("op", "repo.exchange_rate.upsert"),
+			slog.String("from", rate.FromCurrency),
+			slog.String("to", rate.ToCurrency),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormExchangeRateRepository) Delete(id uint) error {
+	r.logger.Debug("repo.exchange_rate.delete",
+		slog.String("op", "repo.exchange_rate.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	// Курс удаляется физически, чтобы не мешать уникальному индексу пары и даты
+	if err := r.db.Unscoped().Delete(&models.ExchangeRate{}, id).Error; err != nil {
+		r.logger.Error("repo.exchange_rate.delete failed",
+			slog.String("op", "repo.exchange_rate.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
@@ -6,16 +6,35 @@ import (
 	"log/slog"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var errExpenseNil error = errors.New("expense is nil")
 
 type ExpenseRepository interface {
 	List(filter models.ExpenseFilter) ([]models.Expense, error)
+	// ListPage возвращает страницу расходов по фильтру с пагинацией по курсору
+	ListPage(filter models.ExpenseFilter, page models.PageRequest) (*models.Page[models.Expense], error)
+	// Stream построчно передает расходы по фильтру в fn, не загружая всю выборку в память
+	Stream(filter models.ExpenseFilter, fn func(row models.ExpenseExportRow) error) error
 	GetByID(id uint) (*models.Expense, error)
+	// ListIDs возвращает идентификаторы расходов по фильтру от ранних к поздним, не больше limit
+	ListIDs(filter models.ExpenseFilter, limit int) ([]uint, error)
+	// GetForUpdate возвращает расходы с частями и блокирует их строки до конца транзакции
+	GetForUpdate(ids []uint) ([]models.Expense, error)
 	Create(expense *models.Expense) error
 	Update(expense *models.Expense) error
 	Delete(id uint) error
+	// FindExternalIDs возвращает те из переданных идентификаторов выписки, которые уже есть у расходов пользователя, включая корзину
+	FindExternalIDs(userID uint, externalIDs []string) ([]string, error)
+	// ReplaceTags заменяет набор тегов расхода
+	ReplaceTags(expense *models.Expense, tags []models.Tag) error
+	// ReplaceSplits заменяет части расхода, пустой список отменяет разделение
+	ReplaceSplits(expense *models.Expense, splits []models.ExpenseSplit) error
+	// ListDuplicateCandidates возвращает расходы, у которых есть другой расход с той же суммой в пределах окна дат
+	ListDuplicateCandidates(filter models.DuplicateFilter) ([]models.Expense, error)
+	// GetCategorySamples возвращает описания, суммы и категории последних расходов пользователя с описанием
+	GetCategorySamples(userID uint, limit int) ([]models.CategorySample, error)
 	WithTx(tx TxProvider) ExpenseRepository
 }
 
@@ -39,28 +58,10 @@ func (r *gormExpenseRepository) List(filter models.ExpenseFilter) ([]models.Expe
 	)
 
 	var expenses []models.Expense
-	query := r.db.Model(&models.Expense{}).Preload("Category").Where("user_id = ?", filter.UserID)
-
-	if filter.CategoryID != nil {
-		query = query.Where("category_id = ?", *filter.CategoryID)
-	}
-	if filter.StartDate != nil {
-		query = query.Where("date >= ?", *filter.StartDate)
-	}
-	if filter.EndDate != nil {
-		query = query.Where("date <= ?", *filter.EndDate)
-	}
-	if filter.MinAmount != nil {
-		query = query.Where("amount >= ?", *filter.MinAmount)
-	}
-	if filter.MaxAmount != nil {
-		query = query.Where("amount <= ?", *filter.MaxAmount)
-	}
-	if filter.Limit != nil {
-		query = query.Limit(*filter.Limit)
-	}
-	if filter.Offset != nil {
-		query = query.Offset(*filter.Offset)
+	query := applyExpenseFilter(r.db.Model(&models.Expense{}).Preload("Category").Preload("Tags").Preload("Splits"), filter)
+	if filter.Search != "" {
+		// Найденные расходы упорядочиваются по релевантности
+		query = selectExpenseSearch(query, filter.Search).Order("search_rank DESC, expenses.date DESC, expenses.id DESC")
 	}
 
 	if err := query.Find(&expenses).Error; err != nil {
@@ -73,13 +74,126 @@ func (r *gormExpenseRepository) List(filter models.ExpenseFilter) ([]models.Expe
 	return expenses, nil
 }
 
+func (r *gormExpenseRepository) ListPage(filter models.ExpenseFilter, page models.PageRequest) (*models.Page[models.Expense], error) {
+	r.logger.Debug("repo.expense.list_page",
+		slog.String("op", "repo.expense.list_page"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+		slog.String("sort", page.Sort),
+	)
+
+	keys := map[string]pageKey[models.Expense]{
+		models.SortByDate: {
+			expr:   "expenses.date",
+			value:  func(e *models.Expense) interface{} { return e.Date },
+			decode: decodeTimeCursor,
+		},
+		models.SortByAmount: {
+			expr:   "expenses.amount",
+			value:  func(e *models.Expense) interface{} { return e.Amount },
+			decode: decodeMoneyCursor,
+		},
+		models.SortByCreatedAt: {
+			expr:   "expenses.created_at",
+			value:  func(e *models.Expense) interface{} { return e.CreatedAt },
+			decode: decodeTimeCursor,
+		},
+	}
+	if filter.Search != "" {
+		keys[models.SortByRelevance] = pageKey[models.Expense]{
+			expr:   "ts_rank_cd(" + expenseSearchDocumentSQL + ", " + expenseSearchQuerySQL + ")",
+			args:   []interface{}{filter.Search},
+			value:  func(e *models.Expense) interface{} { return e.SearchRank },
+			decode: decodeFloatCursor,
+		}
+	}
+
+	// Лимит и смещение фильтра не используются: размер страницы задает курсорная пагинация
+	filter.Limit, filter.Offset = nil, nil
+	result, err := pageQuery[models.Expense]{
+		query:    applyExpenseFilter(r.db.Model(&models.Expense{}), filter),
+		idColumn: "expenses.id",
+		keys:     keys,
+		id:       func(e *models.Expense) uint { return e.ID },
+		load: func(query *gorm.DB) *gorm.DB {
+			query = query.Preload("Category").Preload("Tags").Preload("Splits")
+			if filter.Search != "" {
+				query = selectExpenseSearch(query, filter.Search)
+			}
+			return query
+		},
+	}.fetch(page)
+	if err != nil {
+		r.logger.Error("repo.expense.list_page failed",
+			slog.String("op", "repo.expense.list_page"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// selectExpenseSearch добавляет к выборке расходов релевантность и фрагмент с подсветкой,
+// построенный по описанию и категории
+func selectExpenseSearch(query *gorm.DB, search string) *gorm.DB {
+	return query.Select(`expenses.*,
+		ts_rank_cd(`+expenseSearchDocumentSQL+`, `+expenseSearchQuerySQL+`) AS search_rank,
+		ts_headline('russian', `+expenseSearchHeadlineTextSQL+`, `+expenseSearchQuerySQL+`, ?) AS search_snippet`,
+		search, search, expenseSearchHeadlineOptions)
+}
+
+func (r *gormExpenseRepository) Stream(filter models.ExpenseFilter, fn func(row models.ExpenseExportRow) error) error {
+	r.logger.Debug("repo.expense.stream",
+		slog.String("op", "repo.expense.stream"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+	)
+
+	query := applyExpenseFilter(r.db.Model(&models.Expense{}), filter).
+		Select(`expenses.id, expenses.date, expenses.amount, expenses.currency,
+			expenses.original_amount, expenses.exchange_rate, expenses.description,
+			expenses.category_id, COALESCE(categories.name, '') AS category_name,
+			COALESCE(categories.color, '') AS category_color,
+			expenses.account_id, COALESCE(accounts.name, '') AS account_name,
+			COALESCE((
+				SELECT string_agg(tags.name, ', ' ORDER BY tags.name)
+				FROM expense_tags
+				INNER JOIN tags ON tags.id = expense_tags.tag_id
+				WHERE expense_tags.expense_id = expenses.id
+			), '') AS tags`).
+		Joins("LEFT JOIN categories ON categories.id = expenses.category_id").
+		Joins("LEFT JOIN accounts ON accounts.id = expenses.account_id").
+		Order("expenses.date, expenses.id")
+
+	rows, err := query.Rows()
+	if err != nil {
+		r.logger.Error("repo.expense.stream failed",
+			slog.String("op", "repo.expense.stream"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row models.ExpenseExportRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (r *gormExpenseRepository) GetByID(id uint) (*models.Expense, error) {
 	r.logger.Debug("repo.expense.get_by_id",
 		slog.String("op", "repo.expense.get_by_id"),
 		slog.Uint64("id", uint64(id)),
 	)
 	var expense models.Expense
-	if err := r.db.First(&expense, id).Error; err != nil {
+	if err := r.db.Preload("Tags").Preload("Splits").First(&expense, id).Error; err != nil {
 		r.logger.Error("repo.expense.get_by_id failed",
 			slog.String("op", "repo.expense.get_by_id"),
 			slog.Uint64("id", uint64(id)),
@@ -90,6 +204,54 @@ func (r *gormExpenseRepository) GetByID(id uint) (*models.Expense, error) {
 	return &expense, nil
 }
 
+func (r *gormExpenseRepository) ListIDs(filter models.ExpenseFilter, limit int) ([]uint, error) {
+	r.logger.Debug("repo.expense.list_ids",
+		slog.String("op", "repo.expense.list_ids"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+		slog.Int("limit", limit),
+	)
+	var ids []uint
+	err := applyExpenseFilter(r.db.Model(&models.Expense{}), filter).
+		Order("expenses.date ASC, expenses.id ASC").
+		Limit(limit).
+		Pluck("expenses.id", &ids).Error
+	if err != nil {
+		r.logger.Error("repo.expense.list_ids failed",
+			slog.String("op", "repo.expense.list_ids"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *gormExpenseRepository) GetForUpdate(ids []uint) ([]models.Expense, error) {
+	r.logger.Debug("repo.expense.get_for_update",
+		slog.String("op", "repo.expense.get_for_update"),
+		slog.Int("count", len(ids)),
+	)
+	var expenses []models.Expense
+	if len(ids) == 0 {
+		return expenses, nil
+	}
+	// Строки блокируются в порядке идентификаторов, чтобы параллельные массовые операции не взаимоблокировались
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Splits").
+		Where("id IN ?", ids).
+		Order("id").
+		Find(&expenses).Error
+	if err != nil {
+		r.logger.Error("repo.expense.get_for_update failed",
+			slog.String("op", "repo.expense.get_for_update"),
+			slog.Int("count", len(ids)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return expenses, nil
+}
+
 func (r *gormExpenseRepository) Create(expense *models.Expense) error {
 	if expense == nil {
 		return errExpenseNil
@@ -98,7 +260,7 @@ func (r *gormExpenseRepository) Create(expense *models.Expense) error {
 	r.logger.Debug("repo.expense.create",
 		slog.String("op", "repo.expense.create"),
 		slog.Uint64("user_id", uint64(expense.UserID)),
-		slog.Float64("amount", expense.Amount),
+		slog.String("amount", expense.Amount.String()),
 		slog.Uint64("category", uint64(expense.CategoryID)),
 	)
 
@@ -106,7 +268,7 @@ func (r *gormExpenseRepository) Create(expense *models.Expense) error {
 		r.logger.Error("repo.expense.create failed",
 			slog.String("op", "repo.expense.create"),
 			slog.Uint64("user_id", uint64(expense.UserID)),
-			slog.Float64("amount", expense.Amount),
+			slog.String("amount", expense.Amount.String()),
 			slog.Uint64("category", uint64(expense.CategoryID)),
 			slog.String("error", err.Error()),
 		)
@@ -124,7 +286,7 @@ func (r *gormExpenseRepository) Update(expense *models.Expense) error {
 		slog.Uint64("id", uint64(expense.ID)),
 	)
 
-	if err := r.db.Save(expense).Error; err != nil {
+	if err := r.db.Omit("Tags", "Splits").Save(expense).Error; err != nil {
 		r.logger.Error("repo.expense.update failed",
 			slog.String("op", "repo.expense.update"),
 			slog.Uint64("id", uint64(expense.ID)),
@@ -150,3 +312,218 @@ func (r *gormExpenseRepository) Delete(id uint) error {
 	}
 	return nil
 }
+
+func (r *gormExpenseRepository) ReplaceTags(expense *models.Expense, tags []models.Tag) error {
+	if expense == nil {
+		return errExpenseNil
+	}
+	r.logger.Debug("repo.expense.replace_tags",
+		slog.String("op", "repo.expense.replace_tags"),
+		slog.Uint64("id", uint64(expense.ID)),
+		slog.Int("count", len(tags)),
+	)
+
+	association := r.db.Model(expense).Association("Tags")
+	var err error
+	if len(tags) == 0 {
+		err = association.Clear()
+	} else {
+		err = association.Replace(tags)
+	}
+	if err != nil {
+		r.logger.Error("repo.expense.replace_tags failed",
+			slog.String("op", "repo.expense.replace_tags"),
+			slog.Uint64("id", uint64(expense.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormExpenseRepository) ReplaceSplits(expense *models.Expense, splits []models.ExpenseSplit) error {
+	r.logger.Debug("repo.expense.replace_splits",
+		slog.String("op", "repo.expense.replace_splits"),
+		slog.Uint64("id", uint64(expense.ID)),
+		slog.Int("count", len(splits)),
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Прежние части удаляются безвозвратно: история частей не хранится
+		if err := tx.Unscoped().Where("expense_id = ?", expense.ID).Delete(&models.ExpenseSplit{}).Error; err != nil {
+			return err
+		}
+		if len(splits) == 0 {
+			return nil
+		}
+		for i := range splits {
+			splits[i].ID = 0
+			splits[i].ExpenseID = expense.ID
+		}
+		return tx.Omit("Category").Create(&splits).Error
+	})
+	if err != nil {
+		r.logger.Error("repo.expense.replace_splits failed",
+			slog.String("op", "repo.expense.replace_splits"),
+			slog.Uint64("id", uint64(expense.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormExpenseRepository) FindExternalIDs(userID uint, externalIDs []string) ([]string, error) {
+	r.logger.Debug("repo.expense.find_external_ids",
+		slog.String("op", "repo.expense.find_external_ids"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(externalIDs)),
+	)
+	var existing []string
+	if len(externalIDs) == 0 {
+		return existing, nil
+	}
+	// Расходы в корзине тоже учитываются: иначе после их восстановления операция выписки задвоится
+	err := r.db.Unscoped().Model(&models.Expense{}).
+		Where("user_id = ? AND external_id IN ?", userID, externalIDs).
+		Pluck("external_id", &existing).Error
+	if err != nil {
+		r.logger.Error("repo.expense.find_external_ids failed",
+			slog.String("op", "repo.expense.find_external_ids"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return existing, nil
+}
+
+func (r *gormExpenseRepository) ListDuplicateCandidates(filter models.DuplicateFilter) ([]models.Expense, error) {
+	r.logger.Debug("repo.expense.list_duplicate_candidates",
+		slog.String("op", "repo.expense.list_duplicate_candidates"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+		slog.Int("window_days", filter.WindowDays),
+	)
+
+	query := r.db.Model(&models.Expense{}).Preload("Category").Preload("Tags").
+		Where("expenses.user_id = ?", filter.UserID).
+		Where(`EXISTS (
+			SELECT 1 FROM expenses other
+			WHERE other.user_id = expenses.user_id
+				AND other.id <> expenses.id
+				AND other.amount = expenses.amount
+				AND other.deleted_at IS NULL
+				AND other.date BETWEEN expenses.date - make_interval(days => ?) AND expenses.date + make_interval(days => ?)
+		)`, filter.WindowDays, filter.WindowDays)
+	if filter.StartDate != nil {
+		query = query.Where("expenses.date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("expenses.date <= ?", *filter.EndDate)
+	}
+
+	var expenses []models.Expense
+	if err := query.Order("expenses.amount, expenses.date, expenses.id").Find(&expenses).Error; err != nil {
+		r.logger.Error("repo.expense.list_duplicate_candidates failed",
+			slog.String("op", "repo.expense.list_duplicate_candidates"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return expenses, nil
+}
+
+func (r *gormExpenseRepository) GetCategorySamples(userID uint, limit int) ([]models.CategorySample, error) {
+	r.logger.Debug("repo.expense.get_category_samples",
+		slog.String("op", "repo.expense.get_category_samples"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("limit", limit),
+	)
+	var samples []models.CategorySample
+	err := r.db.Model(&models.Expense{}).
+		Select("category_id, description, amount").
+		Where("user_id = ? AND description <> ''", userID).
+		Order("date DESC, id DESC").
+		Limit(limit).
+		Scan(&samples).Error
+	if err != nil {
+		r.logger.Error("repo.expense.get_category_samples failed",
+			slog.String("op", "repo.expense.get_category_samples"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return samples, nil
+}
+
+const (
+	// expenseSearchDocumentSQL поисковый документ расхода: описание и название категории в конфигурации
+	// russian, латинские слова в ней приводятся английским стеммером. Категория входит в документ, поэтому
+	// запрос «ozon покупки» находит заказ Ozon в категории «Покупки». Столбец заполняется триггерами
+	// (см. database.migrateExpenseSearch) и покрыт GIN-индексом.
+	expenseSearchDocumentSQL = "expenses.search_vector"
+	// expenseSearchQuerySQL разбирает строку поиска: поддерживаются фразы в кавычках, OR и исключение
+	// слов через минус. Запрос строится в той же конфигурации, что и документ, поэтому исключенное слово
+	// отбрасывает расход при любом написании.
+	expenseSearchQuerySQL = "websearch_to_tsquery('russian', ?)"
+	// expenseSearchHeadlineTextSQL текст фрагмента: описание и категория с экранированными символами HTML,
+	// чтобы в ответ попадала только разметка подсветки
+	expenseSearchHeadlineTextSQL = `replace(replace(replace(concat_ws(' — ', NULLIF(expenses.description, ''), (
+			SELECT categories.name FROM categories WHERE categories.id = expenses.category_id
+		)), '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+	// expenseSearchHeadlineOptions параметры фрагмента с подсветкой найденных слов
+	expenseSearchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5, MaxFragments=2"
+)
+
+// applyExpenseFilter добавляет к запросу условия фильтра расходов.
+// Столбцы указываются с именем таблицы, чтобы фильтр работал и в запросах с JOIN.
+func applyExpenseFilter(query *gorm.DB, filter models.ExpenseFilter) *gorm.DB {
+	query = query.Where("expenses.user_id = ?", filter.UserID)
+
+	if filter.AccountID != nil {
+		query = query.Where("expenses.account_id = ?", *filter.AccountID)
+	}
+	// Разделенный расход попадает в выборку и по категории любой своей части
+	if filter.CategoryID != nil {
+		if filter.IncludeSubcategories {
+			query = query.Where("(expenses.category_id IN ("+categorySubtreeSQL+") OR expenses.id IN ("+
+				"SELECT expense_id FROM expense_splits WHERE deleted_at IS NULL AND category_id IN ("+categorySubtreeSQL+")))",
+				*filter.CategoryID, *filter.CategoryID)
+		} else {
+			query = query.Where("(expenses.category_id = ? OR expenses.id IN ("+
+				"SELECT expense_id FROM expense_splits WHERE deleted_at IS NULL AND category_id = ?))",
+				*filter.CategoryID, *filter.CategoryID)
+		}
+	}
+	if filter.StartDate != nil {
+		query = query.Where("expenses.date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("expenses.date <= ?", *filter.EndDate)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("expenses.amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("expenses.amount <= ?", *filter.MaxAmount)
+	}
+	if len(filter.TagIDs) > 0 {
+		query = query.Where("expenses.id IN (SELECT expense_id FROM expense_tags WHERE tag_id IN ?)", filter.TagIDs)
+	}
+	if len(filter.ExcludeTagIDs) > 0 {
+		query = query.Where("expenses.id NOT IN (SELECT expense_id FROM expense_tags WHERE tag_id IN ?)", filter.ExcludeTagIDs)
+	}
+	// Полнотекстовый поиск по описанию расхода и названию его категории
+	if filter.Search != "" {
+		query = query.Where(expenseSearchDocumentSQL+" @@ "+expenseSearchQuerySQL, filter.Search)
+	}
+	if filter.Limit != nil {
+		query = query.Limit(*filter.Limit)
+	}
+	if filter.Offset != nil {
+		query = query.Offset(*filter.Offset)
+	}
+	return query
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var errIncomeNil error = errors.New("income is nil")
+
+type IncomeRepository interface {
+	List(filter models.IncomeFilter) ([]models.Income, error)
+	GetByID(id uint) (*models.Income, error)
+	Create(income *models.Income) error
+	Update(income *models.Income) error
+	Delete(id uint) error
+	WithTx(tx TxProvider) IncomeRepository
+}
+
+type gormIncomeRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewIncomeRepository(db *gorm.DB, logger *slog.Logger) IncomeRepository {
+	return &gormIncomeRepository{db: db, logger: logger}
+}
+
+func (r *gormIncomeRepository) WithTx(tx TxProvider) IncomeRepository {
+	return &gormIncomeRepository{db: tx.DB(), logger: r.logger}
+}
+
+func (r *gormIncomeRepository) List(filter models.IncomeFilter) ([]models.Income, error) {
+
+	r.logger.Debug("repo.income.list",
+		slog.String("op", "repo.income.list"),
+	)
+
+	var incomes []models.Income
+	query := r.db.Model(&models.Income{}).Preload("Category").Where("user_id = ?", filter.UserID)
+
+	if filter.AccountID != nil {
+		query = query.Where("account_id = ?", *filter.AccountID)
+	}
+	if filter.CategoryID != nil {
+		if filter.IncludeSubcategories {
+			query = query.Where("category_id IN ("+categorySubtreeSQL+")", *filter.CategoryID)
+		} else {
+			query = query.Where("category_id = ?", *filter.CategoryID)
+		}
+	}
+	if filter.StartDate != nil {
+		query = query.Where("date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("date <= ?", *filter.EndDate)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", *filter.MaxAmount)
+	}
+	if filter.Limit != nil {
+		query = query.Limit(*filter.Limit)
+	}
+	if filter.Offset != nil {
+		query = query.Offset(*filter.Offset)
+	}
+
+	if err := query.Find(&incomes).Error; err != nil {
+		r.logger.Error("repo.income.list failed",
+			slog.String("op", "repo.income.list"),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return incomes, nil
+}
+
+func (r *gormIncomeRepository) GetByID(id uint) (*models.Income, error) {
+	r.logger.Debug("repo.income.get_by_id",
+		slog.String("op", "repo.income.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var income models.Income
+	if err := r.db.First(&income, id).Error; err != nil {
+		r.logger.Error("repo.income.get_by_id failed",
+			slog.String("op", "repo.income.get_by_id"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &income, nil
+}
+
+func (r *gormIncomeRepository) Create(income *models.Income) error {
+	if income == nil {
+		return errIncomeNil
+	}
+
+	r.logger.Debug("repo.income.create",
+		slog.String("op", "repo.income.create"),
+		slog.Uint64("user_id", uint64(income.UserID)),
+		slog.String("amount", income.Amount.String()),
+		slog.Uint64("category", uint64(income.CategoryID)),
+	)
+
+	if err := r.db.Create(income).Error; err != nil {
+		r.logger.Error("repo.income.create failed",
+			slog.String("op", "repo.income.create"),
+			slog.Uint64("user_id", uint64(income.UserID)),
+			slog.String("amount", income.Amount.String()),
+			slog.Uint64("category", uint64(income.CategoryID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormIncomeRepository) Update(income *models.Income) error {
+	if income == nil {
+		return errIncomeNil
+	}
+	r.logger.Debug("repo.income.update",
+		slog.String("op", "repo.income.update"),
+		slog.Uint64("id", uint64(income.ID)),
+	)
+
+	if err := r.db.Save(income).Error; err != nil {
+		r.logger.Error("repo.income.update failed",
+			slog.String("op", "repo.income.update"),
+			slog.Uint64("id", uint64(income.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormIncomeRepository) Delete(id uint) error {
+	r.logger.Debug("repo.income.delete",
+		slog.String("op", "repo.income.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Delete(&models.Income{}, id).Error; err != nil {
+		r.logger.Error("repo.income.delete failed",
+			slog.String("op", "repo.income.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
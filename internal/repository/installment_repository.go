@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errInstallmentPlanNil error = errors.New("installment plan is nil")
+
+type InstallmentPlanRepository interface {
+	GetByID(id uint) (*models.InstallmentPlan, error)
+	GetByUserID(userID uint) ([]models.InstallmentPlan, error)
+	// GetDue возвращает планы, дата следующего платежа которых не позже before
+	GetDue(before time.Time) ([]models.InstallmentPlan, error)
+	// GetForUpdate возвращает план и блокирует его строку до конца транзакции
+	GetForUpdate(id uint) (*models.InstallmentPlan, error)
+	// GetPaidAmounts возвращает сумму и количество существующих расходов-платежей каждого из планов
+	GetPaidAmounts(planIDs []uint) (map[uint]models.InstallmentPaid, error)
+	Create(plan *models.InstallmentPlan) error
+	Update(plan *models.InstallmentPlan) error
+	Delete(id uint) error
+	WithTx(tx TxProvider) InstallmentPlanRepository
+}
+
+type gormInstallmentPlanRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewInstallmentPlanRepository(db *gorm.DB, logger *slog.Logger) InstallmentPlanRepository {
+	return &gormInstallmentPlanRepository{db: db, logger: logger}
+}
+
+func (r *gormInstallmentPlanRepository) WithTx(tx TxProvider) InstallmentPlanRepository {
+	return &gormInstallmentPlanRepository{db: tx.DB(), logger: r.logger}
+}
+
+func (r *gormInstallmentPlanRepository) GetByID(id uint) (*models.InstallmentPlan, error) {
+	r.logger.Debug("repo.installment_plan.get_by_id",
+		slog.String("op", "repo.installment_plan.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var plan models.InstallmentPlan
+	if err := r.db.Preload("Category").First(&plan, id).Error; err != nil {
+		r.logger.Error("repo.installment_plan.get_by_id failed",
+			slog.String("op", "repo.installment_plan.get_by_id"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *gormInstallmentPlanRepository) GetByUserID(userID uint) ([]models.InstallmentPlan, error) {
+	r.logger.Debug("repo.installment_plan.get_by_user_id",
+		slog.String("op", "repo.installment_plan.get_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var plans []models.InstallmentPlan
+	// Сначала планы с ближайшим платежом, выплаченные в конце
+	err := r.db.Preload("Category").
+		Where("user_id = ?", userID).
+		Order("next_date ASC NULLS LAST, id").
+		Find(&plans).Error
+	if err != nil {
+		r.logger.Error("repo.installment_plan.get_by_user_id failed",
+			slog.String("op", "repo.installment_plan.get_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (r *gormInstallmentPlanRepository) GetDue(before time.Time) ([]models.InstallmentPlan, error) {
+	r.logger.Debug("repo.installment_plan.get_due",
+		slog.String("op", "repo.installment_plan.get_due"),
+		slog.Time("before", before),
+	)
+	var plans []models.InstallmentPlan
+	if err := r.db.Where("next_date <= ?", before).Order("next_date, id").Find(&plans).Error; err != nil {
+		r.logger.Error("repo.installment_plan.get_due failed",
+			slog.String("op", "repo.installment_plan.get_due"),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (r *gormInstallmentPlanRepository) GetForUpdate(id uint) (*models.InstallmentPlan, error) {
+	r.logger.Debug("repo.installment_plan.get_for_update",
+		slog.String("op", "repo.installment_plan.get_for_update"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var plan models.InstallmentPlan
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&plan, id).Error; err != nil {
+		r.logger.Error("repo.installment_plan.get_for_update failed",
+			slog.String("op", "repo.installment_plan.get_for_update"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *gormInstallmentPlanRepository) GetPaidAmounts(planIDs []uint) (map[uint]models.InstallmentPaid, error) {
+	r.logger.Debug("repo.installment_plan.get_paid_amounts",
+		slog.String("op", "repo.installment_plan.get_paid_amounts"),
+		slog.Int("plans", len(planIDs)),
+	)
+	paid := make(map[uint]models.InstallmentPaid, len(planIDs))
+	if len(planIDs) == 0 {
+		return paid, nil
+	}
+
+	var rows []struct {
+		InstallmentPlanID uint
+		Amount            models.Money
+		Payments          int
+	}
+	err := r.db.Model(&models.Expense{}).
+		Select("installment_plan_id, SUM(amount) AS amount, COUNT(*) AS payments").
+		Where("installment_plan_id IN ?", planIDs).
+		Group("installment_plan_id").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("repo.installment_plan.get_paid_amounts failed",
+			slog.String("op", "repo.installment_plan.get_paid_amounts"),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	for _, row := range rows {
+		paid[row.InstallmentPlanID] = models.InstallmentPaid{Amount: row.Amount, Payments: row.Payments}
+	}
+	return paid, nil
+}
+
+func (r *gormInstallmentPlanRepository) Create(plan *models.InstallmentPlan) error {
+	if plan == nil {
+		return errInstallmentPlanNil
+	}
+
+	r.logger.Debug("repo.installment_plan.create",
+		slog.String("op", "repo.installment_plan.create"),
+		slog.Uint64("user_id", uint64(plan.UserID)),
+		slog.String("total_amount", plan.TotalAmount.String()),
+	)
+
+	if err := r.db.Omit("Category").Create(plan).Error; err != nil {
+		r.logger.Error("repo.installment_plan.create failed",
+			slog.String("op", "repo.installment_plan.create"),
+			slog.Uint64("user_id", uint64(plan.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormInstallmentPlanRepository) Update(plan *models.InstallmentPlan) error {
+	if plan == nil {
+		return errInstallmentPlanNil
+	}
+
+	r.logger.Debug("repo.installment_plan.update",
+		slog.String("op", "repo.installment_plan.update"),
+		slog.Uint64("id", uint64(plan.ID)),
+	)
+
+	if err := r.db.Omit("Category").Save(plan).Error; err != nil {
+		r.logger.Error("repo.installment_plan.update failed",
+			slog.String("op", "repo.installment_plan.update"),
+			slog.Uint64("id", uint64(plan.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormInstallmentPlanRepository) Delete(id uint) error {
+	r.logger.Debug("repo.installment_plan.delete",
+		slog.String("op", "repo.installment_plan.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Delete(&models.InstallmentPlan{}, id).Error; err != nil {
+		r.logger.Error("repo.installment_plan.delete failed",
+			slog.String("op", "repo.installment_plan.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// pageKey поле сортировки постраничного списка
+type pageKey[T any] struct {
+	expr   string                                         // SQL-выражение значения поля с именем таблицы
+	args   []interface{}                                  // Параметры выражения
+	value  func(item *T) interface{}                      // Значение поля у записи для курсора
+	decode func(raw json.RawMessage) (interface{}, error) // Значение из курсора для условия запроса
+}
+
+// pageQuery постраничная выборка по курсору (keyset): условие (поле, id) > (значение, id курсора)
+// вместо смещения, поэтому страницы не сдвигаются при добавлении записей и не замедляются к концу списка
+type pageQuery[T any] struct {
+	query    *gorm.DB                      // Запрос с условиями фильтра, без сортировки и лимита
+	idColumn string                        // Столбец идентификатора, по которому упорядочиваются равные значения
+	keys     map[string]pageKey[T]         // Допустимые поля сортировки
+	id       func(item *T) uint            // Идентификатор записи
+	load     func(query *gorm.DB) *gorm.DB // Связи и дополнительные столбцы для выборки записей страницы
+}
+
+func (q pageQuery[T]) fetch(page models.PageRequest) (*models.Page[T], error) {
+	key, ok := q.keys[page.Sort]
+	if !ok {
+		return nil, fmt.Errorf("сортировка по %q не поддерживается", page.Sort)
+	}
+
+	// Сессия копирует условия, чтобы подсчет и выборка страницы не влияли друг на друга
+	base := q.query.Session(&gorm.Session{})
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	direction, operator := "ASC", ">"
+	if page.Direction == models.SortDesc {
+		direction, operator = "DESC", "<"
+	}
+
+	find := base
+	if q.load != nil {
+		find = q.load(find)
+	}
+	if page.Cursor != nil {
+		value, err := key.decode(page.Cursor.Value)
+		if err != nil {
+			return nil, models.ErrInvalidCursor
+		}
+		args := append(append([]interface{}{}, key.args...), value, page.Cursor.ID)
+		find = find.Where("("+key.expr+", "+q.idColumn+") "+operator+" (?, ?)", args...)
+	}
+
+	var items []T
+	err := find.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:                key.expr + " " + direction + ", " + q.idColumn + " " + direction,
+		Vars:               key.args,
+		WithoutParentheses: true,
+	}}).Limit(page.Limit + 1).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.Page[T]{Items: items, Total: total, Limit: page.Limit}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+	if len(items) > page.Limit {
+		result.Items = items[:page.Limit]
+		result.HasMore = true
+
+		last := &result.Items[page.Limit-1]
+		value, err := json.Marshal(key.value(last))
+		if err != nil {
+			return nil, err
+		}
+		next := models.Cursor{Sort: page.Sort, Direction: page.Direction, Value: value, ID: q.id(last)}.Encode()
+		result.NextCursor = &next
+	}
+	return result, nil
+}
+
+func decodeTimeCursor(raw json.RawMessage) (interface{}, error) {
+	var value time.Time
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+
+func decodeMoneyCursor(raw json.RawMessage) (interface{}, error) {
+	var value models.Money
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+
+func decodeIntCursor(raw json.RawMessage) (interface{}, error) {
+	var value int64
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+
+func decodeFloatCursor(raw json.RawMessage) (interface{}, error) {
+	var value float64
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
@@ -15,10 +15,14 @@ type RecurringExpenseRepository interface {
 	List() ([]models.RecurringExpense, error)
 	GetByID(id uint) (*models.RecurringExpense, error)
 	GetByUserID(userID uint) ([]models.RecurringExpense, error)
+	// ListPage возвращает страницу регулярных расходов пользователя с пагинацией по курсору
+	ListPage(userID uint, page models.PageRequest) (*models.Page[models.RecurringExpense], error)
 	GetActiveByNextDate(nextDate time.Time) ([]models.RecurringExpense, error)
 	Create(recurringExpense *models.RecurringExpense) error
 	Update(recurringExpense *models.RecurringExpense) error
 	Delete(id uint) error
+	// ReplaceTags заменяет набор тегов регулярного расхода
+	ReplaceTags(recurringExpense *models.RecurringExpense, tags []models.Tag) error
 	WithTx(tx TxProvider) RecurringExpenseRepository
 }
 
@@ -56,7 +60,7 @@ func (r *gormRecurringExpenseRepository) GetByID(id uint) (*models.RecurringExpe
 		slog.Uint64("id", uint64(id)),
 	)
 	var recurringExpense models.RecurringExpense
-	if err := r.db.Preload("Category").First(&recurringExpense, id).Error; err != nil {
+	if err := r.db.Preload("Category").Preload("Tags").First(&recurringExpense, id).Error; err != nil {
 		r.logger.Error("repo.recurring_expense.get_by_id failed",
 			slog.String("op", "repo.recurring_expense.get_by_id"),
 			slog.Uint64("id", uint64(id)),
@@ -73,7 +77,7 @@ func (r *gormRecurringExpenseRepository) GetByUserID(userID uint) ([]models.Recu
 		slog.Uint64("user_id", uint64(userID)),
 	)
 	var recurringExpenses []models.RecurringExpense
-	if err := r.db.Preload("Category").Where("user_id = ?", userID).Order("next_date ASC").Find(&recurringExpenses).Error; err != nil {
+	if err := r.db.Preload("Category").Preload("Tags").Where("user_id = ?", userID).Order("next_date ASC").Find(&recurringExpenses).Error; err != nil {
 		r.logger.Error("repo.recurring_expense.get_by_user_id failed",
 			slog.String("op", "repo.recurring_expense.get_by_user_id"),
 			slog.Uint64("user_id", uint64(userID)),
@@ -84,13 +88,55 @@ func (r *gormRecurringExpenseRepository) GetByUserID(userID uint) ([]models.Recu
 	return recurringExpenses, nil
 }
 
+func (r *gormRecurringExpenseRepository) ListPage(userID uint, page models.PageRequest) (*models.Page[models.RecurringExpense], error) {
+	r.logger.Debug("repo.recurring_expense.list_page",
+		slog.String("op", "repo.recurring_expense.list_page"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("sort", page.Sort),
+	)
+	recurringExpenses, err := pageQuery[models.RecurringExpense]{
+		query:    r.db.Model(&models.RecurringExpense{}).Where("user_id = ?", userID),
+		idColumn: "recurring_expenses.id",
+		keys: map[string]pageKey[models.RecurringExpense]{
+			models.SortByDate: {
+				expr:   "recurring_expenses.next_date",
+				value:  func(e *models.RecurringExpense) interface{} { return e.NextDate },
+				decode: decodeTimeCursor,
+			},
+			models.SortByAmount: {
+				expr:   "recurring_expenses.amount",
+				value:  func(e *models.RecurringExpense) interface{} { return e.Amount },
+				decode: decodeMoneyCursor,
+			},
+			models.SortByCreatedAt: {
+				expr:   "recurring_expenses.created_at",
+				value:  func(e *models.RecurringExpense) interface{} { return e.CreatedAt },
+				decode: decodeTimeCursor,
+			},
+		},
+		id: func(e *models.RecurringExpense) uint { return e.ID },
+		load: func(query *gorm.DB) *gorm.DB {
+			return query.Preload("Category").Preload("Tags")
+		},
+	}.fetch(page)
+	if err != nil {
+		r.logger.Error("repo.recurring_expense.list_page failed",
+			slog.String("op", "repo.recurring_expense.list_page"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return recurringExpenses, nil
+}
+
 func (r *gormRecurringExpenseRepository) GetActiveByNextDate(nextDate time.Time) ([]models.RecurringExpense, error) {
 	r.logger.Debug("repo.recurring_expense.get_active_by_next_date",
 		slog.String("op", "repo.recurring_expense.get_active_by_next_date"),
 		slog.Time("next_date", nextDate),
 	)
 	var recurringExpenses []models.RecurringExpense
-	if err := r.db.Preload("Category").Where("is_active = ? AND next_date <= ?", true, nextDate).Find(&recurringExpenses).Error; err != nil {
+	if err := r.db.Preload("Category").Preload("Tags").Where("is_active = ? AND next_date <= ?", true, nextDate).Find(&recurringExpenses).Error; err != nil {
 		r.logger.Error("repo.recurring_expense.get_active_by_next_date failed",
 			slog.String("op", "repo.recurring_expense.get_active_by_next_date"),
 			slog.Time("next_date", nextDate),
@@ -109,7 +155,7 @@ func (r *gormRecurringExpenseRepository) Create(recurringExpense *models.Recurri
 		slog.String("op", "repo.recurring_expense.create"),
 		slog.Uint64("user_id", uint64(recurringExpense.UserID)),
 		slog.Uint64("category_id", uint64(recurringExpense.CategoryID)),
-		slog.Float64("amount", recurringExpense.Amount),
+		slog.String("amount", recurringExpense.Amount.String()),
 		slog.String("type", string(recurringExpense.Type)),
 		slog.Time("next_date", recurringExpense.NextDate),
 	)
@@ -118,7 +164,7 @@ func (r *gormRecurringExpenseRepository) Create(recurringExpense *models.Recurri
 			slog.String("op", "repo.recurring_expense.create"),
 			slog.Uint64("user_id", uint64(recurringExpense.UserID)),
 			slog.Uint64("category_id", uint64(recurringExpense.CategoryID)),
-			slog.Float64("amount", recurringExpense.Amount),
+			slog.String("amount", recurringExpense.Amount.String()),
 			slog.String("type", string(recurringExpense.Type)),
 			slog.Time("next_date", recurringExpense.NextDate),
 			slog.String("error", err.Error()),
@@ -137,17 +183,17 @@ func (r *gormRecurringExpenseRepository) Update(recurringExpense *models.Recurri
 		slog.Uint64("id", uint64(recurringExpense.ID)),
 		slog.Uint64("user_id", uint64(recurringExpense.UserID)),
 		slog.Uint64("category_id", uint64(recurringExpense.CategoryID)),
-		slog.Float64("amount", recurringExpense.Amount),
+		slog.String("amount", recurringExpense.Amount.String()),
 		slog.String("type", string(recurringExpense.Type)),
 		slog.Time("next_date", recurringExpense.NextDate),
 	)
-	if err := r.db.Save(recurringExpense).Error; err != nil {
+	if err := r.db.Omit("Tags").Save(recurringExpense).Error; err != nil {
 		r.logger.Error("repo.recurring_expense.update failed",
 			slog.String("op", "repo.recurring_expense.update"),
 			slog.Uint64("id", uint64(recurringExpense.ID)),
 			slog.Uint64("user_id", uint64(recurringExpense.UserID)),
 			slog.Uint64("category_id", uint64(recurringExpense.CategoryID)),
-			slog.Float64("amount", recurringExpense.Amount),
+			slog.String("amount", recurringExpense.Amount.String()),
 			slog.String("type", string(recurringExpense.Type)),
 			slog.Time("next_date", recurringExpense.NextDate),
 			slog.String("error", err.Error()),
@@ -172,3 +218,31 @@ func (r *gormRecurringExpenseRepository) Delete(id uint) error {
 	}
 	return nil
 }
+
+func (r *gormRecurringExpenseRepository) ReplaceTags(recurringExpense *models.RecurringExpense, tags []models.Tag) error {
+	if recurringExpense == nil {
+		return errRecurringExpenseNil
+	}
+	r.logger.Debug("repo.recurring_expense.replace_tags",
+		slog.String("op", "repo.recurring_expense.replace_tags"),
+		slog.Uint64("id", uint64(recurringExpense.ID)),
+		slog.Int("count", len(tags)),
+	)
+
+	association := r.db.Model(recurringExpense).Association("Tags")
+	var err error
+	if len(tags) == 0 {
+		err = association.Clear()
+	} else {
+		err = association.Replace(tags)
+	}
+	if err != nil {
+		r.logger.Error("repo.recurring_expense.replace_tags failed",
+			slog.String("op", "repo.recurring_expense.replace_tags"),
+			slog.Uint64("id", uint64(recurringExpense.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
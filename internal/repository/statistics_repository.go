@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
@@ -43,20 +44,25 @@ func (r *gormStatisticsRepository) GetPeriodStatistics(
 		CategoryID    uint
 		CategoryName  string
 		CategoryColor string
-		TotalAmount   float64
+		TotalAmount   models.Money
 		Count         int
+		PrimaryCount  int
 	}
 
-	// Используем BETWEEN как в analytics для совместимости с PostgreSQL
+	// Используем BETWEEN как в analytics для совместимости с PostgreSQL.
+	// Разделенный расход учитывается частями в категориях частей, остальные — целиком в своей категории.
+	// Расход считается в общем количестве один раз — в категории, совпадающей с его основной.
 	query := `
 		SELECT
 			c.id    AS category_id,
 			c.name  AS category_name,
 			c.color AS category_color,
-			COALESCE(SUM(e.amount), 0) AS total_amount,
-			COUNT(e.id)   AS count
+			COALESCE(SUM(COALESCE(s.amount, e.amount)), 0) AS total_amount,
+			COUNT(DISTINCT e.id) AS count,
+			COUNT(DISTINCT e.id) FILTER (WHERE c.id = e.category_id) AS primary_count
 		FROM expenses e
-		INNER JOIN categories c ON c.id = e.category_id
+		LEFT JOIN expense_splits s ON s.expense_id = e.id AND s.deleted_at IS NULL
+		INNER JOIN categories c ON c.id = COALESCE(s.category_id, e.category_id)
 		WHERE e.user_id = ?
 		  AND e.date BETWEEN ? AND ?
 		  AND e.deleted_at IS NULL
@@ -83,18 +89,39 @@ func (r *gormStatisticsRepository) GetPeriodStatistics(
 		slog.Int("rows_count", len(rows)),
 	)
 
-	var total float64
+	var total models.Money
 	var count int
 
 	for _, r := range rows {
 		total += r.TotalAmount
-		count += r.Count
+		count += r.PrimaryCount
 	}
 
 	// Вычисляем среднее значение
-	averageAmount := 0.0
-	if count > 0 {
-		averageAmount = total / float64(count)
+	averageAmount := total.Div(count)
+
+	var income struct {
+		Total models.Money
+	}
+	incomeQuery := `
+		SELECT COALESCE(SUM(amount), 0) AS total
+		FROM incomes
+		WHERE user_id = ?
+		  AND date BETWEEN ? AND ?
+		  AND deleted_at IS NULL
+	`
+	if err := r.db.Raw(incomeQuery, userID, start, end).Scan(&income).Error; err != nil {
+		r.logger.Error("income SQL query failed",
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	// Доля сбережений считается только при наличии доходов
+	totalIncome := income.Total
+	savingsRate := 0.0
+	if totalIncome > 0 {
+		savingsRate = (totalIncome - total).Percent(totalIncome)
 	}
 
 	stats := &models.PeriodStatistics{
@@ -103,24 +130,158 @@ func (r *gormStatisticsRepository) GetPeriodStatistics(
 		TotalAmount:   total,
 		Count:         count,
 		AverageAmount: averageAmount,
+		TotalIncome:   totalIncome,
+		NetAmount:     totalIncome - total,
+		SavingsRate:   savingsRate,
 		ByCategory:    []models.CategoryStatistics{}, // Инициализируем как пустой слайс, а не nil
+		ByTag:         []models.TagStatistics{},
 	}
 
-	for _, r := range rows {
-		percentage := 0.0
-		if total > 0 {
-			percentage = (r.TotalAmount / total) * 100
-		}
+	// Расход с несколькими тегами учитывается в каждом из них
+	var tagRows []struct {
+		TagID       uint
+		TagName     string
+		TagColor    string
+		TotalAmount models.Money
+		Count       int
+	}
+	tagQuery := `
+		SELECT
+			t.id    AS tag_id,
+			t.name  AS tag_name,
+			t.color AS tag_color,
+			COALESCE(SUM(e.amount), 0) AS total_amount,
+			COUNT(e.id) AS count
+		FROM expenses e
+		INNER JOIN expense_tags et ON et.expense_id = e.id
+		INNER JOIN tags t ON t.id = et.tag_id
+		WHERE e.user_id = ?
+		  AND e.date BETWEEN ? AND ?
+		  AND e.deleted_at IS NULL
+		  AND t.deleted_at IS NULL
+		GROUP BY t.id, t.name, t.color
+		ORDER BY total_amount DESC
+	`
+	if err := r.db.Raw(tagQuery, userID, start, end).Scan(&tagRows).Error; err != nil {
+		r.logger.Error("tags SQL query failed",
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	for _, row := range tagRows {
+		stats.ByTag = append(stats.ByTag, models.TagStatistics{
+			TagID:       row.TagID,
+			TagName:     row.TagName,
+			TagColor:    row.TagColor,
+			TotalAmount: row.TotalAmount,
+			Count:       row.Count,
+			Percentage:  row.TotalAmount.Percent(total),
+		})
+	}
 
-		stats.ByCategory = append(stats.ByCategory, models.CategoryStatistics{
+	// Иерархия категорий пользователя для сворачивания подкатегорий в родительские
+	var categories []models.Category
+	if err := r.db.Select("id", "name", "color", "parent_id").
+		Where("user_id = ?", userID).
+		Find(&categories).Error; err != nil {
+		r.logger.Error("categories SQL query failed",
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	own := make(map[uint]models.CategoryStatistics, len(rows))
+	for _, r := range rows {
+		own[r.CategoryID] = models.CategoryStatistics{
 			CategoryID:    r.CategoryID,
 			CategoryName:  r.CategoryName,
 			CategoryColor: r.CategoryColor,
-			TotalAmount:   r.TotalAmount,
+			OwnAmount:     r.TotalAmount,
 			Count:         r.Count,
-			Percentage:    percentage,
-		})
+		}
 	}
 
+	stats.ByCategory = rollUpCategoryStatistics(own, categories, total)
+
 	return stats, nil
 }
+
+// rollUpCategoryStatistics строит дерево статистики: сумма и количество каждой категории
+// включают все ее подкатегории. В дерево попадают только категории с расходами в поддереве.
+func rollUpCategoryStatistics(
+	own map[uint]models.CategoryStatistics,
+	categories []models.Category,
+	total models.Money,
+) []models.CategoryStatistics {
+	byID := make(map[uint]models.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	childrenOf := make(map[uint][]uint)
+	var roots []uint
+	for _, c := range categories {
+		if c.ParentID != nil {
+			if _, ok := byID[*c.ParentID]; ok {
+				childrenOf[*c.ParentID] = append(childrenOf[*c.ParentID], c.ID)
+				continue
+			}
+		}
+		roots = append(roots, c.ID)
+	}
+	// Категории с расходами, которых нет среди категорий пользователя, выводятся как корневые
+	for id := range own {
+		if _, ok := byID[id]; !ok {
+			roots = append(roots, id)
+		}
+	}
+
+	var build func(id uint) models.CategoryStatistics
+	build = func(id uint) models.CategoryStatistics {
+		stat, ok := own[id]
+		if !ok {
+			category := byID[id]
+			stat = models.CategoryStatistics{
+				CategoryID:    id,
+				CategoryName:  category.Name,
+				CategoryColor: category.Color,
+			}
+		}
+		if category, ok := byID[id]; ok {
+			stat.ParentID = category.ParentID
+		}
+		stat.TotalAmount = stat.OwnAmount
+
+		for _, childID := range childrenOf[id] {
+			child := build(childID)
+			if child.Count == 0 {
+				continue
+			}
+			stat.TotalAmount += child.TotalAmount
+			stat.Count += child.Count
+			stat.Children = append(stat.Children, child)
+		}
+		sortCategoryStatistics(stat.Children)
+
+		if total > 0 {
+			stat.Percentage = stat.TotalAmount.Percent(total)
+		}
+		return stat
+	}
+
+	result := []models.CategoryStatistics{}
+	for _, id := range roots {
+		if stat := build(id); stat.Count > 0 {
+			result = append(result, stat)
+		}
+	}
+	sortCategoryStatistics(result)
+
+	return result
+}
+
+func sortCategoryStatistics(stats []models.CategoryStatistics) {
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].TotalAmount > stats[j].TotalAmount
+	})
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var errTagNil error = errors.New("tag is nil")
+
+type TagRepository interface {
+	GetByID(id uint) (*models.Tag, error)
+	GetByUserID(userID uint) ([]models.Tag, error)
+	// GetByIDs возвращает теги пользователя с указанными идентификаторами
+	GetByIDs(userID uint, ids []uint) ([]models.Tag, error)
+	Create(tag *models.Tag) error
+	Update(tag *models.Tag) error
+	// Delete удаляет тег окончательно вместе с его привязками к расходам
+	Delete(id uint) error
+	WithTx(tx TxProvider) TagRepository
+}
+
+type gormTagRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewTagRepository(db *gorm.DB, logger *slog.Logger) TagRepository {
+	return &gormTagRepository{db: db, logger: logger}
+}
+
+func (r *gormTagRepository) WithTx(tx TxProvider) TagRepository {
+	return &gormTagRepository{db: tx.DB(), logger: r.logger}
+}
+
+func (r *gormTagRepository) GetByID(id uint) (*models.Tag, error) {
+	r.logger.Debug("repo.tag.get_by_id",
+		slog.String("op", "repo.tag.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var tag models.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		r.logger.Error("repo.tag.get_by_id failed",
+			slog.String("op", "repo.tag.get_by_id"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *gormTagRepository) GetByUserID(userID uint) ([]models.Tag, error) {
+	r.logger.Debug("repo.tag.get_by_user_id",
+		slog.String("op", "repo.tag.get_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var tags []models.Tag
+	if err := r.db.Where("user_id = ?", userID).Order("name").Find(&tags).Error; err != nil {
+		r.logger.Error("repo.tag.get_by_user_id failed",
+			slog.String("op", "repo.tag.get_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *gormTagRepository) GetByIDs(userID uint, ids []uint) ([]models.Tag, error) {
+	r.logger.Debug("repo.tag.get_by_ids",
+		slog.String("op", "repo.tag.get_by_ids"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(ids)),
+	)
+	var tags []models.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	if err := r.db.Where("user_id = ? AND id IN ?", userID, ids).Find(&tags).Error; err != nil {
+		r.logger.Error("repo.tag.get_by_ids failed",
+			slog.String("op", "repo.tag.get_by_ids"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *gormTagRepository) Create(tag *models.Tag) error {
+	if tag == nil {
+		return errTagNil
+	}
+	r.logger.Debug("repo.tag.create",
+		slog.String("op", "repo.tag.create"),
+		slog.Uint64("user_id", uint64(tag.UserID)),
+		slog.String("name", tag.Name),
+	)
+	if err := r.db.Create(tag).Error; err != nil {
+		r.logger.Error("repo.tag.create failed",
+			slog.String("op", "repo.tag.create"),
+			slog.Uint64("user_id", uint64(tag.UserID)),
+			slog.String("name", tag.Name),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormTagRepository) Update(tag *models.Tag) error {
+	if tag == nil {
+		return errTagNil
+	}
+	r.logger.Debug("repo.tag.update",
+		slog.String("op", "repo.tag.update"),
+		slog.Uint64("id", uint64(tag.ID)),
+	)
+	if err := r.db.Save(tag).Error; err != nil {
+		r.logger.Error("repo.tag.update failed",
+			slog.String("op", "repo.tag.update"),
+			slog.Uint64("id", uint64(tag.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormTagRepository) Delete(id uint) error {
+	r.logger.Debug("repo.tag.delete",
+		slog.String("op", "repo.tag.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM expense_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM recurring_expense_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Tag{}, id).Error
+	})
+	if err != nil {
+		r.logger.Error("repo.tag.delete failed",
+			slog.String("op", "repo.tag.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
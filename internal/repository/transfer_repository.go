@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var errTransferNil error = errors.New("transfer is nil")
+
+type TransferRepository interface {
+	GetByID(id uint) (*models.Transfer, error)
+	// GetByUserID возвращает переводы пользователя, при accountID != nil только по этому счету
+	GetByUserID(userID uint, accountID *uint) ([]models.Transfer, error)
+	Create(transfer *models.Transfer) error
+	Delete(id uint) error
+}
+
+type gormTransferRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewTransferRepository(db *gorm.DB, logger *slog.Logger) TransferRepository {
+	return &gormTransferRepository{db: db, logger: logger}
+}
+
+func (r *gormTransferRepository) GetByID(id uint) (*models.Transfer, error) {
+	r.logger.Debug("repo.transfer.get_by_id",
+		slog.String("op", "repo.transfer.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var transfer models.Transfer
+	if err := r.db.Preload("FromAccount").Preload("ToAccount").First(&transfer, id).Error; err != nil {
+		r.logger.Error("repo.transfer.get_by_id failed",
+			slog.String("op", "repo.transfer.get_by_id"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *gormTransferRepository) GetByUserID(userID uint, accountID *uint) ([]models.Transfer, error) {
+	r.logger.Debug("repo.transfer.get_by_user_id",
+		slog.String("op", "repo.transfer.get_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	var transfers []models.Transfer
+	query := r.db.Preload("FromAccount").Preload("ToAccount").Where("user_id = ?", userID)
+	if accountID != nil {
+		query = query.Where("from_account_id = ? OR to_account_id = ?", *accountID, *accountID)
+	}
+
+	if err := query.Order("date DESC").Find(&transfers).Error; err != nil {
+		r.logger.Error("repo.transfer.get_by_user_id failed",
+			slog.String("op", "repo.transfer.get_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (r *gormTransferRepository) Create(transfer *models.Transfer) error {
+	if transfer == nil {
+		return errTransferNil
+	}
+
+	r.logger.Debug("repo.transfer.create",
+		slog.String("op", "repo.transfer.create"),
+		slog.Uint64("user_id", uint64(transfer.UserID)),
+		slog.Uint64("from_account_id", uint64(transfer.FromAccountID)),
+		slog.Uint64("to_account_id", uint64(transfer.ToAccountID)),
+	)
+
+	if err := r.db.Omit("FromAccount", "ToAccount").Create(transfer).Error; err != nil {
+		r.logger.Error("repo.transfer.create failed",
+			slog.String("op", "repo.transfer.create"),
+			slog.Uint64("user_id", uint64(transfer.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormTransferRepository) Delete(id uint) error {
+	r.logger.Debug("repo.transfer.delete",
+		slog.String("op", "repo.transfer.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Delete(&models.Transfer{}, id).Error; err != nil {
+		r.logger.Error("repo.transfer.delete failed",
+			slog.String("op", "repo.transfer.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
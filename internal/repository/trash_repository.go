@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// trashItemsSQL все мягко удаленные записи, которые попадают в корзину, в виде models.TrashItem
+const trashItemsSQL = `
+	SELECT 'expense' AS type, e.id, e.user_id,
+		COALESCE(NULLIF(e.description, ''), c.name, '') AS title, e.amount, e.deleted_at
+	FROM expenses e
+	LEFT JOIN categories c ON c.id = e.category_id
+	WHERE e.deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'category', id, user_id, name, NULL::numeric, deleted_at
+	FROM categories
+	WHERE deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'budget', id, user_id, to_char(make_date(year, month, 1), 'MM.YYYY'), amount, deleted_at
+	FROM budgets
+	WHERE deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'recurring_expense', r.id, r.user_id,
+		COALESCE(NULLIF(r.description, ''), c.name, ''), r.amount, r.deleted_at
+	FROM recurring_expenses r
+	LEFT JOIN categories c ON c.id = r.category_id
+	WHERE r.deleted_at IS NOT NULL
+`
+
+// trashTables таблицы записей корзины по видам
+var trashTables = map[models.TrashItemType]string{
+	models.TrashItemExpense:          "expenses",
+	models.TrashItemCategory:         "categories",
+	models.TrashItemBudget:           "budgets",
+	models.TrashItemRecurringExpense: "recurring_expenses",
+}
+
+// trashPurgeStatements удаление зависимых записей перед удалением самой записи корзины, по порядку
+var trashPurgeStatements = map[models.TrashItemType][]string{
+	models.TrashItemExpense: {
+		"DELETE FROM expense_tags WHERE expense_id = @id",
+		"DELETE FROM expense_splits WHERE expense_id = @id",
+		"DELETE FROM attachments WHERE expense_id = @id",
+	},
+	// Правила и лимиты бюджетов по категории без нее не нужны, а удаленные подкатегории становятся корневыми
+	models.TrashItemCategory: {
+		"DELETE FROM category_rules WHERE category_id = @id",
+		"DELETE FROM budget_categories WHERE category_id = @id",
+		"UPDATE categories SET parent_id = NULL WHERE parent_id = @id",
+	},
+	models.TrashItemBudget: {
+		"DELETE FROM budget_categories WHERE budget_id = @id",
+	},
+	models.TrashItemRecurringExpense: {
+		"DELETE FROM recurring_expense_tags WHERE recurring_expense_id = @id",
+	},
+}
+
+type TrashRepository interface {
+	// List возвращает удаленные записи пользователя, сначала удаленные последними.
+	// Пустой itemType означает записи всех видов.
+	List(userID uint, itemType models.TrashItemType) ([]models.TrashItem, error)
+	// GetItem возвращает удаленную запись, gorm.ErrRecordNotFound — если такой записи нет в корзине
+	GetItem(itemType models.TrashItemType, id uint) (*models.TrashItem, error)
+	// ListExpired возвращает записи всех пользователей, удаленные раньше before, не больше limit.
+	// Категории идут последними, чтобы к их удалению ссылавшиеся на них записи уже были удалены.
+	ListExpired(before time.Time, limit int) ([]models.TrashItem, error)
+	// GetExpense возвращает удаленный расход с его частями
+	GetExpense(id uint) (*models.Expense, error)
+	GetCategory(id uint) (*models.Category, error)
+	GetBudget(id uint) (*models.Budget, error)
+	GetRecurringExpense(id uint) (*models.RecurringExpense, error)
+	RestoreExpense(id uint) error
+	// RestoreCategory восстанавливает категорию с родителем parentID и правила категоризации,
+	// удаленные вместе с ней
+	RestoreCategory(id uint, parentID *uint) error
+	RestoreBudget(id uint) error
+	// RestoreRecurringExpense восстанавливает регулярный расход со следующей датой nextDate
+	RestoreRecurringExpense(id uint, nextDate time.Time) error
+	// CountCategoryReferences возвращает количество расходов, частей расходов, доходов и регулярных расходов,
+	// в том числе удаленных, которые ссылаются на категорию
+	CountCategoryReferences(id uint) (int64, error)
+	// Purge безвозвратно удаляет запись корзины вместе с зависимыми записями в одной транзакции.
+	// Файлы вложений расхода к этому моменту должны быть уже удалены из хранилища.
+	Purge(itemType models.TrashItemType, id uint) error
+}
+
+type gormTrashRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewTrashRepository(db *gorm.DB, logger *slog.Logger) TrashRepository {
+	return &gormTrashRepository{db: db, logger: logger}
+}
+
+func (r *gormTrashRepository) items() *gorm.DB {
+	return r.db.Table("(?) AS trash", r.db.Raw(trashItemsSQL))
+}
+
+func (r *gormTrashRepository) List(userID uint, itemType models.TrashItemType) ([]models.TrashItem, error) {
+	r.logger.Debug("repo.trash.list",
+		slog.String("op", "repo.trash.list"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("type", string(itemType)),
+	)
+	query := r.items().Where("user_id = ?", userID)
+	if itemType != "" {
+		query = query.Where("type = ?", itemType)
+	}
+	var items []models.TrashItem
+	if err := query.Order("deleted_at DESC, id DESC").Find(&items).Error; err != nil {
+		r.logger.Error("repo.trash.list failed",
+			slog.String("op", "repo.trash.list"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *gormTrashRepository) GetItem(itemType models.TrashItemType, id uint) (*models.TrashItem, error) {
+	r.logger.Debug("repo.trash.get_item",
+		slog.String("op", "repo.trash.get_item"),
+		slog.String("type", string(itemType)),
+		slog.Uint64("id", uint64(id)),
+	)
+	var item models.TrashItem
+	if err := r.items().Where("type = ? AND id = ?", itemType, id).Take(&item).Error; err != nil {
+		r.logger.Error("repo.trash.get_item failed",
+			slog.String("op", "repo.trash.get_item"),
+			slog.String("type", string(itemType)),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *gormTrashRepository) ListExpired(before time.Time, limit int) ([]models.TrashItem, error) {
+	r.logger.Debug("repo.trash.list_expired",
+		slog.String("op", "repo.trash.list_expired"),
+		slog.Time("before", before),
+	)
+	var items []models.TrashItem
+	err := r.items().
+		Where("deleted_at < ?", before).
+		Order("type = 'category', deleted_at, id").
+		Limit(limit).
+		Find(&items).Error
+	if err != nil {
+		r.logger.Error("repo.trash.list_expired failed",
+			slog.String("op", "repo.trash.list_expired"),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *gormTrashRepository) GetExpense(id uint) (*models.Expense, error) {
+	var expense models.Expense
+	err := r.db.Unscoped().
+		Preload("Splits", "deleted_at IS NULL").
+		Where("deleted_at IS NOT NULL").
+		First(&expense, id).Error
+	if err != nil {
+		return nil, r.getFailed("expense", id, err)
+	}
+	return &expense, nil
+}
+
+func (r *gormTrashRepository) GetCategory(id uint) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&category, id).Error; err != nil {
+		return nil, r.getFailed("category", id, err)
+	}
+	return &category, nil
+}
+
+func (r *gormTrashRepository) GetBudget(id uint) (*models.Budget, error) {
+	var budget models.Budget
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&budget, id).Error; err != nil {
+		return nil, r.getFailed("budget", id, err)
+	}
+	return &budget, nil
+}
+
+func (r *gormTrashRepository) GetRecurringExpense(id uint) (*models.RecurringExpense, error) {
+	var recurringExpense models.RecurringExpense
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&recurringExpense, id).Error; err != nil {
+		return nil, r.getFailed("recurring_expense", id, err)
+	}
+	return &recurringExpense, nil
+}
+
+func (r *gormTrashRepository) getFailed(entity string, id uint, err error) error {
+	r.logger.Error("repo.trash.get failed",
+		slog.String("op", "repo.trash.get_"+entity),
+		slog.Uint64("id", uint64(id)),
+		slog.String("error", err.Error()),
+	)
+	return err
+}
+
+func (r *gormTrashRepository) RestoreExpense(id uint) error {
+	return r.restore(&models.Expense{}, id, nil)
+}
+
+func (r *gormTrashRepository) RestoreCategory(id uint, parentID *uint) error {
+	category, err := r.GetCategory(id)
+	if err != nil {
+		return err
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Правила, удаленные вместе с категорией, получили ту же отметку времени удаления
+		err := tx.Unscoped().Model(&models.CategoryRule{}).
+			Where("category_id = ? AND deleted_at = ?", id, category.DeletedAt.Time).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		return (&gormTrashRepository{db: tx, logger: r.logger}).
+			restore(&models.Category{}, id, map[string]interface{}{"parent_id": parentID})
+	})
+}
+
+func (r *gormTrashRepository) RestoreBudget(id uint) error {
+	return r.restore(&models.Budget{}, id, nil)
+}
+
+func (r *gormTrashRepository) RestoreRecurringExpense(id uint, nextDate time.Time) error {
+	return r.restore(&models.RecurringExpense{}, id, map[string]interface{}{"next_date": nextDate})
+}
+
+// restore снимает отметку удаления с записи model и записывает в нее значения changes
+func (r *gormTrashRepository) restore(model interface{}, id uint, changes map[string]interface{}) error {
+	r.logger.Debug("repo.trash.restore",
+		slog.String("op", "repo.trash.restore"),
+		slog.String("model", fmt.Sprintf("%T", model)),
+		slog.Uint64("id", uint64(id)),
+	)
+	values := map[string]interface{}{"deleted_at": nil}
+	for column, value := range changes {
+		values[column] = value
+	}
+	result := r.db.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).Updates(values)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = gorm.ErrRecordNotFound
+	}
+	if result.Error != nil {
+		r.logger.Error("repo.trash.restore failed",
+			slog.String("op", "repo.trash.restore"),
+			slog.String("model", fmt.Sprintf("%T", model)),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", result.Error.Error()),
+		)
+		return result.Error
+	}
+	return nil
+}
+
+func (r *gormTrashRepository) CountCategoryReferences(id uint) (int64, error) {
+	r.logger.Debug("repo.trash.count_category_references",
+		slog.String("op", "repo.trash.count_category_references"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var usage struct {
+		Count int64
+	}
+	query := `
+		SELECT (
+			(SELECT COUNT(*) FROM expenses WHERE category_id = @id)
+			+ (SELECT COUNT(*) FROM expense_splits WHERE category_id = @id)
+			+ (SELECT COUNT(*) FROM incomes WHERE category_id = @id)
+			+ (SELECT COUNT(*) FROM recurring_expenses WHERE category_id = @id)
+			+ (SELECT COUNT(*) FROM installment_plans WHERE category_id = @id)
+		) AS count
+	`
+	if err := r.db.Raw(query, map[string]interface{}{"id": id}).Scan(&usage).Error; err != nil {
+		r.logger.Error("repo.trash.count_category_references failed",
+			slog.String("op", "repo.trash.count_category_references"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return 0, err
+	}
+	return usage.Count, nil
+}
+
+func (r *gormTrashRepository) Purge(itemType models.TrashItemType, id uint) error {
+	r.logger.Debug("repo.trash.purge",
+		slog.String("op", "repo.trash.purge"),
+		slog.String("type", string(itemType)),
+		slog.Uint64("id", uint64(id)),
+	)
+	table, ok := trashTables[itemType]
+	if !ok {
+		return fmt.Errorf("неизвестный вид записи корзины %q", itemType)
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Блокировка строки не дает восстановить запись, пока удаляются ее зависимые записи
+		var ids []uint
+		err := tx.Raw("SELECT id FROM "+table+" WHERE id = ? AND deleted_at IS NOT NULL FOR UPDATE", id).
+			Scan(&ids).Error
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		statements := append(append([]string{}, trashPurgeStatements[itemType]...), "DELETE FROM "+table+" WHERE id = @id")
+		for _, statement := range statements {
+			if err := tx.Exec(statement, map[string]interface{}{"id": id}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("repo.trash.purge failed",
+			slog.String("op", "repo.trash.purge"),
+			slog.String("type", string(itemType)),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
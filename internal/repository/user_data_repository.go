@@ -0,0 +1,651 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserDataRepository операции над всеми данными пользователя сразу: выгрузка и безвозвратное удаление
+type UserDataRepository interface {
+	// Load загружает все записи пользователя, кроме удаленных
+	Load(userID uint) (*models.UserData, error)
+	// Purge безвозвратно удаляет пользователя и все его записи, включая мягко удаленные, в одной транзакции.
+	// Возвращает ключи файлов удаленных вложений: файлы удаляет вызывающий после фиксации транзакции.
+	Purge(userID uint) ([]string, error)
+	// Restore создает записи архива у пользователя в одной транзакции с новыми идентификаторами.
+	// Категории, счета и теги с совпадающими названиями не дублируются, а сопоставляются с существующими.
+	// Записи, которые у пользователя уже есть, пропускаются, поэтому повторное восстановление того же
+	// архива ничего не создает. Ссылки внутри архива должны быть проверены заранее.
+	Restore(userID uint, archive *models.UserDataArchive) (*models.UserDataImportResult, error)
+}
+
+type gormUserDataRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewUserDataRepository(db *gorm.DB, logger *slog.Logger) UserDataRepository {
+	return &gormUserDataRepository{db: db, logger: logger}
+}
+
+func (r *gormUserDataRepository) Load(userID uint) (*models.UserData, error) {
+	r.logger.Debug("repo.user_data.load",
+		slog.String("op", "repo.user_data.load"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	data := &models.UserData{User: &models.User{}}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(data.User, userID).Error; err != nil {
+			return err
+		}
+		owned := func(query *gorm.DB, dest interface{}) error {
+			return query.Where("user_id = ?", userID).Order("id").Find(dest).Error
+		}
+		if err := owned(tx, &data.Categories); err != nil {
+			return err
+		}
+		if err := owned(tx, &data.Accounts); err != nil {
+			return err
+		}
+		if err := owned(tx, &data.Tags); err != nil {
+			return err
+		}
+		if err := owned(tx, &data.CategoryRules); err != nil {
+			return err
+		}
+		if err := owned(tx.Preload("Tags").Preload("Splits"), &data.Expenses); err != nil {
+			return err
+		}
+		if err := owned(tx, &data.Incomes); err != nil {
+			return err
+		}
+		if err := owned(tx, &data.Transfers); err != nil {
+			return err
+		}
+		if err := owned(tx.Preload("Categories"), &data.Budgets); err != nil {
+			return err
+		}
+		if err := owned(tx.Preload("Tags"), &data.RecurringExpenses); err != nil {
+			return err
+		}
+		if err := owned(tx, &data.InstallmentPlans); err != nil {
+			return err
+		}
+		return owned(tx, &data.ActivityHistory)
+	})
+	if err != nil {
+		r.logger.Error("repo.user_data.load failed",
+			slog.String("op", "repo.user_data.load"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *gormUserDataRepository) Purge(userID uint) ([]string, error) {
+	r.logger.Debug("repo.user_data.purge",
+		slog.String("op", "repo.user_data.purge"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	// Сначала связи и зависимые записи, затем категории и сам пользователь
+	statements := []string{
+		"DELETE FROM expense_tags WHERE expense_id IN (SELECT id FROM expenses WHERE user_id = @user_id)",
+		"DELETE FROM expense_splits WHERE expense_id IN (SELECT id FROM expenses WHERE user_id = @user_id)",
+		"DELETE FROM recurring_expense_tags WHERE recurring_expense_id IN (SELECT id FROM recurring_expenses WHERE user_id = @user_id)",
+		"DELETE FROM budget_categories WHERE budget_id IN (SELECT id FROM budgets WHERE user_id = @user_id)",
+		"DELETE FROM expenses WHERE user_id = @user_id",
+		"DELETE FROM incomes WHERE user_id = @user_id",
+		"DELETE FROM transfers WHERE user_id = @user_id",
+		"DELETE FROM recurring_expenses WHERE user_id = @user_id",
+		"DELETE FROM installment_plans WHERE user_id = @user_id",
+		"DELETE FROM budgets WHERE user_id = @user_id",
+		"DELETE FROM tags WHERE user_id = @user_id",
+		"DELETE FROM accounts WHERE user_id = @user_id",
+		"DELETE FROM activity_histories WHERE user_id = @user_id",
+		"DELETE FROM category_rules WHERE user_id = @user_id",
+		"DELETE FROM exchange_rates WHERE user_id = @user_id",
+		"UPDATE categories SET parent_id = NULL WHERE user_id = @user_id",
+		"DELETE FROM categories WHERE user_id = @user_id",
+		"DELETE FROM users WHERE id = @user_id",
+	}
+
+	var storageKeys []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw("DELETE FROM attachments WHERE user_id = ? RETURNING storage_key", userID).
+			Scan(&storageKeys).Error
+		if err != nil {
+			return err
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement, map[string]interface{}{"user_id": userID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("repo.user_data.purge failed",
+			slog.String("op", "repo.user_data.purge"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return storageKeys, nil
+}
+
+// restoreBatchSize размер пакета вставки расходов и доходов при восстановлении
+const restoreBatchSize = 500
+
+func (r *gormUserDataRepository) Restore(userID uint, archive *models.UserDataArchive) (*models.UserDataImportResult, error) {
+	r.logger.Debug("repo.user_data.restore",
+		slog.String("op", "repo.user_data.restore"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	result := &models.UserDataImportResult{Skipped: []string{}}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		categoryIDs, err := restoreCategories(tx, userID, archive.Categories, result)
+		if err != nil {
+			return err
+		}
+		accountIDs, err := restoreAccounts(tx, userID, archive.Accounts, result)
+		if err != nil {
+			return err
+		}
+		tags, err := restoreTags(tx, userID, archive.Tags, result)
+		if err != nil {
+			return err
+		}
+
+		mapAccount := func(id *uint) *uint {
+			if id == nil {
+				return nil
+			}
+			mapped := accountIDs[*id]
+			return &mapped
+		}
+		mapTags := func(ids []uint) []models.Tag {
+			mapped := make([]models.Tag, 0, len(ids))
+			for _, id := range ids {
+				mapped = append(mapped, tags[id])
+			}
+			return mapped
+		}
+
+		if err := restoreCategoryRules(tx, userID, archive.CategoryRules, categoryIDs, result); err != nil {
+			return err
+		}
+
+		// Планы рассрочки восстанавливаются до расходов, чтобы платежи сохранили ссылку на свой план.
+		// Совпавший план не создается, платежи архива ссылаются на существующий.
+		var existingPlans []models.InstallmentPlan
+		if err := tx.Where("user_id = ?", userID).Find(&existingPlans).Error; err != nil {
+			return err
+		}
+		plansByKey := make(map[string][]uint, len(existingPlans))
+		for _, plan := range existingPlans {
+			key := installmentPlanRestoreKey(&plan)
+			plansByKey[key] = append(plansByKey[key], plan.ID)
+		}
+
+		planIDs := make(map[uint]uint, len(archive.InstallmentPlans))
+		for _, p := range archive.InstallmentPlans {
+			plan := models.InstallmentPlan{
+				UserID:       userID,
+				CategoryID:   categoryIDs[p.CategoryID],
+				AccountID:    mapAccount(p.AccountID),
+				Description:  p.Description,
+				TotalAmount:  p.TotalAmount,
+				Payments:     p.Payments,
+				StartDate:    p.StartDate,
+				PaymentsMade: p.PaymentsMade,
+				NextDate:     p.NextDate,
+			}
+			key := installmentPlanRestoreKey(&plan)
+			if ids := plansByKey[key]; len(ids) > 0 {
+				planIDs[p.ID] = ids[0]
+				plansByKey[key] = ids[1:]
+				result.Duplicates++
+				continue
+			}
+			if err := tx.Create(&plan).Error; err != nil {
+				return err
+			}
+			planIDs[p.ID] = plan.ID
+			result.InstallmentPlans++
+		}
+		mapPlan := func(id *uint) *uint {
+			if id == nil {
+				return nil
+			}
+			mapped := planIDs[*id]
+			return &mapped
+		}
+
+		if len(archive.Expenses) > 0 {
+			existing, err := loadRestoreKeys(tx, userID, expenseRestoreKey)
+			if err != nil {
+				return err
+			}
+			expenses := make([]models.Expense, 0, len(archive.Expenses))
+			for _, e := range archive.Expenses {
+				splits := make([]models.ExpenseSplit, 0, len(e.Splits))
+				for _, split := range e.Splits {
+					splits = append(splits, models.ExpenseSplit{
+						CategoryID:     categoryIDs[split.CategoryID],
+						Amount:         split.Amount,
+						OriginalAmount: split.OriginalAmount,
+						Description:    split.Description,
+					})
+				}
+				expense := models.Expense{
+					UserID:         userID,
+					AccountID:      mapAccount(e.AccountID),
+					CategoryID:     categoryIDs[e.CategoryID],
+					Amount:         e.Amount,
+					Currency:       e.Currency,
+					OriginalAmount: e.OriginalAmount,
+					ExchangeRate:   e.ExchangeRate,
+					Description:    e.Description,
+					Date:           e.Date,
+					ExternalID:     e.ExternalID,
+					Tags:           mapTags(e.TagIDs),
+					Splits:         splits,
+
+					InstallmentPlanID: mapPlan(e.InstallmentPlanID),
+				}
+				if existing.take(expenseRestoreKey(&expense)) {
+					result.Duplicates++
+					continue
+				}
+				expenses = append(expenses, expense)
+			}
+			if len(expenses) > 0 {
+				if err := tx.CreateInBatches(&expenses, restoreBatchSize).Error; err != nil {
+					return err
+				}
+			}
+			result.Expenses = len(expenses)
+		}
+
+		if len(archive.Incomes) > 0 {
+			existing, err := loadRestoreKeys(tx, userID, incomeRestoreKey)
+			if err != nil {
+				return err
+			}
+			incomes := make([]models.Income, 0, len(archive.Incomes))
+			for _, i := range archive.Incomes {
+				income := models.Income{
+					UserID:      userID,
+					AccountID:   mapAccount(i.AccountID),
+					CategoryID:  categoryIDs[i.CategoryID],
+					Amount:      i.Amount,
+					Description: i.Description,
+					Date:        i.Date,
+				}
+				if existing.take(incomeRestoreKey(&income)) {
+					result.Duplicates++
+					continue
+				}
+				incomes = append(incomes, income)
+			}
+			if len(incomes) > 0 {
+				if err := tx.CreateInBatches(&incomes, restoreBatchSize).Error; err != nil {
+					return err
+				}
+			}
+			result.Incomes = len(incomes)
+		}
+
+		existingTransfers, err := loadRestoreKeys(tx, userID, transferRestoreKey)
+		if err != nil {
+			return err
+		}
+		for _, t := range archive.Transfers {
+			transfer := models.Transfer{
+				UserID:        userID,
+				FromAccountID: accountIDs[t.FromAccountID],
+				ToAccountID:   accountIDs[t.ToAccountID],
+				Amount:        t.Amount,
+				Description:   t.Description,
+				Date:          t.Date,
+			}
+			if existingTransfers.take(transferRestoreKey(&transfer)) {
+				result.Duplicates++
+				continue
+			}
+			if err := tx.Create(&transfer).Error; err != nil {
+				return err
+			}
+			result.Transfers++
+		}
+
+		if err := restoreBudgets(tx, userID, archive.Budgets, categoryIDs, result); err != nil {
+			return err
+		}
+
+		existingRecurring, err := loadRestoreKeys(tx, userID, recurringExpenseRestoreKey)
+		if err != nil {
+			return err
+		}
+		for _, re := range archive.RecurringExpenses {
+			recurring := models.RecurringExpense{
+				UserID:      userID,
+				CategoryID:  categoryIDs[re.CategoryID],
+				Amount:      re.Amount,
+				Description: re.Description,
+				Type:        re.Type,
+				DayOfMonth:  re.DayOfMonth,
+				DayOfWeek:   re.DayOfWeek,
+				IsActive:    re.IsActive,
+				NextDate:    re.NextDate,
+				Tags:        mapTags(re.TagIDs),
+			}
+			if existingRecurring.take(recurringExpenseRestoreKey(&recurring)) {
+				result.Duplicates++
+				continue
+			}
+			if err := tx.Create(&recurring).Error; err != nil {
+				return err
+			}
+			result.RecurringExpenses++
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("repo.user_data.restore failed",
+			slog.String("op", "repo.user_data.restore"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// restoreKeys число записей пользователя с каждым ключом восстановления. Каждая существующая запись
+// поглощает одну запись архива с тем же ключом, поэтому одинаковые записи внутри архива сохраняются.
+type restoreKeys map[string]int
+
+// take сообщает, есть ли еще не сопоставленная запись с ключом key, и сопоставляет ее
+func (k restoreKeys) take(key string) bool {
+	if k[key] == 0 {
+		return false
+	}
+	k[key]--
+	return true
+}
+
+// loadRestoreKeys загружает записи пользователя и считает их ключи восстановления
+func loadRestoreKeys[T any](tx *gorm.DB, userID uint, key func(*T) string) (restoreKeys, error) {
+	var records []T
+	if err := tx.Where("user_id = ?", userID).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	keys := make(restoreKeys, len(records))
+	for i := range records {
+		keys[key(&records[i])]++
+	}
+	return keys, nil
+}
+
+// restoreTime приводит время к точности хранения в БД, чтобы время из архива совпадало с сохраненным
+func restoreTime(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
+
+func restoreAccountKey(accountID *uint) uint {
+	if accountID == nil {
+		return 0
+	}
+	return *accountID
+}
+
+// expenseRestoreKey ключ расхода: идентификатор операции выписки, а без него — дата, суммы, категория,
+// счет и описание
+func expenseRestoreKey(e *models.Expense) string {
+	if e.ExternalID != nil {
+		return "external|" + *e.ExternalID
+	}
+	return fmt.Sprintf("%s|%d|%d|%s|%d|%d|%s", restoreTime(e.Date), e.Amount, e.OriginalAmount, e.Currency,
+		e.CategoryID, restoreAccountKey(e.AccountID), e.Description)
+}
+
+func incomeRestoreKey(i *models.Income) string {
+	return fmt.Sprintf("%s|%d|%d|%d|%s", restoreTime(i.Date), i.Amount, i.CategoryID, restoreAccountKey(i.AccountID), i.Description)
+}
+
+func transferRestoreKey(t *models.Transfer) string {
+	return fmt.Sprintf("%s|%d|%d|%d|%s", restoreTime(t.Date), t.Amount, t.FromAccountID, t.ToAccountID, t.Description)
+}
+
+func recurringExpenseRestoreKey(r *models.RecurringExpense) string {
+	day := func(value *int) int {
+		if value == nil {
+			return -1
+		}
+		return *value
+	}
+	return fmt.Sprintf("%s|%d|%d|%d|%d|%s", r.Type, r.Amount, r.CategoryID, day(r.DayOfMonth), day(r.DayOfWeek), r.Description)
+}
+
+func installmentPlanRestoreKey(p *models.InstallmentPlan) string {
+	return fmt.Sprintf("%s|%d|%d|%d|%s", restoreTime(p.StartDate), p.TotalAmount, p.Payments, p.CategoryID, p.Description)
+}
+
+// restoreCategoryRules создает правила категоризации, правило с тем же названием у пользователя уже есть — пропускается
+func restoreCategoryRules(tx *gorm.DB, userID uint, rules []models.ArchiveCategoryRule, categoryIDs map[uint]uint, result *models.UserDataImportResult) error {
+	existing, err := loadRestoreKeys(tx, userID, func(rule *models.CategoryRule) string {
+		return strings.ToLower(strings.TrimSpace(rule.Name))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rules {
+		if existing.take(strings.ToLower(strings.TrimSpace(r.Name))) {
+			result.Duplicates++
+			continue
+		}
+		rule := models.CategoryRule{
+			UserID:              userID,
+			Name:                r.Name,
+			Priority:            r.Priority,
+			IsActive:            r.IsActive,
+			DescriptionContains: r.DescriptionContains,
+			DescriptionRegex:    r.DescriptionRegex,
+			MinAmount:           r.MinAmount,
+			MaxAmount:           r.MaxAmount,
+			Weekdays:            r.Weekdays,
+			CategoryID:          categoryIDs[r.CategoryID],
+			SetDescription:      r.SetDescription,
+		}
+		if err := tx.Omit("Category").Create(&rule).Error; err != nil {
+			return err
+		}
+		result.CategoryRules++
+	}
+	return nil
+}
+
+// restoreCategories создает категории архива, начиная с корневых, и возвращает соответствие идентификаторов.
+// Категория с тем же названием, типом и родителем у пользователя уже есть — используется она.
+func restoreCategories(tx *gorm.DB, userID uint, categories []models.ArchiveCategory, result *models.UserDataImportResult) (map[uint]uint, error) {
+	var existing []models.Category
+	if err := tx.Where("user_id = ?", userID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	categoryKey := func(name string, categoryType models.CategoryType, parentID *uint) string {
+		parent := uint(0)
+		if parentID != nil {
+			parent = *parentID
+		}
+		return fmt.Sprintf("%s|%s|%d", strings.ToLower(strings.TrimSpace(name)), categoryType, parent)
+	}
+	byKey := make(map[string]uint, len(existing))
+	for _, c := range existing {
+		byKey[categoryKey(c.Name, c.Type, c.ParentID)] = c.ID
+	}
+
+	ids := make(map[uint]uint, len(categories))
+	pending := categories
+	for len(pending) > 0 {
+		var next []models.ArchiveCategory
+		for _, c := range pending {
+			var parentID *uint
+			if c.ParentID != nil {
+				mapped, ok := ids[*c.ParentID]
+				if !ok {
+					next = append(next, c)
+					continue
+				}
+				parentID = &mapped
+			}
+
+			categoryType := c.Type
+			if categoryType == "" {
+				categoryType = models.CategoryTypeExpense
+			}
+			key := categoryKey(c.Name, categoryType, parentID)
+			if id, ok := byKey[key]; ok {
+				ids[c.ID] = id
+				result.Matched++
+				continue
+			}
+
+			category := models.Category{
+				UserID:    userID,
+				Name:      c.Name,
+				Color:     c.Color,
+				Icon:      c.Icon,
+				Type:      categoryType,
+				IsDefault: c.IsDefault,
+				ParentID:  parentID,
+			}
+			if err := tx.Create(&category).Error; err != nil {
+				return nil, err
+			}
+			ids[c.ID] = category.ID
+			byKey[key] = category.ID
+			result.Categories++
+		}
+		if len(next) == len(pending) {
+			return nil, fmt.Errorf("категория %d ссылается на отсутствующую родительскую категорию", next[0].ID)
+		}
+		pending = next
+	}
+	return ids, nil
+}
+
+// restoreAccounts создает счета архива, счет с тем же названием сопоставляется с существующим
+func restoreAccounts(tx *gorm.DB, userID uint, accounts []models.ArchiveAccount, result *models.UserDataImportResult) (map[uint]uint, error) {
+	var existing []models.Account
+	if err := tx.Where("user_id = ?", userID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]uint, len(existing))
+	for _, a := range existing {
+		byName[strings.ToLower(strings.TrimSpace(a.Name))] = a.ID
+	}
+
+	ids := make(map[uint]uint, len(accounts))
+	for _, a := range accounts {
+		name := strings.ToLower(strings.TrimSpace(a.Name))
+		if id, ok := byName[name]; ok {
+			ids[a.ID] = id
+			result.Matched++
+			continue
+		}
+
+		account := models.Account{
+			UserID:         userID,
+			Name:           a.Name,
+			Type:           a.Type,
+			OpeningBalance: a.OpeningBalance,
+		}
+		if account.Type == "" {
+			account.Type = models.AccountTypeCash
+		}
+		if err := tx.Create(&account).Error; err != nil {
+			return nil, err
+		}
+		ids[a.ID] = account.ID
+		byName[name] = account.ID
+		result.Accounts++
+	}
+	return ids, nil
+}
+
+// restoreTags создает теги архива, тег с тем же названием без учета регистра сопоставляется с существующим
+func restoreTags(tx *gorm.DB, userID uint, tags []models.ArchiveTag, result *models.UserDataImportResult) (map[uint]models.Tag, error) {
+	var existing []models.Tag
+	if err := tx.Where("user_id = ?", userID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]models.Tag, len(existing))
+	for _, t := range existing {
+		byName[strings.ToLower(t.Name)] = t
+	}
+
+	mapped := make(map[uint]models.Tag, len(tags))
+	for _, t := range tags {
+		name := strings.ToLower(strings.TrimSpace(t.Name))
+		if tag, ok := byName[name]; ok {
+			mapped[t.ID] = tag
+			result.Matched++
+			continue
+		}
+
+		tag := models.Tag{UserID: userID, Name: strings.TrimSpace(t.Name), Color: t.Color}
+		if err := tx.Create(&tag).Error; err != nil {
+			return nil, err
+		}
+		mapped[t.ID] = tag
+		byName[name] = tag
+		result.Tags++
+	}
+	return mapped, nil
+}
+
+// restoreBudgets создает бюджеты с лимитами по категориям. Пересечение периодов с бюджетами
+// пользователя проверяется заранее, при проверке архива.
+func restoreBudgets(tx *gorm.DB, userID uint, budgets []models.ArchiveBudget, categoryIDs map[uint]uint, result *models.UserDataImportResult) error {
+	for _, b := range budgets {
+		periodType := b.PeriodType
+		if periodType == "" {
+			periodType = models.BudgetPeriodMonthly
+		}
+
+		budget := models.Budget{
+			UserID:     userID,
+			Amount:     b.Amount,
+			Month:      b.Month,
+			Year:       b.Year,
+			Rollover:   b.Rollover,
+			PeriodType: periodType,
+			AnchorDate: b.AnchorDate,
+			EndDate:    b.EndDate,
+		}
+		for _, limit := range b.Categories {
+			budget.Categories = append(budget.Categories, models.BudgetCategory{
+				CategoryID: categoryIDs[limit.CategoryID],
+				Amount:     limit.Amount,
+			})
+		}
+		if err := tx.Create(&budget).Error; err != nil {
+			return err
+		}
+		result.Budgets++
+	}
+	return nil
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAccountNotFound  = errors.New("счет не найден")
+	ErrTransferNotFound = errors.New("перевод не найден")
+	ErrAccountInUse     = errors.New("по счету есть операции, перенесите или удалите их перед удалением счета")
+)
+
+type AccountService interface {
+	CreateAccount(userID uint, req models.CreateAccountRequest) (*models.Account, error)
+	GetAccountList(userID uint) ([]models.Account, error)
+	GetAccountByID(id uint) (*models.Account, error)
+	UpdateAccount(id uint, req models.UpdateAccountRequest) (*models.Account, error)
+	// DeleteAccount удаляет счет без операций. Если на счет ссылаются расходы, доходы, переводы
+	// или планы рассрочки, возвращается ErrAccountInUse
+	DeleteAccount(id uint) error
+
+	CreateTransfer(userID uint, req models.CreateTransferRequest) (*models.Transfer, error)
+	GetTransferList(userID uint, accountID *uint) ([]models.Transfer, error)
+	GetTransferByID(id uint) (*models.Transfer, error)
+	DeleteTransfer(id uint) error
+}
+
+type accountService struct {
+	accounts  repository.AccountRepository
+	transfers repository.TransferRepository
+	logger    *slog.Logger
+}
+
+func NewAccountService(accounts repository.AccountRepository, transfers repository.TransferRepository, logger *slog.Logger) AccountService {
+	return &accountService{
+		accounts:  accounts,
+		transfers: transfers,
+		logger:    logger,
+	}
+}
+
+func (s *accountService) CreateAccount(userID uint, req models.CreateAccountRequest) (*models.Account, error) {
+	if req.Name == "" {
+		return nil, errors.New("название счета не может быть пустым")
+	}
+
+	accountType := req.Type
+	if accountType == "" {
+		accountType = models.AccountTypeCash
+	}
+
+	account := &models.Account{
+		UserID:         userID,
+		Name:           req.Name,
+		Type:           accountType,
+		OpeningBalance: req.OpeningBalance,
+	}
+
+	if err := s.accounts.Create(account); err != nil {
+		s.logger.Error("account create failed",
+			slog.String("op", "create_account"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	account.Balance = account.OpeningBalance
+
+	s.logger.Info("account created",
+		slog.Uint64("account_id", uint64(account.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("type", string(account.Type)),
+	)
+
+	return account, nil
+}
+
+func (s *accountService) GetAccountList(userID uint) ([]models.Account, error) {
+	accounts, err := s.accounts.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list accounts",
+			slog.String("op", "list_accounts"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	for i := range accounts {
+		if err := s.fillBalance(&accounts[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.Info("accounts listed",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(accounts)),
+	)
+
+	return accounts, nil
+}
+
+func (s *accountService) GetAccountByID(id uint) (*models.Account, error) {
+	account, err := s.getAccount(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.fillBalance(account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (s *accountService) UpdateAccount(id uint, req models.UpdateAccountRequest) (*models.Account, error) {
+	account, err := s.getAccount(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, errors.New("название счета не может быть пустым")
+		}
+		account.Name = *req.Name
+	}
+	if req.Type != nil {
+		account.Type = *req.Type
+	}
+	if req.OpeningBalance != nil {
+		account.OpeningBalance = *req.OpeningBalance
+	}
+
+	if err := s.accounts.Update(account); err != nil {
+		s.logger.Error("account update failed",
+			slog.String("op", "update_account"),
+			slog.Uint64("account_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.fillBalance(account); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("account updated",
+		slog.Uint64("account_id", uint64(id)),
+	)
+
+	return account, nil
+}
+
+func (s *accountService) DeleteAccount(id uint) error {
+	if _, err := s.getAccount(id); err != nil {
+		return err
+	}
+
+	err := repository.RunInTransaction(func(tx repository.TxProvider) error {
+		accounts := s.accounts.WithTx(tx)
+
+		// Операции не должны ссылаться на удаленный счет, иначе они пропадут из остатков
+		count, err := accounts.CountTransactions(id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAccountInUse
+		}
+		return accounts.Delete(id)
+	})
+	if errors.Is(err, ErrAccountInUse) {
+		s.logger.Warn("account delete rejected",
+			slog.Uint64("account_id", uint64(id)),
+			slog.String("reason", err.Error()),
+		)
+		return err
+	}
+	if err != nil {
+		s.logger.Error("account delete failed",
+			slog.String("op", "delete_account"),
+			slog.Uint64("account_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("account deleted",
+		slog.Uint64("account_id", uint64(id)),
+	)
+
+	return nil
+}
+
+func (s *accountService) CreateTransfer(userID uint, req models.CreateTransferRequest) (*models.Transfer, error) {
+	if err := s.validateTransfer(userID, req); err != nil {
+		s.logger.Warn("transfer create validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Uint64("from_account_id", uint64(req.FromAccountID)),
+			slog.Uint64("to_account_id", uint64(req.ToAccountID)),
+			slog.String("amount", req.Amount.String()),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	transfer := &models.Transfer{
+		UserID:        userID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		Date:          req.Date,
+	}
+
+	if err := s.transfers.Create(transfer); err != nil {
+		s.logger.Error("transfer create failed",
+			slog.String("op", "create_transfer"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("transfer created",
+		slog.Uint64("transfer_id", uint64(transfer.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("from_account_id", uint64(transfer.FromAccountID)),
+		slog.Uint64("to_account_id", uint64(transfer.ToAccountID)),
+		slog.String("amount", transfer.Amount.String()),
+	)
+
+	return s.GetTransferByID(transfer.ID)
+}
+
+func (s *accountService) GetTransferList(userID uint, accountID *uint) ([]models.Transfer, error) {
+	transfers, err := s.transfers.GetByUserID(userID, accountID)
+	if err != nil {
+		s.logger.Error("failed to list transfers",
+			slog.String("op", "list_transfers"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("transfers listed",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(transfers)),
+	)
+
+	return transfers, nil
+}
+
+func (s *accountService) GetTransferByID(id uint) (*models.Transfer, error) {
+	transfer, err := s.transfers.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("transfer not found",
+				slog.Uint64("transfer_id", uint64(id)),
+			)
+			return nil, ErrTransferNotFound
+		}
+		s.logger.Error("failed to get transfer",
+			slog.String("op", "get_transfer_by_id"),
+			slog.Uint64("transfer_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (s *accountService) DeleteTransfer(id uint) error {
+	if _, err := s.GetTransferByID(id); err != nil {
+		return err
+	}
+
+	if err := s.transfers.Delete(id); err != nil {
+		s.logger.Error("transfer delete failed",
+			slog.String("op", "delete_transfer"),
+			slog.Uint64("transfer_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("transfer deleted",
+		slog.Uint64("transfer_id", uint64(id)),
+	)
+
+	return nil
+}
+
+func (s *accountService) getAccount(id uint) (*models.Account, error) {
+	account, err := s.accounts.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("account not found",
+				slog.Uint64("account_id", uint64(id)),
+			)
+			return nil, ErrAccountNotFound
+		}
+		s.logger.Error("failed to get account",
+			slog.String("op", "get_account_by_id"),
+			slog.Uint64("account_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return account, nil
+}
+
+// fillBalance вычисляет текущий остаток счета: начальный остаток плюс движение по операциям
+func (s *accountService) fillBalance(account *models.Account) error {
+	movement, err := s.accounts.GetNetMovement(account.ID)
+	if err != nil {
+		s.logger.Error("failed to calculate account balance",
+			slog.String("op", "account_balance"),
+			slog.Uint64("account_id", uint64(account.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	account.Balance = account.OpeningBalance + movement
+	return nil
+}
+
+func (s *accountService) validateTransfer(userID uint, req models.CreateTransferRequest) error {
+	if req.Amount <= 0 {
+		return errors.New("сумма должна быть больше нуля")
+	}
+	if req.FromAccountID == req.ToAccountID {
+		return errors.New("счета списания и зачисления должны различаться")
+	}
+
+	for _, accountID := range []uint{req.FromAccountID, req.ToAccountID} {
+		account, err := s.getAccount(accountID)
+		if err != nil {
+			return err
+		}
+		if account.UserID != userID {
+			return ErrAccountNotFound
+		}
+	}
+
+	return nil
+}
+
+// validateAccountOwner проверяет, что счет существует и принадлежит пользователю.
+// Используется сервисами расходов и доходов при привязке операции к счету.
+func validateAccountOwner(accounts repository.AccountRepository, userID, accountID uint) error {
+	account, err := accounts.GetByID(accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAccountNotFound
+		}
+		return errors.New("ошибка при проверке счета")
+	}
+	if account.UserID != userID {
+		return ErrAccountNotFound
+	}
+	return nil
+}
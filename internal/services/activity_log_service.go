@@ -10,7 +10,9 @@ import (
 
 type ActivityLogService interface {
 	CreateActivityLog(req models.CreateActivityLogRequest) (*models.ActivityHistory, error)
-	GetActivityLogs(filter models.ActivityFilter) ([]models.ActivityHistory, error)
+	GetActivityLogs(filter models.ActivityFilter, page models.PageRequest) (*models.Page[models.ActivityHistory], error)
+	// WithTx возвращает сервис, который пишет журнал в транзакции tx вместе с изменениями, которые он описывает
+	WithTx(tx repository.TxProvider) ActivityLogService
 }
 
 type activityLogService struct {
@@ -22,6 +24,10 @@ func NewActivityLogService(activityLog repository.ActivityLogRepository, logger
 	return &activityLogService{activityLog: activityLog, logger: logger}
 }
 
+func (s *activityLogService) WithTx(tx repository.TxProvider) ActivityLogService {
+	return &activityLogService{activityLog: s.activityLog.WithTx(tx), logger: s.logger}
+}
+
 func (s *activityLogService) CreateActivityLog(req models.CreateActivityLogRequest) (*models.ActivityHistory, error) {
 	const op = "service.activity_log.create"
 
@@ -90,7 +96,7 @@ func (s *activityLogService) CreateActivityLog(req models.CreateActivityLogReque
 	return activityLog, nil
 }
 
-func (s *activityLogService) GetActivityLogs(filter models.ActivityFilter) ([]models.ActivityHistory, error) {
+func (s *activityLogService) GetActivityLogs(filter models.ActivityFilter, page models.PageRequest) (*models.Page[models.ActivityHistory], error) {
 	const op = "service.activity_log.get"
 
 	s.logger.Debug("retrieving activity logs",
@@ -98,7 +104,7 @@ func (s *activityLogService) GetActivityLogs(filter models.ActivityFilter) ([]mo
 		slog.Uint64("user_id", uint64(filter.UserID)),
 	)
 
-	activityLogs, err := s.activityLog.Get(filter)
+	activityLogs, err := s.activityLog.Get(filter, page)
 	if err != nil {
 		s.logger.Error("failed to retrieve activity logs",
 			slog.String("op", op),
@@ -111,7 +117,7 @@ func (s *activityLogService) GetActivityLogs(filter models.ActivityFilter) ([]mo
 	s.logger.Info("retrieved activity logs successfully",
 		slog.String("op", op),
 		slog.Uint64("user_id", uint64(filter.UserID)),
-		slog.Int("count", len(activityLogs)),
+		slog.Int("count", len(activityLogs.Items)),
 	)
 
 	return activityLogs, nil
@@ -130,6 +136,7 @@ func (s *activityLogService) validateActivityLogCreate(req models.CreateActivity
 	case models.ActivityTypeExpenseCreated,
 		models.ActivityTypeExpenseUpdated,
 		models.ActivityTypeExpenseDeleted,
+		models.ActivityTypeExpenseMerged,
 		models.ActivityTypeCategoryCreated,
 		models.ActivityTypeCategoryUpdated,
 		models.ActivityTypeCategoryDeleted,
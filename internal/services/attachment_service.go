@@ -0,0 +1,301 @@
+package services
+
+import (
+	"bytes"
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"cashcontrol/internal/storage"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttachmentNotFound    = errors.New("вложение не найдено")
+	ErrAttachmentEmpty       = errors.New("файл пустой")
+	ErrAttachmentTooLarge    = errors.New("файл слишком большой")
+	ErrAttachmentType        = errors.New("недопустимый тип файла: разрешены JPEG, PNG, WebP, HEIC и PDF")
+	ErrAttachmentLimit       = errors.New("достигнуто максимальное количество вложений у расхода")
+	ErrAttachmentFileMissing = errors.New("файл вложения отсутствует в хранилище")
+)
+
+const (
+	// maxExpenseAttachments максимальное количество вложений у одного расхода
+	maxExpenseAttachments = 20
+	// attachmentSniffLen сколько первых байт файла читается для определения типа
+	attachmentSniffLen = 512
+	// maxAttachmentNameLen максимальная длина сохраняемого имени файла в байтах
+	maxAttachmentNameLen = 255
+)
+
+// attachmentExtensions допустимые типы вложений и расширения, с которыми файлы сохраняются в хранилище
+var attachmentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"image/heic":      ".heic",
+	"application/pdf": ".pdf",
+}
+
+type AttachmentService interface {
+	GetAttachmentList(expenseID uint) ([]models.Attachment, error)
+	GetAttachmentByID(id uint) (*models.Attachment, error)
+	// UploadAttachment сохраняет файл размером size байт и прикрепляет его к расходу.
+	// Тип определяется по содержимому файла, а не по имени или заголовку запроса.
+	UploadAttachment(expense *models.Expense, fileName string, r io.Reader, size int64) (*models.Attachment, error)
+	// OpenAttachment открывает содержимое вложения, вызывающий обязан закрыть его
+	OpenAttachment(attachment *models.Attachment) (io.ReadCloser, error)
+	DeleteAttachment(attachment *models.Attachment) error
+	// DeleteExpenseAttachments удаляет все вложения расхода вместе с файлами
+	DeleteExpenseAttachments(expenseID uint) error
+	// DeleteFiles удаляет файлы, записи вложений которых уже удалены. Ошибка удаления не прерывает
+	// удаление остальных файлов, оставшиеся файлы записываются в журнал
+	DeleteFiles(storageKeys []string)
+}
+
+type attachmentService struct {
+	attachments repository.AttachmentRepository
+	files       storage.Storage
+	maxSize     int64
+	logger      *slog.Logger
+}
+
+func NewAttachmentService(attachments repository.AttachmentRepository, files storage.Storage, maxSize int64, logger *slog.Logger) AttachmentService {
+	return &attachmentService{
+		attachments: attachments,
+		files:       files,
+		maxSize:     maxSize,
+		logger:      logger,
+	}
+}
+
+func (s *attachmentService) GetAttachmentList(expenseID uint) ([]models.Attachment, error) {
+	attachments, err := s.attachments.GetByExpenseID(expenseID)
+	if err != nil {
+		s.logger.Error("failed to get attachment list",
+			slog.String("op", "get_attachment_list"),
+			slog.Uint64("expense_id", uint64(expenseID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (s *attachmentService) GetAttachmentByID(id uint) (*models.Attachment, error) {
+	attachment, err := s.attachments.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (s *attachmentService) UploadAttachment(expense *models.Expense, fileName string, r io.Reader, size int64) (*models.Attachment, error) {
+	if size <= 0 {
+		return nil, ErrAttachmentEmpty
+	}
+	if size > s.maxSize {
+		return nil, fmt.Errorf("%w: максимум %d байт", ErrAttachmentTooLarge, s.maxSize)
+	}
+
+	count, err := s.attachments.CountByExpenseID(expense.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxExpenseAttachments {
+		return nil, fmt.Errorf("%w (%d)", ErrAttachmentLimit, maxExpenseAttachments)
+	}
+
+	head := make([]byte, attachmentSniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType := detectAttachmentType(head)
+	extension, ok := attachmentExtensions[contentType]
+	if !ok {
+		s.logger.Warn("attachment type rejected",
+			slog.Uint64("expense_id", uint64(expense.ID)),
+			slog.String("content_type", contentType),
+		)
+		return nil, ErrAttachmentType
+	}
+
+	key, err := attachmentKey(expense, extension)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	body := io.TeeReader(io.MultiReader(bytes.NewReader(head), r), hash)
+	if err := s.files.Put(key, body, size, contentType); err != nil {
+		s.logger.Error("attachment upload failed",
+			slog.String("op", "upload_attachment"),
+			slog.Uint64("expense_id", uint64(expense.ID)),
+			slog.String("key", key),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		UserID:      expense.UserID,
+		ExpenseID:   expense.ID,
+		FileName:    attachmentFileName(fileName, extension),
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		StorageKey:  key,
+	}
+	if err := s.attachments.Create(attachment); err != nil {
+		// Файл без записи в базе никто не увидит, поэтому он сразу удаляется
+		if deleteErr := s.files.Delete(key); deleteErr != nil {
+			s.logger.Warn("orphan attachment file cleanup failed",
+				slog.String("key", key),
+				slog.String("error", deleteErr.Error()),
+			)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("attachment uploaded",
+		slog.Uint64("expense_id", uint64(expense.ID)),
+		slog.Uint64("attachment_id", uint64(attachment.ID)),
+		slog.String("content_type", contentType),
+		slog.Int64("size", size),
+	)
+	return attachment, nil
+}
+
+func (s *attachmentService) OpenAttachment(attachment *models.Attachment) (io.ReadCloser, error) {
+	rc, err := s.files.Get(attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			s.logger.Warn("attachment file missing",
+				slog.Uint64("attachment_id", uint64(attachment.ID)),
+				slog.String("key", attachment.StorageKey),
+			)
+			return nil, ErrAttachmentFileMissing
+		}
+		s.logger.Error("attachment download failed",
+			slog.String("op", "open_attachment"),
+			slog.Uint64("attachment_id", uint64(attachment.ID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return rc, nil
+}
+
+// DeleteAttachment сначала удаляет файл, затем запись: удаление отсутствующего файла не считается
+// ошибкой, поэтому при сбое на втором шаге повторный запрос завершит удаление
+func (s *attachmentService) DeleteAttachment(attachment *models.Attachment) error {
+	if err := s.files.Delete(attachment.StorageKey); err != nil {
+		s.logger.Error("attachment file delete failed",
+			slog.String("op", "delete_attachment"),
+			slog.Uint64("attachment_id", uint64(attachment.ID)),
+			slog.String("key", attachment.StorageKey),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	if err := s.attachments.Delete(attachment.ID); err != nil {
+		return err
+	}
+
+	s.logger.Info("attachment deleted",
+		slog.Uint64("expense_id", uint64(attachment.ExpenseID)),
+		slog.Uint64("attachment_id", uint64(attachment.ID)),
+	)
+	return nil
+}
+
+func (s *attachmentService) DeleteExpenseAttachments(expenseID uint) error {
+	attachments, err := s.attachments.GetByExpenseID(expenseID)
+	if err != nil {
+		return err
+	}
+	return s.deleteAll(attachments)
+}
+
+func (s *attachmentService) DeleteFiles(storageKeys []string) {
+	for _, key := range storageKeys {
+		if err := s.files.Delete(key); err != nil {
+			s.logger.Warn("orphan attachment file cleanup failed",
+				slog.String("key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+func (s *attachmentService) deleteAll(attachments []models.Attachment) error {
+	for i := range attachments {
+		if err := s.DeleteAttachment(&attachments[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detectAttachmentType определяет MIME-тип по сигнатуре файла. HEIC стандартная библиотека
+// не распознает, он проверяется отдельно по брендам контейнера ISO BMFF.
+func detectAttachmentType(head []byte) string {
+	if len(head) >= 12 && string(head[4:8]) == "ftyp" {
+		switch string(head[8:12]) {
+		case "heic", "heix", "heim", "heis", "hevc", "hevx", "mif1", "msf1":
+			return "image/heic"
+		}
+	}
+	contentType := http.DetectContentType(head)
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return contentType
+}
+
+// attachmentKey строит ключ объекта. Имя файла пользователя в ключ не попадает: случайная часть
+// исключает совпадения и избавляет от экранирования произвольных символов.
+func attachmentKey(expense *models.Expense, extension string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("users/%d/expenses/%d/%s%s", expense.UserID, expense.ID, hex.EncodeToString(random), extension), nil
+}
+
+// attachmentFileName очищает имя файла от пути и управляющих символов. Если имени нет,
+// используется "attachment" с расширением по типу файла.
+func attachmentFileName(name, extension string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(name, ""))
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment" + extension
+	}
+	for len(name) > maxAttachmentNameLen {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
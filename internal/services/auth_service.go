@@ -268,6 +268,7 @@ func (s *authService) createDefaultCategories(userID uint) {
 		{Name: "Транспорт", Color: "#0EA5E9", Icon: "🚕"},
 		{Name: "Дом", Color: "#22C55E", Icon: "🏠"},
 		{Name: "Подписки", Color: "#8B5CF6", Icon: "📱"},
+		{Name: "Зарплата", Color: "#10B981", Icon: "💼", Type: models.CategoryTypeIncome},
 	}
 
 	for _, c := range defaults {
@@ -12,6 +12,8 @@ import (
 )
 
 var ErrBudgetNotFound = errors.New("бюджет не найден")
+var ErrBudgetCategoryNotFound = errors.New("лимит по категории не найден")
+var ErrBudgetPeriodConflict = errors.New("период бюджета пересекается с другим бюджетом")
 
 const (
 	NearLimitThreshold = 0.8 // 80% использования бюджета
@@ -19,33 +21,42 @@ const (
 
 type BudgetService interface {
 	CreateBudget(userID uint, req models.CreateBudgetRequest) (*models.Budget, error)
-	GetBudgetList(userID uint) ([]models.Budget, error)
+	GetBudgetList(userID uint, page models.PageRequest) (*models.Page[models.Budget], error)
 	GetBudgetByID(id uint) (*models.Budget, error)
 	GetBudgetByUserIDAndMonth(userID uint, month, year int) (*models.Budget, error)
 	GetCurrentBudgetStatus(userID uint) (*models.BudgetStatus, error)
 	GetBudgetStatus(userID uint, month, year int) (*models.BudgetStatus, error)
+	GetBudgetStatusAt(userID uint, date time.Time) (*models.BudgetStatus, error)
 	UpdateBudget(id uint, req models.UpdateBudgetRequest) (*models.Budget, error)
 	DeleteBudget(id uint) error
+	SetBudgetCategory(budgetID uint, req models.SetBudgetCategoryRequest) (*models.BudgetCategory, error)
+	DeleteBudgetCategory(budgetID, categoryID uint) error
 }
 
 type budgetService struct {
-	budgets  repository.BudgetRepository
-	expenses repository.ExpenseRepository
-	notifier NotificationService
-	logger   *slog.Logger
+	budgets    repository.BudgetRepository
+	expenses   repository.ExpenseRepository
+	categories repository.CategoryRepository
+	statistics repository.StatisticsRepository
+	notifier   NotificationService
+	logger     *slog.Logger
 }
 
 func NewBudgetService(
 	budgets repository.BudgetRepository,
 	expenses repository.ExpenseRepository,
+	categories repository.CategoryRepository,
+	statistics repository.StatisticsRepository,
 	notifier NotificationService,
 	logger *slog.Logger,
 ) BudgetService {
 	return &budgetService{
-		budgets:  budgets,
-		expenses: expenses,
-		notifier: notifier,
-		logger:   logger,
+		budgets:    budgets,
+		expenses:   expenses,
+		categories: categories,
+		statistics: statistics,
+		notifier:   notifier,
+		logger:     logger,
 	}
 }
 
@@ -53,7 +64,7 @@ func (s *budgetService) CreateBudget(userID uint, req models.CreateBudgetRequest
 	if err := s.validateBudgetCreate(req); err != nil {
 		s.logger.Warn("budget create validation failed",
 			slog.Uint64("user_id", uint64(userID)),
-			slog.Float64("amount", req.Amount),
+			slog.String("amount", req.Amount.String()),
 			slog.Int("month", req.Month),
 			slog.Int("year", req.Year),
 			slog.String("reason", err.Error()),
@@ -61,33 +72,65 @@ func (s *budgetService) CreateBudget(userID uint, req models.CreateBudgetRequest
 		return nil, err
 	}
 
-	// Проверяем, не существует ли уже бюджет на этот месяц
-	existing, err := s.budgets.GetByUserIDAndMonth(userID, req.Month, req.Year)
-	if err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Error("failed to check existing budget",
-				slog.Uint64("user_id", uint64(userID)),
-				slog.Int("month", req.Month),
-				slog.Int("year", req.Year),
-				slog.String("error", err.Error()),
-			)
+	periodType := req.PeriodType
+	if periodType == "" {
+		periodType = models.BudgetPeriodMonthly
+	}
+
+	budget := &models.Budget{
+		UserID:     userID,
+		Amount:     req.Amount,
+		Month:      req.Month,
+		Year:       req.Year,
+		Rollover:   req.Rollover,
+		PeriodType: periodType,
+	}
+
+	if periodType != models.BudgetPeriodMonthly {
+		// Немесячный бюджет действует с даты привязки до даты окончания или бессрочно
+		setBudgetAnchor(budget, *req.AnchorDate)
+		if err := setBudgetEnd(budget, req.EndDate); err != nil {
 			return nil, err
 		}
-		// ErrRecordNotFound - это нормально, бюджета нет
-	} else if existing != nil {
-		s.logger.Warn("budget already exists for this month",
+	}
+
+	// На любую дату может действовать только один бюджет
+	if err := s.checkBudgetOverlap(budget); err != nil {
+		s.logger.Warn("budget period overlaps existing budget",
 			slog.Uint64("user_id", uint64(userID)),
-			slog.Int("month", req.Month),
-			slog.Int("year", req.Year),
+			slog.Int("month", budget.Month),
+			slog.Int("year", budget.Year),
+			slog.String("reason", err.Error()),
 		)
-		return nil, errors.New("бюджет на этот месяц уже существует")
+		return nil, err
 	}
 
-	budget := &models.Budget{
-		UserID: userID,
-		Amount: req.Amount,
-		Month:  req.Month,
-		Year:   req.Year,
+	// Лимиты по категориям создаются вместе с бюджетом
+	seen := make(map[uint]bool, len(req.Categories))
+	var categoriesTotal models.Money
+	for _, line := range req.Categories {
+		if seen[line.CategoryID] {
+			return nil, errors.New("категория указана в бюджете несколько раз")
+		}
+		seen[line.CategoryID] = true
+
+		if err := s.validateBudgetCategory(userID, line); err != nil {
+			s.logger.Warn("budget category validation failed",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Uint64("category_id", uint64(line.CategoryID)),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
+		categoriesTotal += line.Amount
+
+		budget.Categories = append(budget.Categories, models.BudgetCategory{
+			CategoryID: line.CategoryID,
+			Amount:     line.Amount,
+		})
+	}
+	if categoriesTotal > budget.Amount {
+		return nil, errors.New("сумма лимитов по категориям превышает общий бюджет")
 	}
 
 	if err := s.budgets.Create(budget); err != nil {
@@ -103,7 +146,7 @@ func (s *budgetService) CreateBudget(userID uint, req models.CreateBudgetRequest
 	s.logger.Info("budget created",
 		slog.Uint64("budget_id", uint64(budget.ID)),
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Float64("amount", budget.Amount),
+		slog.String("amount", budget.Amount.String()),
 		slog.Int("month", budget.Month),
 		slog.Int("year", budget.Year),
 	)
@@ -111,8 +154,8 @@ func (s *budgetService) CreateBudget(userID uint, req models.CreateBudgetRequest
 	return budget, nil
 }
 
-func (s *budgetService) GetBudgetList(userID uint) ([]models.Budget, error) {
-	budgets, err := s.budgets.GetByUserID(userID)
+func (s *budgetService) GetBudgetList(userID uint, page models.PageRequest) (*models.Page[models.Budget], error) {
+	budgets, err := s.budgets.ListPage(userID, page)
 	if err != nil {
 		s.logger.Error("failed to list budgets",
 			slog.String("op", "list_budgets"),
@@ -124,18 +167,14 @@ func (s *budgetService) GetBudgetList(userID uint) ([]models.Budget, error) {
 
 	s.logger.Info("budgets listed",
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Int("count", len(budgets)),
+		slog.Int("count", len(budgets.Items)),
 	)
 
 	return budgets, nil
 }
 
 func (s *budgetService) GetCurrentBudgetStatus(userID uint) (*models.BudgetStatus, error) {
-	now := time.Now()
-	month := int(now.Month())
-	year := now.Year()
-
-	return s.GetBudgetStatus(userID, month, year)
+	return s.GetBudgetStatusAt(userID, time.Now().UTC())
 }
 
 func (s *budgetService) GetBudgetByID(id uint) (*models.Budget, error) {
@@ -158,7 +197,7 @@ func (s *budgetService) GetBudgetByID(id uint) (*models.Budget, error) {
 	s.logger.Info("budget retrieved",
 		slog.Uint64("budget_id", uint64(budget.ID)),
 		slog.Uint64("user_id", uint64(budget.UserID)),
-		slog.Float64("amount", budget.Amount),
+		slog.String("amount", budget.Amount.String()),
 	)
 
 	return budget, nil
@@ -197,44 +236,101 @@ func (s *budgetService) GetBudgetStatus(userID uint, month, year int) (*models.B
 		return nil, err
 	}
 
+	startDate, endDate := monthBounds(month, year)
+	return s.buildBudgetStatus(budget, startDate, endDate)
+}
+
+// GetBudgetStatusAt возвращает статус бюджета, действующего на указанную дату.
+// Немесячный бюджет (неделя, две недели, квартал, платежный цикл) имеет приоритет
+// над календарным месячным бюджетом.
+func (s *budgetService) GetBudgetStatusAt(userID uint, date time.Time) (*models.BudgetStatus, error) {
+	budget, err := s.budgets.GetPeriodicByUserIDAndDate(userID, date)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Error("failed to get periodic budget",
+				slog.String("op", "get_budget_status_at"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Time("date", date),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+		return s.GetBudgetStatus(userID, int(date.Month()), date.Year())
+	}
+
+	startDate, endDate := budgetPeriodBounds(budget, date)
+	return s.buildBudgetStatus(budget, startDate, endDate)
+}
+
+func (s *budgetService) buildBudgetStatus(budget *models.Budget, startDate, endDate time.Time) (*models.BudgetStatus, error) {
+	userID := budget.UserID
+
 	// Расчет потраченной суммы за период
-	spent, err := s.calculateSpentAmount(userID, month, year)
+	spent, err := s.calculateSpentAmount(userID, startDate, endDate)
 	if err != nil {
 		s.logger.Error("failed to calculate spent amount",
 			slog.String("op", "get_budget_status"),
 			slog.Uint64("user_id", uint64(userID)),
-			slog.Int("month", month),
-			slog.Int("year", year),
+			slog.Time("period_start", startDate),
+			slog.Time("period_end", endDate),
 			slog.String("error", err.Error()),
 		)
 		return nil, err
 	}
 
-	// Расчет оставшегося лимита
-	remaining := budget.Amount - spent
-	if remaining < 0 {
-		remaining = 0
+	// Перенос остатка из предыдущих периодов
+	carried, err := s.calculateCarriedAmount(budget, startDate)
+	if err != nil {
+		s.logger.Error("failed to calculate carried amount",
+			slog.String("op", "get_budget_status"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Time("period_start", startDate),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
 	}
+	effectiveLimit := budget.Amount + carried
 
-	// Определение процента использования бюджета
-	var percentage float64
-	if budget.Amount == 0 {
-		percentage = 0
-	} else {
-		percentage = (spent / budget.Amount) * 100
-	}
+	remaining, percentage, isExceeded, isNearLimit := budgetUsage(effectiveLimit, spent)
 
-	// Проверка превышения и приближения к лимиту
-	isExceeded := spent > budget.Amount
-	isNearLimit := percentage >= (NearLimitThreshold*100) && !isExceeded
+	// Расчет статуса лимитов по категориям
+	byCategory, err := s.calculateCategoryStatuses(budget, startDate, endDate)
+	if err != nil {
+		s.logger.Error("failed to calculate category statuses",
+			slog.String("op", "get_budget_status"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Time("period_start", startDate),
+			slog.Time("period_end", endDate),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
 
 	status := &models.BudgetStatus{
-		Budget:      budget,
-		Spent:       spent,
-		Remaining:   remaining,
-		Percentage:  percentage,
-		IsExceeded:  isExceeded,
-		IsNearLimit: isNearLimit,
+		Budget:         budget,
+		PeriodType:     budget.PeriodType,
+		PeriodStart:    startDate,
+		PeriodEnd:      endDate,
+		BaseAmount:     budget.Amount,
+		CarriedAmount:  carried,
+		EffectiveLimit: effectiveLimit,
+		Spent:          spent,
+		Remaining:      remaining,
+		Percentage:     percentage,
+		IsExceeded:     isExceeded,
+		IsNearLimit:    isNearLimit,
+		ByCategory:     byCategory,
+	}
+
+	for _, category := range byCategory {
+		if category.IsExceeded {
+			s.logger.Warn("budget category exceeded",
+				slog.Uint64("budget_id", uint64(budget.ID)),
+				slog.Uint64("category_id", uint64(category.CategoryID)),
+				slog.String("limit", category.Limit.String()),
+				slog.String("spent", category.Spent.String()),
+			)
+		}
 	}
 
 	// Логирование уведомлений
@@ -242,16 +338,16 @@ func (s *budgetService) GetBudgetStatus(userID uint, month, year int) (*models.B
 		s.logger.Warn("budget exceeded",
 			slog.Uint64("budget_id", uint64(budget.ID)),
 			slog.Uint64("user_id", uint64(userID)),
-			slog.Float64("budget_amount", budget.Amount),
-			slog.Float64("spent", spent),
+			slog.String("budget_amount", effectiveLimit.String()),
+			slog.String("spent", spent.String()),
 			slog.Float64("percentage", percentage),
 		)
 	} else if isNearLimit {
 		s.logger.Info("budget near limit",
 			slog.Uint64("budget_id", uint64(budget.ID)),
 			slog.Uint64("user_id", uint64(userID)),
-			slog.Float64("budget_amount", budget.Amount),
-			slog.Float64("spent", spent),
+			slog.String("budget_amount", effectiveLimit.String()),
+			slog.String("spent", spent.String()),
 			slog.Float64("percentage", percentage),
 		)
 	}
@@ -261,9 +357,9 @@ func (s *budgetService) GetBudgetStatus(userID uint, month, year int) (*models.B
 		go func() {
 			var msg string
 			if isExceeded {
-				msg = fmt.Sprintf("🚨 Бюджет превышен: потрачено %.0f%% (%.0f / %.0f)", percentage, spent, budget.Amount)
+				msg = fmt.Sprintf("🚨 Бюджет превышен: потрачено %.0f%% (%s / %s)", percentage, spent, effectiveLimit)
 			} else {
-				msg = fmt.Sprintf("⚠️ Бюджет на исходе: %.0f%% (%.0f / %.0f)", percentage, spent, budget.Amount)
+				msg = fmt.Sprintf("⚠️ Бюджет на исходе: %.0f%% (%s / %s)", percentage, spent, effectiveLimit)
 			}
 			if err := s.notifier.SendToUser(userID, msg); err != nil {
 				s.logger.Warn("send budget notification failed", slog.Uint64("user_id", uint64(userID)), slog.String("error", err.Error()))
@@ -300,6 +396,14 @@ func (s *budgetService) UpdateBudget(id uint, req models.UpdateBudgetRequest) (*
 		return nil, err
 	}
 
+	if err := s.checkBudgetOverlap(budget); err != nil {
+		s.logger.Warn("budget period overlaps existing budget",
+			slog.Uint64("budget_id", uint64(id)),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
 	if err := s.budgets.Update(budget); err != nil {
 		s.logger.Error("budget update failed",
 			slog.String("op", "update_budget"),
@@ -311,7 +415,7 @@ func (s *budgetService) UpdateBudget(id uint, req models.UpdateBudgetRequest) (*
 
 	s.logger.Info("budget updated",
 		slog.Uint64("budget_id", uint64(budget.ID)),
-		slog.Float64("amount", budget.Amount),
+		slog.String("amount", budget.Amount.String()),
 		slog.Int("month", budget.Month),
 		slog.Int("year", budget.Year),
 	)
@@ -357,12 +461,29 @@ func (s *budgetService) validateBudgetCreate(req models.CreateBudgetRequest) err
 		return errors.New("сумма бюджета должна быть больше нуля")
 	}
 
-	if req.Month < 1 || req.Month > 12 {
-		return errors.New("месяц должен быть от 1 до 12")
-	}
+	switch req.PeriodType {
+	case "", models.BudgetPeriodMonthly:
+		if req.Month < 1 || req.Month > 12 {
+			return errors.New("месяц должен быть от 1 до 12")
+		}
 
-	if req.Year < 2000 || req.Year > 2100 {
-		return errors.New("год должен быть в диапазоне 2000-2100")
+		if req.Year < 2000 || req.Year > 2100 {
+			return errors.New("год должен быть в диапазоне 2000-2100")
+		}
+
+	case models.BudgetPeriodWeekly,
+		models.BudgetPeriodBiweekly,
+		models.BudgetPeriodQuarterly,
+		models.BudgetPeriodPayCycle:
+		if req.AnchorDate == nil {
+			return errors.New("для немесячного бюджета необходимо указать дату начала периода")
+		}
+		if req.AnchorDate.Year() < 2000 || req.AnchorDate.Year() > 2100 {
+			return errors.New("год должен быть в диапазоне 2000-2100")
+		}
+
+	default:
+		return errors.New("неподдерживаемый тип периода бюджета")
 	}
 
 	return nil
@@ -373,30 +494,93 @@ func (s *budgetService) applyBudgetUpdate(budget *models.Budget, req models.Upda
 		if *req.Amount <= 0 {
 			return errors.New("сумма бюджета должна быть больше нуля")
 		}
+		var categoriesTotal models.Money
+		for _, line := range budget.Categories {
+			categoriesTotal += line.Amount
+		}
+		if categoriesTotal > *req.Amount {
+			return errors.New("сумма лимитов по категориям превышает общий бюджет")
+		}
 		budget.Amount = *req.Amount
 	}
 
-	if req.Month != nil {
-		if *req.Month < 1 || *req.Month > 12 {
-			return errors.New("месяц должен быть от 1 до 12")
+	if req.Rollover != nil {
+		budget.Rollover = *req.Rollover
+	}
+
+	if req.PeriodType != nil {
+		switch *req.PeriodType {
+		case models.BudgetPeriodMonthly,
+			models.BudgetPeriodWeekly,
+			models.BudgetPeriodBiweekly,
+			models.BudgetPeriodQuarterly,
+			models.BudgetPeriodPayCycle:
+			budget.PeriodType = *req.PeriodType
+		default:
+			return errors.New("неподдерживаемый тип периода бюджета")
 		}
-		budget.Month = *req.Month
 	}
 
-	if req.Year != nil {
-		if *req.Year < 2000 || *req.Year > 2100 {
-			return errors.New("год должен быть в диапазоне 2000-2100")
+	if budget.PeriodType == models.BudgetPeriodMonthly {
+		if req.Month != nil {
+			if *req.Month < 1 || *req.Month > 12 {
+				return errors.New("месяц должен быть от 1 до 12")
+			}
+			budget.Month = *req.Month
+		}
+
+		if req.Year != nil {
+			if *req.Year < 2000 || *req.Year > 2100 {
+				return errors.New("год должен быть в диапазоне 2000-2100")
+			}
+			budget.Year = *req.Year
 		}
-		budget.Year = *req.Year
+
+		budget.AnchorDate = nil
+		budget.EndDate = nil
+		return nil
+	}
+
+	// Месяц и год немесячного бюджета всегда берутся из даты привязки, month и year запроса не применяются
+	anchor := req.AnchorDate
+	if anchor == nil {
+		anchor = budget.AnchorDate
+	}
+	if anchor == nil {
+		return errors.New("для немесячного бюджета необходимо указать дату начала периода")
 	}
+	setBudgetAnchor(budget, *anchor)
 
+	end := budget.EndDate
+	if req.ClearEndDate {
+		end = nil
+	}
+	if req.EndDate != nil {
+		end = req.EndDate
+	}
+	return setBudgetEnd(budget, end)
+}
+
+// checkBudgetOverlap проверяет, что период действия бюджета не пересекается с другими бюджетами пользователя.
+// Иначе бессрочный немесячный бюджет навсегда скрыл бы более поздние месячные бюджеты.
+func (s *budgetService) checkBudgetOverlap(budget *models.Budget) error {
+	budgets, err := s.budgets.GetByUserID(budget.UserID)
+	if err != nil {
+		s.logger.Error("failed to check budget overlap",
+			slog.String("op", "check_budget_overlap"),
+			slog.Uint64("user_id", uint64(budget.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	if other := findOverlappingBudget(budgets, budget); other != nil {
+		return fmt.Errorf("%w: %s", ErrBudgetPeriodConflict, describeBudgetPeriod(other))
+	}
 	return nil
 }
 
-func (s *budgetService) calculateSpentAmount(userID uint, month, year int) (float64, error) {
-	// Определяем начало и конец месяца
-	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
+func (s *budgetService) calculateSpentAmount(userID uint, startDate, endDate time.Time) (models.Money, error) {
 
 	// Получаем расходы пользователя за указанный период
 	filter := models.ExpenseFilter{
@@ -409,11 +593,401 @@ func (s *budgetService) calculateSpentAmount(userID uint, month, year int) (floa
 		return 0, err
 	}
 
-	// Суммируем расходы
-	var total float64
+	// Суммируем расходы в минимальных единицах без ошибок округления
+	var total models.Money
 	for _, expense := range expenses {
 		total += expense.Amount
 	}
 
 	return total, nil
 }
+
+func (s *budgetService) SetBudgetCategory(budgetID uint, req models.SetBudgetCategoryRequest) (*models.BudgetCategory, error) {
+	budget, err := s.budgets.GetByID(budgetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBudgetNotFound
+		}
+		s.logger.Error("failed to fetch budget before setting category",
+			slog.String("op", "set_budget_category"),
+			slog.Uint64("budget_id", uint64(budgetID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.validateBudgetCategory(budget.UserID, req); err != nil {
+		s.logger.Warn("budget category validation failed",
+			slog.Uint64("budget_id", uint64(budgetID)),
+			slog.Uint64("category_id", uint64(req.CategoryID)),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	// Сумма лимитов с учетом нового значения не должна превышать общий бюджет
+	categoriesTotal := req.Amount
+	for _, line := range budget.Categories {
+		if line.CategoryID != req.CategoryID {
+			categoriesTotal += line.Amount
+		}
+	}
+	if categoriesTotal > budget.Amount {
+		return nil, errors.New("сумма лимитов по категориям превышает общий бюджет")
+	}
+
+	budgetCategory, err := s.budgets.GetBudgetCategory(budgetID, req.CategoryID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		budgetCategory = &models.BudgetCategory{
+			BudgetID:   budgetID,
+			CategoryID: req.CategoryID,
+		}
+	}
+	budgetCategory.Amount = req.Amount
+
+	if err := s.budgets.SaveBudgetCategory(budgetCategory); err != nil {
+		s.logger.Error("budget category save failed",
+			slog.String("op", "set_budget_category"),
+			slog.Uint64("budget_id", uint64(budgetID)),
+			slog.Uint64("category_id", uint64(req.CategoryID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("budget category set",
+		slog.Uint64("budget_id", uint64(budgetID)),
+		slog.Uint64("category_id", uint64(req.CategoryID)),
+		slog.String("amount", req.Amount.String()),
+	)
+
+	return budgetCategory, nil
+}
+
+func (s *budgetService) DeleteBudgetCategory(budgetID, categoryID uint) error {
+	budgetCategory, err := s.budgets.GetBudgetCategory(budgetID, categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBudgetCategoryNotFound
+		}
+		return err
+	}
+
+	if err := s.budgets.DeleteBudgetCategory(budgetCategory.ID); err != nil {
+		s.logger.Error("budget category delete failed",
+			slog.String("op", "delete_budget_category"),
+			slog.Uint64("budget_id", uint64(budgetID)),
+			slog.Uint64("category_id", uint64(categoryID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("budget category deleted",
+		slog.Uint64("budget_id", uint64(budgetID)),
+		slog.Uint64("category_id", uint64(categoryID)),
+	)
+
+	return nil
+}
+
+func (s *budgetService) validateBudgetCategory(userID uint, req models.SetBudgetCategoryRequest) error {
+	if req.Amount <= 0 {
+		return errors.New("лимит по категории должен быть больше нуля")
+	}
+
+	category, err := s.categories.GetByID(req.CategoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("категория не найдена")
+		}
+		return errors.New("ошибка при проверке категории")
+	}
+	if category.UserID != userID {
+		return errors.New("категория принадлежит другому пользователю")
+	}
+
+	return nil
+}
+
+// calculateCategoryStatuses считает использование лимитов по категориям
+// на основе той же агрегации, что и статистика за период
+func (s *budgetService) calculateCategoryStatuses(budget *models.Budget, startDate, endDate time.Time) ([]models.CategoryBudgetStatus, error) {
+	result := []models.CategoryBudgetStatus{}
+	if len(budget.Categories) == 0 {
+		return result, nil
+	}
+
+	stats, err := s.statistics.GetPeriodStatistics(budget.UserID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Лимит родительской категории учитывает расходы во всех ее подкатегориях
+	spentByCategory := make(map[uint]models.Money, len(stats.ByCategory))
+	var collect func(categories []models.CategoryStatistics)
+	collect = func(categories []models.CategoryStatistics) {
+		for _, category := range categories {
+			spentByCategory[category.CategoryID] = category.TotalAmount
+			collect(category.Children)
+		}
+	}
+	collect(stats.ByCategory)
+
+	for _, line := range budget.Categories {
+		spent := spentByCategory[line.CategoryID]
+		remaining, percentage, isExceeded, isNearLimit := budgetUsage(line.Amount, spent)
+
+		result = append(result, models.CategoryBudgetStatus{
+			CategoryID:    line.CategoryID,
+			CategoryName:  line.Category.Name,
+			CategoryColor: line.Category.Color,
+			Limit:         line.Amount,
+			Spent:         spent,
+			Remaining:     remaining,
+			Percentage:    percentage,
+			IsExceeded:    isExceeded,
+			IsNearLimit:   isNearLimit,
+		})
+	}
+
+	return result, nil
+}
+
+// calculateCarriedAmount возвращает сумму, перенесенную в бюджет из предыдущих периодов.
+// Цепочка переносов не хранится, а пересчитывается при каждом запросе, поэтому
+// изменение расходов или бюджетов прошлых периодов сразу отражается в лимите.
+func (s *budgetService) calculateCarriedAmount(budget *models.Budget, periodStart time.Time) (models.Money, error) {
+	if !budget.Rollover {
+		return 0, nil
+	}
+
+	if budget.PeriodType != models.BudgetPeriodMonthly {
+		return s.calculatePeriodicCarriedAmount(budget, periodStart)
+	}
+
+	budgets, err := s.budgets.GetByUserID(budget.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	byMonth := make(map[int]models.Budget, len(budgets))
+	for _, b := range budgets {
+		if b.PeriodType == models.BudgetPeriodMonthly {
+			byMonth[b.Year*12+b.Month-1] = b
+		}
+	}
+
+	// Идем назад, пока предыдущий месяц существует и текущий принимает перенос
+	var chain []models.Budget
+	current := *budget
+	for current.Rollover {
+		prev, ok := byMonth[current.Year*12+current.Month-2]
+		if !ok {
+			break
+		}
+		chain = append(chain, prev)
+		current = prev
+	}
+
+	// Считаем перенос от самого раннего месяца цепочки к текущему
+	var carried models.Money
+	for i := len(chain) - 1; i >= 0; i-- {
+		startDate, endDate := monthBounds(chain[i].Month, chain[i].Year)
+		spent, err := s.calculateSpentAmount(budget.UserID, startDate, endDate)
+		if err != nil {
+			return 0, err
+		}
+		carried = chain[i].Amount + carried - spent
+	}
+
+	return carried, nil
+}
+
+// calculatePeriodicCarriedAmount считает перенос для немесячного бюджета: все прошедшие
+// периоды используют одну и ту же сумму, поэтому перенос равен сумме лимитов прошедших
+// периодов минус все расходы с даты привязки до начала текущего периода.
+func (s *budgetService) calculatePeriodicCarriedAmount(budget *models.Budget, periodStart time.Time) (models.Money, error) {
+	if budget.AnchorDate == nil || !periodStart.After(*budget.AnchorDate) {
+		return 0, nil
+	}
+
+	passed := budgetPeriodIndex(budget, periodStart)
+	spent, err := s.calculateSpentAmount(budget.UserID, *budget.AnchorDate, periodStart.Add(-time.Nanosecond))
+	if err != nil {
+		return 0, err
+	}
+
+	return models.Money(passed)*budget.Amount - spent, nil
+}
+
+// budgetUsage возвращает остаток, процент использования и флаги превышения лимита
+func budgetUsage(limit, spent models.Money) (remaining models.Money, percentage float64, isExceeded, isNearLimit bool) {
+	remaining = limit - spent
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	if limit > 0 {
+		percentage = spent.Percent(limit)
+	}
+
+	isExceeded = spent > limit
+	isNearLimit = percentage >= (NearLimitThreshold*100) && !isExceeded
+
+	return remaining, percentage, isExceeded, isNearLimit
+}
+
+// monthBounds возвращает начало и конец календарного месяца
+func monthBounds(month, year int) (time.Time, time.Time) {
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	return startDate, endDate
+}
+
+// setBudgetAnchor нормализует дату привязки до начала дня и выставляет месяц и год бюджета
+func setBudgetAnchor(budget *models.Budget, anchor time.Time) {
+	anchorDate := time.Date(anchor.Year(), anchor.Month(), anchor.Day(), 0, 0, 0, 0, time.UTC)
+	budget.AnchorDate = &anchorDate
+	budget.Month = int(anchorDate.Month())
+	budget.Year = anchorDate.Year()
+}
+
+// setBudgetEnd нормализует дату окончания немесячного бюджета до начала дня, nil снимает ограничение
+func setBudgetEnd(budget *models.Budget, end *time.Time) error {
+	if end == nil {
+		budget.EndDate = nil
+		return nil
+	}
+
+	endDate := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	if budget.AnchorDate != nil && endDate.Before(*budget.AnchorDate) {
+		return errors.New("дата окончания бюджета не может быть раньше даты начала периода")
+	}
+	budget.EndDate = &endDate
+	return nil
+}
+
+// budgetActiveBounds возвращает первый и последний момент действия бюджета.
+// Месячный бюджет действует свой календарный месяц, бессрочный немесячный — без ограничения сверху.
+func budgetActiveBounds(budget *models.Budget) (time.Time, time.Time) {
+	if budget.PeriodType == models.BudgetPeriodMonthly || budget.AnchorDate == nil {
+		return monthBounds(budget.Month, budget.Year)
+	}
+
+	end := time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	if budget.EndDate != nil {
+		end = budget.EndDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return *budget.AnchorDate, end
+}
+
+// findOverlappingBudget возвращает бюджет из списка, период действия которого пересекается с budget
+func findOverlappingBudget(budgets []models.Budget, budget *models.Budget) *models.Budget {
+	for i := range budgets {
+		if budgets[i].ID == budget.ID {
+			continue
+		}
+		if budgetsOverlap(&budgets[i], budget) {
+			return &budgets[i]
+		}
+	}
+	return nil
+}
+
+// budgetsOverlap сообщает, пересекаются ли периоды действия двух бюджетов
+func budgetsOverlap(a, b *models.Budget) bool {
+	start, end := budgetActiveBounds(a)
+	otherStart, otherEnd := budgetActiveBounds(b)
+	return !start.After(otherEnd) && !otherStart.After(end)
+}
+
+// sameBudgetPeriod сообщает, действуют ли бюджеты одного типа в точности в одном периоде
+func sameBudgetPeriod(a, b *models.Budget) bool {
+	start, end := budgetActiveBounds(a)
+	otherStart, otherEnd := budgetActiveBounds(b)
+	return a.PeriodType == b.PeriodType && start.Equal(otherStart) && end.Equal(otherEnd)
+}
+
+// describeBudgetPeriod описывает период действия бюджета для сообщений об ошибках
+func describeBudgetPeriod(budget *models.Budget) string {
+	if budget.PeriodType == models.BudgetPeriodMonthly || budget.AnchorDate == nil {
+		return fmt.Sprintf("бюджет на %02d.%d", budget.Month, budget.Year)
+	}
+	if budget.EndDate == nil {
+		return fmt.Sprintf("бюджет %s с %s без даты окончания", budget.PeriodType, budget.AnchorDate.Format("02.01.2006"))
+	}
+	return fmt.Sprintf("бюджет %s с %s по %s", budget.PeriodType, budget.AnchorDate.Format("02.01.2006"), budget.EndDate.Format("02.01.2006"))
+}
+
+// budgetPeriodStep возвращает длину периода бюджета в днях или месяцах
+func budgetPeriodStep(periodType models.BudgetPeriodType) (days, months int) {
+	switch periodType {
+	case models.BudgetPeriodWeekly:
+		return 7, 0
+	case models.BudgetPeriodBiweekly:
+		return 14, 0
+	case models.BudgetPeriodQuarterly:
+		return 0, 3
+	default:
+		return 0, 1
+	}
+}
+
+// budgetPeriodStart возвращает начало n-го периода бюджета, считая от даты привязки.
+// Для месячных шагов день привязки ограничивается последним днем месяца (31 -> 28/29/30).
+func budgetPeriodStart(budget *models.Budget, n int) time.Time {
+	anchor := *budget.AnchorDate
+	days, months := budgetPeriodStep(budget.PeriodType)
+	if days > 0 {
+		return anchor.AddDate(0, 0, n*days)
+	}
+
+	firstOfMonth := time.Date(anchor.Year(), anchor.Month()+time.Month(n*months), 1, 0, 0, 0, 0, time.UTC)
+	lastDayOfMonth := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := anchor.Day()
+	if day > lastDayOfMonth {
+		day = lastDayOfMonth
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// budgetPeriodIndex возвращает номер периода бюджета, в который попадает дата
+func budgetPeriodIndex(budget *models.Budget, date time.Time) int {
+	anchor := *budget.AnchorDate
+	days, months := budgetPeriodStep(budget.PeriodType)
+
+	var n int
+	if days > 0 {
+		n = int(date.Sub(anchor).Hours()/24) / days
+	} else {
+		n = ((date.Year()-anchor.Year())*12 + int(date.Month()-anchor.Month())) / months
+	}
+
+	// Корректируем приближение с учетом ограничения дня месяца и границ суток
+	for n > 0 && budgetPeriodStart(budget, n).After(date) {
+		n--
+	}
+	for !budgetPeriodStart(budget, n+1).After(date) {
+		n++
+	}
+	return n
+}
+
+// budgetPeriodBounds возвращает начало и конец периода бюджета, в который попадает дата
+func budgetPeriodBounds(budget *models.Budget, date time.Time) (time.Time, time.Time) {
+	if budget.PeriodType == models.BudgetPeriodMonthly || budget.AnchorDate == nil {
+		return monthBounds(int(date.Month()), date.Year())
+	}
+
+	n := budgetPeriodIndex(budget, date)
+	start, end := budgetPeriodStart(budget, n), budgetPeriodStart(budget, n+1).Add(-time.Nanosecond)
+	// Последний период бюджета с датой окончания обрезается по ней
+	if _, activeEnd := budgetActiveBounds(budget); end.After(activeEnd) {
+		end = activeEnd
+	}
+	return start, end
+}
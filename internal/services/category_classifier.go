@@ -0,0 +1,113 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// categoryClassifier наивный байесовский классификатор категорий расходов.
+// Признаки — слова описания и порядок суммы, вероятности сглаживаются по Лапласу.
+type categoryClassifier struct {
+	documents  map[uint]int            // Количество расходов по категориям
+	features   map[uint]map[string]int // Частоты признаков по категориям
+	totals     map[uint]int            // Общее количество признаков категории
+	vocabulary map[string]struct{}     // Все встреченные признаки
+	samples    int                     // Размер обучающей выборки
+}
+
+func trainCategoryClassifier(samples []models.CategorySample) *categoryClassifier {
+	c := &categoryClassifier{
+		documents:  make(map[uint]int),
+		features:   make(map[uint]map[string]int),
+		totals:     make(map[uint]int),
+		vocabulary: make(map[string]struct{}),
+	}
+	for _, sample := range samples {
+		c.samples++
+		c.documents[sample.CategoryID]++
+		counts := c.features[sample.CategoryID]
+		if counts == nil {
+			counts = make(map[string]int)
+			c.features[sample.CategoryID] = counts
+		}
+		for _, feature := range categoryFeatures(sample.Description, sample.Amount) {
+			counts[feature]++
+			c.totals[sample.CategoryID]++
+			c.vocabulary[feature] = struct{}{}
+		}
+	}
+	return c
+}
+
+// predict возвращает апостериорные вероятности категорий для описания и суммы, в сумме они дают 1
+func (c *categoryClassifier) predict(description string, amount models.Money) map[uint]float64 {
+	if c.samples == 0 {
+		return map[uint]float64{}
+	}
+
+	// Признаки, которых не было в обучении, одинаково сглаживаются во всех категориях и не влияют на результат
+	var features []string
+	for _, feature := range categoryFeatures(description, amount) {
+		if _, ok := c.vocabulary[feature]; ok {
+			features = append(features, feature)
+		}
+	}
+
+	vocabulary := float64(len(c.vocabulary))
+	scores := make(map[uint]float64, len(c.documents))
+	best := math.Inf(-1)
+	for categoryID, documents := range c.documents {
+		score := math.Log(float64(documents) / float64(c.samples))
+		denominator := float64(c.totals[categoryID]) + vocabulary
+		for _, feature := range features {
+			score += math.Log((float64(c.features[categoryID][feature]) + 1) / denominator)
+		}
+		scores[categoryID] = score
+		best = math.Max(best, score)
+	}
+
+	// Нормализация логарифмов через вычитание максимума, чтобы экспонента не обнулилась
+	var sum float64
+	for categoryID, score := range scores {
+		scores[categoryID] = math.Exp(score - best)
+		sum += scores[categoryID]
+	}
+	for categoryID := range scores {
+		scores[categoryID] /= sum
+	}
+	return scores
+}
+
+// categoryFeatures разбивает описание на слова в нижнем регистре и добавляет признак порядка суммы.
+// Числа и однобуквенные слова отбрасываются: это номера карт, чеков и даты, которые не повторяются.
+func categoryFeatures(description string, amount models.Money) []string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	features := make([]string, 0, len(words)+1)
+	for _, word := range words {
+		if len([]rune(word)) < 2 || isDigits(word) {
+			continue
+		}
+		features = append(features, word)
+	}
+	if amount > 0 {
+		// Суммы одного порядка (по степени двойки) считаются похожими: кофе и продукты на неделю различаются
+		bucket := int(math.Log2(amount.Float64() + 1))
+		features = append(features, "amount:"+strconv.Itoa(bucket))
+	}
+	return features
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
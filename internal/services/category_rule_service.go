@@ -0,0 +1,421 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryRuleNotFound = errors.New("правило не найдено")
+	ErrNoMatchingRule       = errors.New("категория не указана и ни одно правило не подошло")
+)
+
+type CategoryRuleService interface {
+	CreateRule(userID uint, req models.CreateCategoryRuleRequest) (*models.CategoryRule, error)
+	GetRuleList(userID uint) ([]models.CategoryRule, error)
+	GetRuleByID(id uint) (*models.CategoryRule, error)
+	UpdateRule(id uint, req models.UpdateCategoryRuleRequest) (*models.CategoryRule, error)
+	DeleteRule(id uint) error
+	// ApplyRules повторно применяет правила к расходам указанной категории
+	ApplyRules(userID uint, req models.ApplyCategoryRulesRequest) (*models.ApplyCategoryRulesResult, error)
+}
+
+type categoryRuleService struct {
+	rules      repository.CategoryRuleRepository
+	categories repository.CategoryRepository
+	expenses   repository.ExpenseRepository
+	logger     *slog.Logger
+}
+
+func NewCategoryRuleService(
+	rules repository.CategoryRuleRepository,
+	categories repository.CategoryRepository,
+	expenses repository.ExpenseRepository,
+	logger *slog.Logger,
+) CategoryRuleService {
+	return &categoryRuleService{
+		rules:      rules,
+		categories: categories,
+		expenses:   expenses,
+		logger:     logger,
+	}
+}
+
+func (s *categoryRuleService) CreateRule(userID uint, req models.CreateCategoryRuleRequest) (*models.CategoryRule, error) {
+	rule := &models.CategoryRule{
+		UserID:              userID,
+		Name:                strings.TrimSpace(req.Name),
+		Priority:            req.Priority,
+		IsActive:            true,
+		DescriptionContains: strings.TrimSpace(req.DescriptionContains),
+		DescriptionRegex:    req.DescriptionRegex,
+		MinAmount:           req.MinAmount,
+		MaxAmount:           req.MaxAmount,
+		Weekdays:            req.Weekdays,
+		CategoryID:          req.CategoryID,
+		SetDescription:      req.SetDescription,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := s.validateRule(rule); err != nil {
+		s.logger.Warn("category rule create validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("name", req.Name),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.rules.Create(rule); err != nil {
+		s.logger.Error("category rule create failed",
+			slog.String("op", "create_category_rule"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("category rule created",
+		slog.Uint64("rule_id", uint64(rule.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("category_id", uint64(rule.CategoryID)),
+	)
+
+	return s.GetRuleByID(rule.ID)
+}
+
+func (s *categoryRuleService) GetRuleList(userID uint) ([]models.CategoryRule, error) {
+	rules, err := s.rules.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list category rules",
+			slog.String("op", "list_category_rules"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *categoryRuleService) GetRuleByID(id uint) (*models.CategoryRule, error) {
+	rule, err := s.rules.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryRuleNotFound
+		}
+		s.logger.Error("failed to get category rule",
+			slog.String("op", "get_category_rule_by_id"),
+			slog.Uint64("rule_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *categoryRuleService) UpdateRule(id uint, req models.UpdateCategoryRuleRequest) (*models.CategoryRule, error) {
+	rule, err := s.GetRuleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if req.DescriptionContains != nil {
+		rule.DescriptionContains = strings.TrimSpace(*req.DescriptionContains)
+	}
+	if req.DescriptionRegex != nil {
+		rule.DescriptionRegex = *req.DescriptionRegex
+	}
+	if req.MinAmount != nil {
+		rule.MinAmount = req.MinAmount
+	}
+	if req.MaxAmount != nil {
+		rule.MaxAmount = req.MaxAmount
+	}
+	if req.Weekdays != nil {
+		rule.Weekdays = *req.Weekdays
+	}
+	if req.CategoryID != nil {
+		rule.CategoryID = *req.CategoryID
+	}
+	if req.SetDescription != nil {
+		if *req.SetDescription == "" {
+			rule.SetDescription = nil
+		} else {
+			rule.SetDescription = req.SetDescription
+		}
+	}
+
+	if err := s.validateRule(rule); err != nil {
+		s.logger.Warn("category rule update validation failed",
+			slog.Uint64("rule_id", uint64(id)),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.rules.Update(rule); err != nil {
+		s.logger.Error("category rule update failed",
+			slog.String("op", "update_category_rule"),
+			slog.Uint64("rule_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("category rule updated",
+		slog.Uint64("rule_id", uint64(id)),
+	)
+
+	return s.GetRuleByID(id)
+}
+
+func (s *categoryRuleService) DeleteRule(id uint) error {
+	if _, err := s.GetRuleByID(id); err != nil {
+		return err
+	}
+
+	if err := s.rules.Delete(id); err != nil {
+		s.logger.Error("category rule delete failed",
+			slog.String("op", "delete_category_rule"),
+			slog.Uint64("rule_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("category rule deleted",
+		slog.Uint64("rule_id", uint64(id)),
+	)
+	return nil
+}
+
+func (s *categoryRuleService) ApplyRules(userID uint, req models.ApplyCategoryRulesRequest) (*models.ApplyCategoryRulesResult, error) {
+	if err := validateExpenseCategory(s.categories, userID, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	rules, err := loadCategoryRules(s.rules, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses, err := s.expenses.List(models.ExpenseFilter{
+		UserID:     userID,
+		CategoryID: &req.CategoryID,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ApplyCategoryRulesResult{
+		DryRun:  req.DryRun,
+		Checked: len(expenses),
+		Changes: []models.CategoryRuleChange{},
+	}
+	var changed []models.Expense
+	for _, expense := range expenses {
+		// Категории разделенного расхода заданы его частями
+		if len(expense.Splits) > 0 {
+			continue
+		}
+		rule, description := matchCategoryRule(rules, expense.Description, expense.Amount, expense.Date)
+		if rule == nil || (rule.CategoryID == expense.CategoryID && description == expense.Description) {
+			continue
+		}
+		expense.CategoryID = rule.CategoryID
+		expense.Description = description
+		expense.Category = models.Category{}
+		changed = append(changed, expense)
+		result.Changes = append(result.Changes, models.CategoryRuleChange{
+			ExpenseID:   expense.ID,
+			RuleID:      rule.ID,
+			CategoryID:  rule.CategoryID,
+			Description: description,
+		})
+	}
+	result.Updated = len(changed)
+
+	if req.DryRun || len(changed) == 0 {
+		return result, nil
+	}
+
+	err = repository.RunInTransaction(func(tx repository.TxProvider) error {
+		expenseRepo := s.expenses.WithTx(tx)
+		for i := range changed {
+			if err := expenseRepo.Update(&changed[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("category rules apply failed",
+			slog.String("op", "apply_category_rules"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("category rules applied",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("category_id", uint64(req.CategoryID)),
+		slog.Int("checked", result.Checked),
+		slog.Int("updated", result.Updated),
+	)
+	return result, nil
+}
+
+func (s *categoryRuleService) validateRule(rule *models.CategoryRule) error {
+	if rule.Name == "" {
+		return errors.New("название правила не может быть пустым")
+	}
+	// Правило без условий подошло бы к любому расходу и переписало бы категории всех расходов
+	if !categoryRuleHasCondition(rule) {
+		return errors.New("правило должно содержать хотя бы одно условие: подстроку, регулярное выражение, сумму или дни недели")
+	}
+	if rule.DescriptionRegex != "" {
+		if _, err := regexp.Compile(rule.DescriptionRegex); err != nil {
+			return errors.New("некорректное регулярное выражение: " + err.Error())
+		}
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return errors.New("минимальная сумма больше максимальной")
+	}
+	for _, day := range rule.Weekdays {
+		if day < 0 || day > 6 {
+			return errors.New("день недели должен быть от 0 (воскресенье) до 6")
+		}
+	}
+	return validateExpenseCategory(s.categories, rule.UserID, rule.CategoryID)
+}
+
+// validateExpenseCategory проверяет, что категория принадлежит пользователю и предназначена для расходов
+func validateExpenseCategory(categories repository.CategoryRepository, userID, categoryID uint) error {
+	category, err := categories.GetByID(categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("категория не найдена")
+		}
+		return err
+	}
+	if category.UserID != userID {
+		return errors.New("категория не найдена")
+	}
+	if category.Type == models.CategoryTypeIncome {
+		return errors.New("категория предназначена для доходов")
+	}
+	return nil
+}
+
+// categoryRuleHasCondition сообщает, задано ли у правила хотя бы одно условие
+func categoryRuleHasCondition(rule *models.CategoryRule) bool {
+	return rule.DescriptionContains != "" || rule.DescriptionRegex != "" ||
+		rule.MinAmount != nil || rule.MaxAmount != nil || len(rule.Weekdays) > 0
+}
+
+// compiledCategoryRule правило с заранее скомпилированным регулярным выражением
+type compiledCategoryRule struct {
+	rule  models.CategoryRule
+	regex *regexp.Regexp
+}
+
+// categoryRuleSet правила пользователя, готовые к проверке множества расходов. Регулярные выражения
+// компилируются один раз при загрузке, а не для каждого расхода.
+type categoryRuleSet []compiledCategoryRule
+
+// loadCategoryRules загружает включенные правила пользователя в порядке приоритета.
+// Правила без условий и с некорректным регулярным выражением пропускаются.
+func loadCategoryRules(repo repository.CategoryRuleRepository, userID uint) (categoryRuleSet, error) {
+	rules, err := repo.GetActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return newCategoryRuleSet(rules), nil
+}
+
+func newCategoryRuleSet(rules []models.CategoryRule) categoryRuleSet {
+	set := make(categoryRuleSet, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.IsActive || !categoryRuleHasCondition(&rule) {
+			continue
+		}
+		compiled := compiledCategoryRule{rule: rule}
+		if rule.DescriptionRegex != "" {
+			re, err := regexp.Compile(rule.DescriptionRegex)
+			if err != nil {
+				continue
+			}
+			compiled.regex = re
+		}
+		set = append(set, compiled)
+	}
+	return set
+}
+
+// matchCategoryRule возвращает первое подходящее правило и описание расхода после его применения.
+// Правила должны быть упорядочены по приоритету.
+func matchCategoryRule(rules categoryRuleSet, description string, amount models.Money, date time.Time) (*models.CategoryRule, string) {
+	for i := range rules {
+		rule := &rules[i].rule
+		re := rules[i].regex
+		if rule.DescriptionContains != "" &&
+			!strings.Contains(strings.ToLower(description), strings.ToLower(rule.DescriptionContains)) {
+			continue
+		}
+		if rule.MinAmount != nil && amount < *rule.MinAmount {
+			continue
+		}
+		if rule.MaxAmount != nil && amount > *rule.MaxAmount {
+			continue
+		}
+		if len(rule.Weekdays) > 0 && !containsWeekday(rule.Weekdays, date.Weekday()) {
+			continue
+		}
+
+		var match []int
+		if re != nil {
+			if match = re.FindStringSubmatchIndex(description); match == nil {
+				continue
+			}
+		}
+
+		if rule.SetDescription == nil {
+			return rule, description
+		}
+		if re == nil {
+			return rule, *rule.SetDescription
+		}
+		return rule, string(re.ExpandString(nil, *rule.SetDescription, description, match))
+	}
+	return nil, description
+}
+
+func containsWeekday(weekdays []int, day time.Weekday) bool {
+	for _, d := range weekdays {
+		if d == int(day) {
+			return true
+		}
+	}
+	return false
+}
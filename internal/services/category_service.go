@@ -4,19 +4,30 @@ import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/repository"
 	"errors"
+	"fmt"
 	"log/slog"
 
 	"gorm.io/gorm"
 )
 
-var ErrCategoryNotFound = errors.New("категория не найдена")
+var (
+	ErrCategoryNotFound = errors.New("категория не найдена")
+	ErrCategoryInUse    = errors.New("в категории есть операции, укажите категорию для их переноса")
+	// ErrCategoryMergeInvalid категории нельзя объединить: одна и та же категория, разные типы или глубина дерева
+	ErrCategoryMergeInvalid = errors.New("объединение категорий невозможно")
+)
 
 type CategoryService interface {
 	CreateCategory(userID uint, req models.CreateCategoryRequest) (*models.Category, error)
 	GetCategoryList(userID uint) ([]models.Category, error)
+	GetCategoryTree(userID uint) ([]models.Category, error)
 	GetCategoryByID(id uint) (*models.Category, error)
 	UpdateCategory(id uint, req models.UpdateCategoryRequest) (*models.Category, error)
-	DeleteCategory(id uint) error
+	// DeleteCategory удаляет категорию. Если в ней есть операции, нужно указать targetID,
+	// тогда операции переносятся в целевую категорию (как при MergeCategory)
+	DeleteCategory(id uint, targetID *uint) error
+	// MergeCategory переносит все операции и подкатегории из id в targetID и удаляет id
+	MergeCategory(id, targetID uint) (*models.CategoryMergeResult, error)
 }
 
 type categoryService struct {
@@ -44,11 +55,29 @@ func (s *categoryService) CreateCategory(userID uint, req models.CreateCategoryR
 		color = "#3B82F6" // Default цвет
 	}
 
+	categoryType := req.Type
+	if categoryType == "" {
+		categoryType = models.CategoryTypeExpense
+	}
+
 	category := &models.Category{
 		UserID: userID,
 		Name:   req.Name,
 		Color:  color,
 		Icon:   req.Icon,
+		Type:   categoryType,
+	}
+
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if err := s.validateCategoryParent(category, *req.ParentID); err != nil {
+			s.logger.Warn("category parent validation failed",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Uint64("parent_id", uint64(*req.ParentID)),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
+		category.ParentID = req.ParentID
 	}
 
 	if err := s.categories.Create(category); err != nil {
@@ -140,6 +169,22 @@ func (s *categoryService) UpdateCategory(id uint, req models.UpdateCategoryReque
 	if req.Icon != nil {
 		category.Icon = *req.Icon
 	}
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			category.ParentID = nil
+		} else {
+			if err := s.validateCategoryParent(category, *req.ParentID); err != nil {
+				s.logger.Warn("category parent validation failed",
+					slog.Uint64("category_id", uint64(id)),
+					slog.Uint64("parent_id", uint64(*req.ParentID)),
+					slog.String("reason", err.Error()),
+				)
+				return nil, err
+			}
+			parentID := *req.ParentID
+			category.ParentID = &parentID
+		}
+	}
 
 	if err := s.categories.Update(category); err != nil {
 		s.logger.Error("category update failed",
@@ -158,8 +203,16 @@ func (s *categoryService) UpdateCategory(id uint, req models.UpdateCategoryReque
 	return category, nil
 }
 
-func (s *categoryService) DeleteCategory(id uint) error {
-	_, err := s.categories.GetByID(id)
+// DeleteCategory удаляет категорию без операций, ее подкатегории поднимаются на уровень выше
+// и становятся потомками родителя удаляемой категории (или корневыми).
+// С targetID удаление выполняется как объединение с целевой категорией.
+func (s *categoryService) DeleteCategory(id uint, targetID *uint) error {
+	if targetID != nil {
+		_, err := s.MergeCategory(id, *targetID)
+		return err
+	}
+
+	category, err := s.categories.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			s.logger.Warn("category not found for delete",
@@ -175,7 +228,31 @@ func (s *categoryService) DeleteCategory(id uint) error {
 		return err
 	}
 
-	if err := s.categories.Delete(id); err != nil {
+	err = repository.RunInTransaction(func(tx repository.TxProvider) error {
+		categories := s.categories.WithTx(tx)
+
+		// Операции не должны ссылаться на удаленную категорию, иначе они пропадут из статистики
+		count, err := categories.CountTransactions(id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrCategoryInUse
+		}
+
+		if err := categories.ReparentChildren(id, category.ParentID); err != nil {
+			return err
+		}
+		return categories.Delete(id)
+	})
+	if errors.Is(err, ErrCategoryInUse) {
+		s.logger.Warn("category delete rejected",
+			slog.Uint64("category_id", uint64(id)),
+			slog.String("reason", err.Error()),
+		)
+		return err
+	}
+	if err != nil {
 		s.logger.Error("category delete failed",
 			slog.String("op", "delete_category"),
 			slog.Uint64("category_id", uint64(id)),
@@ -191,9 +268,252 @@ func (s *categoryService) DeleteCategory(id uint) error {
 	return nil
 }
 
+func (s *categoryService) MergeCategory(id, targetID uint) (*models.CategoryMergeResult, error) {
+	source, err := s.GetCategoryByID(id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.GetCategoryByID(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateCategoryMerge(source, target); err != nil {
+		if errors.Is(err, ErrCategoryMergeInvalid) || errors.Is(err, ErrCategoryNotFound) {
+			s.logger.Warn("category merge validation failed",
+				slog.Uint64("category_id", uint64(id)),
+				slog.Uint64("target_category_id", uint64(targetID)),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
+		s.logger.Error("category merge check failed",
+			slog.String("op", "merge_category"),
+			slog.Uint64("category_id", uint64(id)),
+			slog.Uint64("target_category_id", uint64(targetID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	var result *models.CategoryMergeResult
+	err = repository.RunInTransaction(func(tx repository.TxProvider) error {
+		categories := s.categories.WithTx(tx)
+
+		moved, err := categories.ReassignTransactions(id, targetID)
+		if err != nil {
+			return err
+		}
+		if err := categories.ReparentChildren(id, &targetID); err != nil {
+			return err
+		}
+		if err := categories.Delete(id); err != nil {
+			return err
+		}
+		result = moved
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("category merge failed",
+			slog.String("op", "merge_category"),
+			slog.Uint64("category_id", uint64(id)),
+			slog.Uint64("target_category_id", uint64(targetID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("category merged",
+		slog.Uint64("category_id", uint64(id)),
+		slog.Uint64("target_category_id", uint64(targetID)),
+		slog.Int64("expenses", result.Expenses),
+		slog.Int64("incomes", result.Incomes),
+		slog.Int64("recurring_expenses", result.RecurringExpenses),
+		slog.Int64("installment_plans", result.InstallmentPlans),
+		slog.Int64("budget_limits", result.BudgetLimits),
+	)
+
+	return result, nil
+}
+
+// validateCategoryMerge проверяет, что категории принадлежат одному пользователю и имеют один тип,
+// а подкатегории исходной категории можно перенести в целевую без нарушения глубины дерева.
+// Ошибки проверки оборачивают ErrCategoryMergeInvalid или равны ErrCategoryNotFound, остальные — ошибки БД.
+func (s *categoryService) validateCategoryMerge(source, target *models.Category) error {
+	if source.ID == target.ID {
+		return fmt.Errorf("%w: категория совпадает с целевой", ErrCategoryMergeInvalid)
+	}
+	if source.UserID != target.UserID {
+		return ErrCategoryNotFound
+	}
+	if source.Type != target.Type {
+		return fmt.Errorf("%w: категории разных типов", ErrCategoryMergeInvalid)
+	}
+
+	subtree, err := s.categories.GetSubtreeIDs(source.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке подкатегорий: %w", err)
+	}
+	for _, id := range subtree {
+		if id == target.ID {
+			return fmt.Errorf("%w: целевая категория является подкатегорией исходной", ErrCategoryMergeInvalid)
+		}
+	}
+
+	categories, err := s.categories.GetByUserID(source.UserID)
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке подкатегорий: %w", err)
+	}
+
+	// Подкатегории исходной категории становятся потомками целевой
+	height := categorySubtreeHeight(source.ID, categories)
+	if height > 1 && categoryDepth(target.ID, categories)+height-1 > models.MaxCategoryDepth {
+		return fmt.Errorf("%w: превышена максимальная вложенность категорий (%d)", ErrCategoryMergeInvalid, models.MaxCategoryDepth)
+	}
+
+	return nil
+}
+
 func (s *categoryService) validateCategoryCreate(req models.CreateCategoryRequest) error {
 	if req.Name == "" {
 		return errors.New("название категории не может быть пустым")
 	}
+	switch req.Type {
+	case "", models.CategoryTypeExpense, models.CategoryTypeIncome:
+	default:
+		return errors.New("неподдерживаемый тип категории")
+	}
 	return nil
 }
+
+func (s *categoryService) GetCategoryTree(userID uint) ([]models.Category, error) {
+	categories, err := s.categories.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list categories",
+			slog.String("op", "category_tree"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+// validateCategoryParent проверяет, что родитель принадлежит тому же пользователю, имеет тот же тип,
+// не является самой категорией или ее потомком и что вложенность не превысит MaxCategoryDepth
+func (s *categoryService) validateCategoryParent(category *models.Category, parentID uint) error {
+	categories, err := s.categories.GetByUserID(category.UserID)
+	if err != nil {
+		return errors.New("ошибка при проверке родительской категории")
+	}
+
+	byID := make(map[uint]models.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	parent, ok := byID[parentID]
+	if !ok {
+		return errors.New("родительская категория не найдена")
+	}
+	if parent.Type != category.Type {
+		return errors.New("тип подкатегории должен совпадать с типом родительской категории")
+	}
+
+	// Глубина родителя и проверка на цикл
+	parentDepth := 0
+	current := parent
+	for {
+		if category.ID != 0 && current.ID == category.ID {
+			return errors.New("категория не может быть вложена в саму себя или свою подкатегорию")
+		}
+		parentDepth++
+		if current.ParentID == nil {
+			break
+		}
+		next, ok := byID[*current.ParentID]
+		if !ok {
+			break
+		}
+		current = next
+	}
+
+	// Высота поддерева переносимой категории
+	height := 1
+	if category.ID != 0 {
+		height = categorySubtreeHeight(category.ID, categories)
+	}
+
+	if parentDepth+height > models.MaxCategoryDepth {
+		return fmt.Errorf("превышена максимальная вложенность категорий (%d)", models.MaxCategoryDepth)
+	}
+
+	return nil
+}
+
+// categoryDepth возвращает уровень категории в дереве, корневая категория имеет уровень 1
+func categoryDepth(id uint, categories []models.Category) int {
+	byID := make(map[uint]models.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	depth := 0
+	current, ok := byID[id]
+	// Ограничение по числу категорий защищает от зацикленных данных
+	for ok && depth < len(categories) {
+		depth++
+		if current.ParentID == nil {
+			break
+		}
+		current, ok = byID[*current.ParentID]
+	}
+	return depth
+}
+
+// categorySubtreeHeight возвращает количество уровней в поддереве категории, включая ее саму
+func categorySubtreeHeight(id uint, categories []models.Category) int {
+	height := 1
+	for _, c := range categories {
+		if c.ParentID != nil && *c.ParentID == id {
+			if h := categorySubtreeHeight(c.ID, categories) + 1; h > height {
+				height = h
+			}
+		}
+	}
+	return height
+}
+
+// buildCategoryTree собирает плоский список категорий в дерево. Категории, родитель которых
+// отсутствует в списке, считаются корневыми.
+func buildCategoryTree(categories []models.Category) []models.Category {
+	present := make(map[uint]bool, len(categories))
+	for _, c := range categories {
+		present[c.ID] = true
+	}
+
+	childrenOf := make(map[uint][]models.Category)
+	var roots []models.Category
+	for _, c := range categories {
+		if c.ParentID != nil && present[*c.ParentID] {
+			childrenOf[*c.ParentID] = append(childrenOf[*c.ParentID], c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		for i := range nodes {
+			nodes[i].Children = attach(childrenOf[nodes[i].ID])
+		}
+		return nodes
+	}
+
+	result := attach(roots)
+	if result == nil {
+		result = []models.Category{}
+	}
+	return result
+}
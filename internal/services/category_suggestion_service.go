@@ -0,0 +1,130 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// categorySuggestionSamples количество последних расходов, на которых обучается модель
+	categorySuggestionSamples = 5000
+	// defaultCategorySuggestionLimit количество подсказок по умолчанию
+	defaultCategorySuggestionLimit = 3
+)
+
+type CategorySuggestionService interface {
+	// SuggestCategories предлагает категории для расхода: сначала по правилам, затем по истории расходов пользователя
+	SuggestCategories(userID uint, req models.CategorySuggestionRequest) (*models.CategorySuggestionResult, error)
+}
+
+type categorySuggestionService struct {
+	expenses   repository.ExpenseRepository
+	categories repository.CategoryRepository
+	rules      repository.CategoryRuleRepository
+	logger     *slog.Logger
+}
+
+func NewCategorySuggestionService(
+	expenses repository.ExpenseRepository,
+	categories repository.CategoryRepository,
+	rules repository.CategoryRuleRepository,
+	logger *slog.Logger,
+) CategorySuggestionService {
+	return &categorySuggestionService{
+		expenses:   expenses,
+		categories: categories,
+		rules:      rules,
+		logger:     logger,
+	}
+}
+
+func (s *categorySuggestionService) SuggestCategories(userID uint, req models.CategorySuggestionRequest) (*models.CategorySuggestionResult, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultCategorySuggestionLimit
+	}
+	date := time.Now()
+	if req.Date != nil {
+		date = *req.Date
+	}
+
+	// Подсказываются только существующие категории расходов пользователя
+	categoryList, err := s.categories.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(categoryList))
+	for _, category := range categoryList {
+		if category.Type != models.CategoryTypeIncome {
+			names[category.ID] = category.Name
+		}
+	}
+
+	result := &models.CategorySuggestionResult{Suggestions: []models.CategorySuggestion{}}
+
+	rules, err := loadCategoryRules(s.rules, userID)
+	if err != nil {
+		return nil, err
+	}
+	if rule, _ := matchCategoryRule(rules, req.Description, req.Amount, date); rule != nil {
+		if name, ok := names[rule.CategoryID]; ok {
+			ruleID := rule.ID
+			result.Suggestions = append(result.Suggestions, models.CategorySuggestion{
+				CategoryID:   rule.CategoryID,
+				CategoryName: name,
+				Confidence:   1,
+				Source:       models.CategorySuggestionSourceRule,
+				RuleID:       &ruleID,
+			})
+		}
+	}
+
+	samples, err := s.expenses.GetCategorySamples(userID, categorySuggestionSamples)
+	if err != nil {
+		return nil, err
+	}
+	known := samples[:0]
+	for _, sample := range samples {
+		if _, ok := names[sample.CategoryID]; ok {
+			known = append(known, sample)
+		}
+	}
+	result.Samples = len(known)
+
+	predictions := trainCategoryClassifier(known).predict(req.Description, req.Amount)
+	history := make([]models.CategorySuggestion, 0, len(predictions))
+	for categoryID, probability := range predictions {
+		if len(result.Suggestions) > 0 && result.Suggestions[0].CategoryID == categoryID {
+			continue
+		}
+		history = append(history, models.CategorySuggestion{
+			CategoryID:   categoryID,
+			CategoryName: names[categoryID],
+			Confidence:   math.Round(probability*10000) / 10000,
+			Source:       models.CategorySuggestionSourceHistory,
+		})
+	}
+	sort.Slice(history, func(i, j int) bool {
+		if history[i].Confidence != history[j].Confidence {
+			return history[i].Confidence > history[j].Confidence
+		}
+		return history[i].CategoryID < history[j].CategoryID
+	})
+
+	result.Suggestions = append(result.Suggestions, history...)
+	if len(result.Suggestions) > limit {
+		result.Suggestions = result.Suggestions[:limit]
+	}
+
+	s.logger.Debug("category suggestions built",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("samples", result.Samples),
+		slog.Int("suggestions", len(result.Suggestions)),
+	)
+
+	return result, nil
+}
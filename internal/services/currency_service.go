@@ -0,0 +1,334 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExchangeRateNotFound = errors.New("курс валюты не найден")
+	ErrInvalidCurrency      = errors.New("некорректный код валюты")
+)
+
+type CurrencyService interface {
+	GetBaseCurrency(userID uint) (string, error)
+	SetBaseCurrency(userID uint, currency string) (*models.User, error)
+	// ConvertToBase пересчитывает сумму в базовую валюту пользователя по курсу на дату
+	ConvertToBase(userID uint, amount models.Money, currency string, date time.Time) (converted models.Money, rate float64, err error)
+	// GetRate возвращает курс из таблицы курсов пользователя
+	GetRate(userID uint, fromCurrency, toCurrency string, date time.Time) (float64, error)
+
+	GetRateList(userID uint, fromCurrency, toCurrency string) ([]models.ExchangeRate, error)
+	GetRateByID(id uint) (*models.ExchangeRate, error)
+	CreateRate(userID uint, req models.CreateExchangeRateRequest) (*models.ExchangeRate, error)
+	DeleteRate(id uint) error
+	ImportRates(userID uint, r io.Reader) (*models.ExchangeRateImportResult, error)
+}
+
+type currencyService struct {
+	rates    repository.ExchangeRateRepository
+	users    repository.UserRepository
+	expenses repository.ExpenseRepository
+	logger   *slog.Logger
+}
+
+func NewCurrencyService(
+	rates repository.ExchangeRateRepository,
+	users repository.UserRepository,
+	expenses repository.ExpenseRepository,
+	logger *slog.Logger,
+) CurrencyService {
+	return &currencyService{
+		rates:    rates,
+		users:    users,
+		expenses: expenses,
+		logger:   logger,
+	}
+}
+
+func (s *currencyService) GetBaseCurrency(userID uint) (string, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrUserNotFound
+		}
+		return "", err
+	}
+	if user.BaseCurrency == "" {
+		return models.DefaultCurrency, nil
+	}
+	return user.BaseCurrency, nil
+}
+
+func (s *currencyService) SetBaseCurrency(userID uint, currency string) (*models.User, error) {
+	code, err := normalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if user.BaseCurrency == code {
+		return user, nil
+	}
+
+	// Суммы расходов уже пересчитаны в текущую базовую валюту, поэтому менять ее можно только до первого расхода
+	limit := 1
+	existing, err := s.expenses.List(models.ExpenseFilter{UserID: userID, Limit: &limit})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, errors.New("нельзя сменить базовую валюту при наличии расходов")
+	}
+
+	user.BaseCurrency = code
+	if err := s.users.Update(user); err != nil {
+		s.logger.Error("base currency update failed",
+			slog.String("op", "set_base_currency"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("base currency updated",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("currency", code),
+	)
+
+	return user, nil
+}
+
+func (s *currencyService) ConvertToBase(userID uint, amount models.Money, currency string, date time.Time) (models.Money, float64, error) {
+	base, err := s.GetBaseCurrency(userID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	rate, err := s.GetRate(userID, currency, base, date)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return amount.MulRate(rate), rate, nil
+}
+
+func (s *currencyService) GetRate(userID uint, fromCurrency, toCurrency string, date time.Time) (float64, error) {
+	if fromCurrency == toCurrency {
+		return 1, nil
+	}
+
+	rate, err := s.rates.FindLatest(userID, fromCurrency, toCurrency, date)
+	if err == nil {
+		return rate.Rate, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	// Обратный курс, если задана только противоположная пара
+	inverse, err := s.rates.FindLatest(userID, toCurrency, fromCurrency, date)
+	if err == nil && inverse.Rate > 0 {
+		return 1 / inverse.Rate, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	s.logger.Warn("exchange rate not found",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("from", fromCurrency),
+		slog.String("to", toCurrency),
+		slog.Time("date", date),
+	)
+	return 0, fmt.Errorf("%w: %s → %s на %s", ErrExchangeRateNotFound, fromCurrency, toCurrency, date.Format("2006-01-02"))
+}
+
+func (s *currencyService) GetRateList(userID uint, fromCurrency, toCurrency string) ([]models.ExchangeRate, error) {
+	rates, err := s.rates.List(userID, strings.ToUpper(fromCurrency), strings.ToUpper(toCurrency))
+	if err != nil {
+		s.logger.Error("failed to list exchange rates",
+			slog.String("op", "list_exchange_rates"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return rates, nil
+}
+
+func (s *currencyService) GetRateByID(id uint) (*models.ExchangeRate, error) {
+	rate, err := s.rates.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExchangeRateNotFound
+		}
+		return nil, err
+	}
+	return rate, nil
+}
+
+func (s *currencyService) CreateRate(userID uint, req models.CreateExchangeRateRequest) (*models.ExchangeRate, error) {
+	rate, err := buildExchangeRate(req.FromCurrency, req.ToCurrency, req.Date, req.Rate)
+	if err != nil {
+		return nil, err
+	}
+	rate.UserID = userID
+
+	if err := s.rates.Upsert(rate); err != nil {
+		s.logger.Error("exchange rate save failed",
+			slog.String("op", "create_exchange_rate"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("exchange rate saved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("from", rate.FromCurrency),
+		slog.String("to", rate.ToCurrency),
+		slog.Time("date", rate.Date),
+		slog.Float64("rate", rate.Rate),
+	)
+
+	return rate, nil
+}
+
+func (s *currencyService) DeleteRate(id uint) error {
+	if _, err := s.GetRateByID(id); err != nil {
+		return err
+	}
+
+	if err := s.rates.Delete(id); err != nil {
+		s.logger.Error("exchange rate delete failed",
+			slog.String("op", "delete_exchange_rate"),
+			slog.Uint64("rate_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// ImportRates загружает курсы пользователя из CSV со столбцами date,from_currency,to_currency,rate.
+// Строка заголовка необязательна, ошибочные строки пропускаются и возвращаются в результате.
+func (s *currencyService) ImportRates(userID uint, r io.Reader) (*models.ExchangeRateImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	result := &models.ExchangeRateImportResult{Errors: []string{}}
+	line := 0
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+		}
+
+		if line == 1 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			continue
+		}
+
+		rate, err := parseExchangeRateRecord(record)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("строка %d: %s", line, err.Error()))
+			continue
+		}
+		rate.UserID = userID
+
+		if err := s.rates.Upsert(rate); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("строка %d: %s", line, err.Error()))
+			continue
+		}
+		result.Imported++
+	}
+
+	s.logger.Info("exchange rates imported",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("imported", result.Imported),
+		slog.Int("errors", len(result.Errors)),
+	)
+
+	return result, nil
+}
+
+func parseExchangeRateRecord(record []string) (*models.ExchangeRate, error) {
+	if len(record) != 4 {
+		return nil, errors.New("ожидается 4 столбца: date,from_currency,to_currency,rate")
+	}
+
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+	if err != nil {
+		return nil, errors.New("некорректная дата, ожидается формат YYYY-MM-DD")
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+	if err != nil || value <= 0 {
+		return nil, errors.New("курс должен быть положительным числом")
+	}
+
+	return buildExchangeRate(record[1], record[2], date, value)
+}
+
+func buildExchangeRate(fromCurrency, toCurrency string, date time.Time, value float64) (*models.ExchangeRate, error) {
+	from, err := normalizeCurrency(fromCurrency)
+	if err != nil {
+		return nil, err
+	}
+	to, err := normalizeCurrency(toCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, errors.New("валюты курса должны различаться")
+	}
+	if value <= 0 {
+		return nil, errors.New("курс должен быть больше нуля")
+	}
+
+	y, m, d := date.Date()
+	return &models.ExchangeRate{
+		FromCurrency: from,
+		ToCurrency:   to,
+		Date:         time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+		Rate:         value,
+	}, nil
+}
+
+// normalizeCurrency приводит код валюты ISO 4217 к верхнему регистру и проверяет формат
+func normalizeCurrency(currency string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(currency))
+	if len(code) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, ch := range code {
+		if ch < 'A' || ch > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return code, nil
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+type DuplicateService interface {
+	// FindDuplicates возвращает группы возможных дубликатов: одинаковая сумма, близкие даты и похожие описания
+	FindDuplicates(filter models.DuplicateFilter) ([]models.DuplicateGroup, error)
+	// MergeDuplicates оставляет один расход, удаляет остальные и записывает объединение в историю действий
+	MergeDuplicates(userID uint, req models.MergeDuplicatesRequest) (*models.MergeDuplicatesResult, error)
+}
+
+type duplicateService struct {
+	expenses    repository.ExpenseRepository
+	attachments repository.AttachmentRepository
+	activity    ActivityLogService
+	logger      *slog.Logger
+}
+
+func NewDuplicateService(expenses repository.ExpenseRepository, attachments repository.AttachmentRepository, activity ActivityLogService, logger *slog.Logger) DuplicateService {
+	return &duplicateService{expenses: expenses, attachments: attachments, activity: activity, logger: logger}
+}
+
+func (s *duplicateService) FindDuplicates(filter models.DuplicateFilter) ([]models.DuplicateGroup, error) {
+	if err := validateDuplicateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.expenses.ListDuplicateCandidates(filter)
+	if err != nil {
+		s.logger.Error("failed to list duplicate candidates",
+			slog.String("op", "find_duplicates"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	// Кандидаты упорядочены по сумме, поэтому группы строятся внутри каждой серии одинаковых сумм
+	groups := []models.DuplicateGroup{}
+	for start := 0; start < len(candidates); {
+		end := start
+		for end < len(candidates) && candidates[end].Amount == candidates[start].Amount {
+			end++
+		}
+		groups = append(groups, groupDuplicates(candidates[start:end], filter)...)
+		start = end
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Expenses[0].Date.After(groups[j].Expenses[0].Date)
+	})
+
+	s.logger.Info("duplicate expenses found",
+		slog.Uint64("user_id", uint64(filter.UserID)),
+		slog.Int("candidates", len(candidates)),
+		slog.Int("groups", len(groups)),
+	)
+
+	return groups, nil
+}
+
+func (s *duplicateService) MergeDuplicates(userID uint, req models.MergeDuplicatesRequest) (*models.MergeDuplicatesResult, error) {
+	keep, err := s.loadUserExpense(userID, req.KeepID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[uint]bool{keep.ID: true}
+	duplicates := make([]*models.Expense, 0, len(req.DuplicateIDs))
+	for _, id := range req.DuplicateIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("расход %d указан несколько раз", id)
+		}
+		seen[id] = true
+
+		duplicate, err := s.loadUserExpense(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+
+	// Объединять можно только настоящую группу дубликатов: критерии поиска проверяются заново,
+	// иначе запрос мог бы схлопнуть в один любые расходы пользователя
+	filter := models.DuplicateFilter{
+		UserID:        userID,
+		WindowDays:    models.DefaultDuplicateWindowDays,
+		MinSimilarity: models.DefaultDuplicateSimilarity,
+	}
+	if req.WindowDays != nil {
+		filter.WindowDays = *req.WindowDays
+	}
+	if req.MinSimilarity != nil {
+		filter.MinSimilarity = *req.MinSimilarity
+	}
+	if err := validateDuplicateFilter(filter); err != nil {
+		return nil, err
+	}
+	if err := checkDuplicateGroup(keep, duplicates, filter); err != nil {
+		s.logger.Warn("duplicate merge rejected",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Uint64("expense_id", uint64(keep.ID)),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	// Оставшийся расход дополняется данными дубликатов, которых у него нет. Идентификатор выписки
+	// переносится, чтобы повторный импорт той же выписки не создал удаленный расход заново,
+	// вложения дубликатов переходят к оставшемуся расходу.
+	tags := keep.Tags
+	tagIDs := make(map[uint]bool, len(tags))
+	for _, tag := range tags {
+		tagIDs[tag.ID] = true
+	}
+	deletedIDs := make([]uint, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		if keep.Description == "" {
+			keep.Description = duplicate.Description
+		}
+		if keep.AccountID == nil {
+			keep.AccountID = duplicate.AccountID
+		}
+		if keep.ExternalID == nil {
+			keep.ExternalID = duplicate.ExternalID
+		}
+		for _, tag := range duplicate.Tags {
+			if !tagIDs[tag.ID] {
+				tagIDs[tag.ID] = true
+				tags = append(tags, tag)
+			}
+		}
+		deletedIDs = append(deletedIDs, duplicate.ID)
+	}
+
+	err = repository.RunInTransaction(func(tx repository.TxProvider) error {
+		expenses := s.expenses.WithTx(tx)
+		for _, id := range deletedIDs {
+			if err := expenses.Delete(id); err != nil {
+				return err
+			}
+		}
+		if err := s.attachments.WithTx(tx).MoveToExpense(deletedIDs, keep.ID); err != nil {
+			return err
+		}
+		if err := expenses.Update(keep); err != nil {
+			return err
+		}
+		if len(tags) != len(keep.Tags) {
+			if err := expenses.ReplaceTags(keep, tags); err != nil {
+				return err
+			}
+			keep.Tags = tags
+		}
+
+		// Запись в журнале сохраняется вместе с объединением, чтобы оно не осталось без следа
+		_, err := s.activity.WithTx(tx).CreateActivityLog(models.CreateActivityLogRequest{
+			UserID:       userID,
+			ActivityType: models.ActivityTypeExpenseMerged,
+			EntityType:   "expense",
+			EntityID:     keep.ID,
+			Description:  fmt.Sprintf("Объединено дубликатов: %d", len(deletedIDs)),
+			Metadata: map[string]interface{}{
+				"kept_id":     keep.ID,
+				"deleted_ids": deletedIDs,
+				"amount":      keep.Amount,
+			},
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.Error("duplicate merge failed",
+			slog.String("op", "merge_duplicates"),
+			slog.Uint64("expense_id", uint64(keep.ID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("duplicate expenses merged",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("expense_id", uint64(keep.ID)),
+		slog.Int("deleted", len(deletedIDs)),
+	)
+
+	return &models.MergeDuplicatesResult{Expense: keep, DeletedIDs: deletedIDs}, nil
+}
+
+// loadUserExpense возвращает расход пользователя, чужой расход считается ненайденным
+func (s *duplicateService) loadUserExpense(userID, id uint) (*models.Expense, error) {
+	expense, err := s.expenses.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrExpenseNotFound, id)
+		}
+		return nil, err
+	}
+	if expense.UserID != userID {
+		return nil, fmt.Errorf("%w: %d", ErrExpenseNotFound, id)
+	}
+	return expense, nil
+}
+
+func validateDuplicateFilter(filter models.DuplicateFilter) error {
+	if filter.WindowDays < 0 {
+		return errors.New("окно поиска не может быть отрицательным")
+	}
+	if filter.MinSimilarity < 0 || filter.MinSimilarity > 1 {
+		return errors.New("сходство описаний должно быть от 0 до 1")
+	}
+	return nil
+}
+
+// checkDuplicateGroup проверяет, что оставляемый расход и дубликаты образуют одну группу по тем же
+// критериям, что и поиск: одна сумма, близкие даты и похожие описания
+func checkDuplicateGroup(keep *models.Expense, duplicates []*models.Expense, filter models.DuplicateFilter) error {
+	expenses := make([]models.Expense, 0, len(duplicates)+1)
+	expenses = append(expenses, *keep)
+	for _, duplicate := range duplicates {
+		if duplicate.Amount != keep.Amount {
+			return fmt.Errorf("расход %d: сумма %s отличается от суммы оставляемого расхода %s", duplicate.ID, duplicate.Amount, keep.Amount)
+		}
+		expenses = append(expenses, *duplicate)
+	}
+
+	groups := groupDuplicates(expenses, filter)
+	if len(groups) != 1 || len(groups[0].Expenses) != len(expenses) {
+		return errors.New("расходы не являются дубликатами друг друга: даты или описания слишком различаются")
+	}
+	return nil
+}
+
+// groupDuplicates объединяет расходы с одной суммой в группы. Два расхода связаны, если их даты
+// отличаются не больше чем на окно, описания достаточно похожи и они не являются разными операциями
+// одной банковской выписки. Группа — компонента связности, расходы в ней упорядочены по дате.
+func groupDuplicates(expenses []models.Expense, filter models.DuplicateFilter) []models.DuplicateGroup {
+	parent := make([]int, len(expenses))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	window := float64(filter.WindowDays) * 24
+	similarity := make(map[int]float64)
+	for i := range expenses {
+		for j := i + 1; j < len(expenses); j++ {
+			a, b := expenses[i], expenses[j]
+			if math.Abs(b.Date.Sub(a.Date).Hours()) > window {
+				continue
+			}
+			if a.ExternalID != nil && b.ExternalID != nil && *a.ExternalID != *b.ExternalID {
+				continue
+			}
+			score := descriptionSimilarity(a.Description, b.Description)
+			if score < filter.MinSimilarity {
+				continue
+			}
+
+			root := find(j)
+			other := find(i)
+			if root != other {
+				parent[other] = root
+				if value, ok := similarity[other]; ok {
+					similarity[root] = minSimilarity(similarity, root, value)
+				}
+			}
+			similarity[root] = minSimilarity(similarity, root, score)
+		}
+	}
+
+	members := make(map[int][]models.Expense)
+	var roots []int
+	for i := range expenses {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], expenses[i])
+	}
+
+	var groups []models.DuplicateGroup
+	for _, root := range roots {
+		if len(members[root]) < 2 {
+			continue
+		}
+		groups = append(groups, models.DuplicateGroup{
+			Amount:     members[root][0].Amount,
+			Similarity: math.Round(similarity[root]*100) / 100,
+			Expenses:   members[root],
+		})
+	}
+	return groups
+}
+
+func minSimilarity(values map[int]float64, key int, value float64) float64 {
+	if current, ok := values[key]; ok && current < value {
+		return current
+	}
+	return value
+}
+
+// descriptionSimilarity сравнивает описания по совпадению слов (коэффициент Жаккара).
+// Числа не учитываются, поэтому "STARBUCKS 1234" и "Starbucks" считаются одинаковыми.
+func descriptionSimilarity(a, b string) float64 {
+	wordsA := make(map[string]bool)
+	for _, word := range categoryFeatures(a, 0) {
+		wordsA[word] = true
+	}
+	wordsB := make(map[string]bool)
+	for _, word := range categoryFeatures(b, 0) {
+		wordsB[word] = true
+	}
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+
+	common := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wordsA)+len(wordsB)-common)
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ErrBulkExpenseRequest некорректные параметры массовой операции, итог по расходам в этом случае не формируется
+var ErrBulkExpenseRequest = errors.New("некорректная массовая операция")
+
+// bulkExpenseOperation изменение одного расхода массовой операции. prepare проверяет расход и меняет его
+// в памяти, ошибка prepare отмечает только этот расход как failed. save записывает измененный расход,
+// ошибка save откатывает всю операцию.
+type bulkExpenseOperation struct {
+	name    string
+	prepare func(expense *models.Expense) (models.BulkExpenseStatus, error)
+	save    func(expenses repository.ExpenseRepository, expense *models.Expense) error
+}
+
+func (s *expenseService) BulkRecategorize(userID uint, selection models.ExpenseSelection, categoryID uint) (*models.BulkExpenseResult, error) {
+	if err := validateExpenseCategory(s.categories, userID, categoryID); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBulkExpenseRequest, err.Error())
+	}
+
+	return s.runBulk(userID, selection, bulkExpenseOperation{
+		name: "recategorize",
+		prepare: func(expense *models.Expense) (models.BulkExpenseStatus, error) {
+			// Категории разделенного расхода задаются частями, как и при обычном обновлении
+			if len(expense.Splits) > 0 {
+				return "", errors.New("категории разделенного расхода задаются частями splits")
+			}
+			if expense.CategoryID == categoryID {
+				return models.BulkExpenseUnchanged, nil
+			}
+			expense.CategoryID = categoryID
+			return models.BulkExpenseUpdated, nil
+		},
+		save: func(expenses repository.ExpenseRepository, expense *models.Expense) error {
+			return expenses.Update(expense)
+		},
+	})
+}
+
+func (s *expenseService) BulkRewriteDescriptions(userID uint, selection models.ExpenseSelection, rewrite models.DescriptionRewrite) (*models.BulkExpenseResult, error) {
+	if (rewrite.Description == nil) == (rewrite.Find == "") {
+		return nil, fmt.Errorf("%w: укажите либо description, либо find и replace", ErrBulkExpenseRequest)
+	}
+
+	return s.runBulk(userID, selection, bulkExpenseOperation{
+		name: "rewrite_descriptions",
+		prepare: func(expense *models.Expense) (models.BulkExpenseStatus, error) {
+			description := strings.ReplaceAll(expense.Description, rewrite.Find, rewrite.Replace)
+			if rewrite.Description != nil {
+				description = *rewrite.Description
+			}
+			if description == expense.Description {
+				return models.BulkExpenseUnchanged, nil
+			}
+			expense.Description = description
+			return models.BulkExpenseUpdated, nil
+		},
+		save: func(expenses repository.ExpenseRepository, expense *models.Expense) error {
+			return expenses.Update(expense)
+		},
+	})
+}
+
+func (s *expenseService) BulkShiftDates(userID uint, selection models.ExpenseSelection, months, days int) (*models.BulkExpenseResult, error) {
+	if months == 0 && days == 0 {
+		return nil, fmt.Errorf("%w: укажите сдвиг months или days", ErrBulkExpenseRequest)
+	}
+
+	return s.runBulk(userID, selection, bulkExpenseOperation{
+		name: "shift_dates",
+		prepare: func(expense *models.Expense) (models.BulkExpenseStatus, error) {
+			expense.Date = shiftDate(expense.Date, months, days)
+			// Сумма в базовой валюте пересчитывается по курсу на новую дату, как при обновлении даты расхода
+			if expense.OriginalAmount == 0 {
+				expense.OriginalAmount = expense.Amount
+			}
+			if err := s.convertExpense(expense); err != nil {
+				return "", err
+			}
+			if len(expense.Splits) > 0 {
+				distributeSplitAmounts(expense, expense.Splits)
+			}
+			return models.BulkExpenseUpdated, nil
+		},
+		save: func(expenses repository.ExpenseRepository, expense *models.Expense) error {
+			if err := expenses.Update(expense); err != nil {
+				return err
+			}
+			if len(expense.Splits) > 0 {
+				return expenses.ReplaceSplits(expense, expense.Splits)
+			}
+			return nil
+		},
+	})
+}
+
+func (s *expenseService) BulkDelete(userID uint, selection models.ExpenseSelection) (*models.BulkExpenseResult, error) {
+	return s.runBulk(userID, selection, bulkExpenseOperation{
+		name: "delete",
+		prepare: func(expense *models.Expense) (models.BulkExpenseStatus, error) {
+			return models.BulkExpenseDeleted, nil
+		},
+		save: func(expenses repository.ExpenseRepository, expense *models.Expense) error {
+			// Расходы удаляются мягко и попадают в корзину, как при удалении по одному
+			return expenses.Delete(expense.ID)
+		},
+	})
+}
+
+// runBulk применяет операцию к выбранным расходам в одной транзакции. Строки расходов блокируются
+// до ее конца, поэтому проверка владельца и изменение не расходятся с параллельными запросами.
+// Ненайденные, чужие и неподходящие расходы попадают в отчет и не мешают изменению остальных.
+func (s *expenseService) runBulk(userID uint, selection models.ExpenseSelection, op bulkExpenseOperation) (*models.BulkExpenseResult, error) {
+	if (len(selection.IDs) == 0) == (selection.Filter == nil) {
+		return nil, fmt.Errorf("%w: укажите либо ids, либо filter", ErrBulkExpenseRequest)
+	}
+	if len(selection.IDs) > models.MaxBulkExpenses {
+		return nil, fmt.Errorf("%w: за один запрос можно изменить не больше %d расходов", ErrBulkExpenseRequest, models.MaxBulkExpenses)
+	}
+
+	var result *models.BulkExpenseResult
+	err := repository.RunInTransaction(func(tx repository.TxProvider) error {
+		expenses := s.expenses.WithTx(tx)
+
+		ids := uniqueIDs(selection.IDs)
+		if selection.Filter != nil {
+			filter := *selection.Filter
+			filter.UserID = userID
+			var err error
+			if ids, err = expenses.ListIDs(filter, models.MaxBulkExpenses+1); err != nil {
+				return err
+			}
+			if len(ids) > models.MaxBulkExpenses {
+				return fmt.Errorf("%w: под фильтр попадает больше %d расходов, уточните его", ErrBulkExpenseRequest, models.MaxBulkExpenses)
+			}
+		}
+
+		locked, err := expenses.GetForUpdate(ids)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uint]*models.Expense, len(locked))
+		for i := range locked {
+			byID[locked[i].ID] = &locked[i]
+		}
+
+		result = &models.BulkExpenseResult{Matched: len(ids), Items: make([]models.BulkExpenseItemResult, 0, len(ids))}
+		for _, id := range ids {
+			item := models.BulkExpenseItemResult{ID: id}
+			expense, ok := byID[id]
+			switch {
+			case !ok:
+				item.Status = models.BulkExpenseNotFound
+				item.Error = ErrExpenseNotFound.Error()
+			case expense.UserID != userID:
+				item.Status = models.BulkExpenseForbidden
+				item.Error = "access denied"
+			default:
+				status, err := op.prepare(expense)
+				if err != nil {
+					item.Status = models.BulkExpenseFailed
+					item.Error = err.Error()
+					break
+				}
+				item.Status = status
+				if status != models.BulkExpenseUnchanged {
+					if err := op.save(expenses, expense); err != nil {
+						return err
+					}
+				}
+			}
+
+			switch item.Status {
+			case models.BulkExpenseUpdated, models.BulkExpenseDeleted:
+				result.Changed++
+			case models.BulkExpenseUnchanged:
+				result.Unchanged++
+			default:
+				result.Failed++
+			}
+			result.Items = append(result.Items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrBulkExpenseRequest) {
+			s.logger.Error("expense bulk operation failed",
+				slog.String("op", "bulk_"+op.name),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("expense bulk operation completed",
+		slog.String("op", "bulk_"+op.name),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("matched", result.Matched),
+		slog.Int("changed", result.Changed),
+		slog.Int("failed", result.Failed),
+	)
+
+	return result, nil
+}
+
+// shiftDate сдвигает дату на months месяцев и days дней. При сдвиге на месяцы день ограничивается
+// последним днем месяца (31.01 + 1 месяц -> 28.02), время и часовой пояс сохраняются.
+func shiftDate(date time.Time, months, days int) time.Time {
+	if months != 0 {
+		firstOfMonth := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+		lastDayOfMonth := firstOfMonth.AddDate(0, 1, -1).Day()
+		day := date.Day()
+		if day > lastDayOfMonth {
+			day = lastDayOfMonth
+		}
+		date = time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day,
+			date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+	}
+	return date.AddDate(0, 0, days)
+}
+
+// uniqueIDs убирает повторы, сохраняя порядок
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -4,6 +4,7 @@ import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/repository"
 	"errors"
+	"fmt"
 	"log/slog"
 
 	"gorm.io/gorm"
@@ -13,22 +14,38 @@ var ErrExpenseNotFound = errors.New("расход не найден")
 
 type ExpenseService interface {
 	CreateExpense(userID uint, req models.CreateExpenseRequest) (*models.Expense, error)
-	GetExpenseList(filter models.ExpenseFilter) ([]models.Expense, error)
+	GetExpenseList(filter models.ExpenseFilter, page models.PageRequest) (*models.Page[models.Expense], error)
 	GetExpenseByID(id uint) (*models.Expense, error)
 	UpdateExpense(id uint, req models.UpdateExpenseRequest) (*models.Expense, error)
 	DeleteExpense(id uint) error
+	// BulkRecategorize переносит выбранные расходы в категорию
+	BulkRecategorize(userID uint, selection models.ExpenseSelection, categoryID uint) (*models.BulkExpenseResult, error)
+	// BulkRewriteDescriptions заменяет описания выбранных расходов
+	BulkRewriteDescriptions(userID uint, selection models.ExpenseSelection, rewrite models.DescriptionRewrite) (*models.BulkExpenseResult, error)
+	// BulkShiftDates сдвигает даты выбранных расходов и пересчитывает их суммы по курсу на новую дату
+	BulkShiftDates(userID uint, selection models.ExpenseSelection, months, days int) (*models.BulkExpenseResult, error)
+	// BulkDelete удаляет выбранные расходы в корзину
+	BulkDelete(userID uint, selection models.ExpenseSelection) (*models.BulkExpenseResult, error)
 }
 
 type expenseService struct {
 	expenses   repository.ExpenseRepository
 	categories repository.CategoryRepository
+	accounts   repository.AccountRepository
+	tags       repository.TagRepository
+	rules      repository.CategoryRuleRepository
+	currency   CurrencyService
 	logger     *slog.Logger
 }
 
-func NewExpenseService(expenses repository.ExpenseRepository, categories repository.CategoryRepository, logger *slog.Logger) ExpenseService {
+func NewExpenseService(expenses repository.ExpenseRepository, categories repository.CategoryRepository, accounts repository.AccountRepository, tags repository.TagRepository, rules repository.CategoryRuleRepository, currency CurrencyService, logger *slog.Logger) ExpenseService {
 	return &expenseService{
 		expenses:   expenses,
 		categories: categories,
+		accounts:   accounts,
+		tags:       tags,
+		rules:      rules,
+		currency:   currency,
 		logger:     logger,
 	}
 }
@@ -36,11 +53,11 @@ func NewExpenseService(expenses repository.ExpenseRepository, categories reposit
 
 func (s *expenseService) CreateExpense(userID uint, req models.CreateExpenseRequest) (*models.Expense, error) {
 
-	if err := s.validateExpenseCreate(req); err != nil {
+	if err := s.validateExpenseCreate(userID, req); err != nil {
 		s.logger.Warn("expense create validation failed",
 			slog.Uint64("user_id", uint64(userID)),
 			slog.Uint64("category_id", uint64(req.CategoryID)),
-			slog.Float64("amount", req.Amount),
+			slog.String("amount", req.Amount.String()),
 			slog.Time("date", req.Date),
 			slog.String("reason", err.Error()),
 		)
@@ -48,11 +65,51 @@ func (s *expenseService) CreateExpense(userID uint, req models.CreateExpenseRequ
 	}
 
 	expense := &models.Expense{
-		UserID:      userID,
-		CategoryID:  req.CategoryID,
-		Description: req.Description,
-		Date:        req.Date,
-		Amount:      req.Amount,
+		UserID:         userID,
+		AccountID:      req.AccountID,
+		CategoryID:     req.CategoryID,
+		Description:    req.Description,
+		Date:           req.Date,
+		Currency:       req.Currency,
+		OriginalAmount: req.Amount,
+	}
+	if len(req.TagIDs) > 0 {
+		tags, err := loadUserTags(s.tags, userID, req.TagIDs)
+		if err != nil {
+			return nil, err
+		}
+		expense.Tags = tags
+	}
+	if err := s.convertExpense(expense); err != nil {
+		s.logger.Warn("expense currency conversion failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("currency", req.Currency),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+	// Разделенный расход получает категорию наибольшей части, расход без категории и частей распределяется
+	// правилами по сумме в базовой валюте
+	if len(req.Splits) > 0 {
+		splits, err := s.buildSplits(expense, req.Splits)
+		if err != nil {
+			s.logger.Warn("expense splits validation failed",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Int("splits", len(req.Splits)),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
+		expense.Splits = splits
+	} else if expense.CategoryID == 0 {
+		if err := s.categorize(expense); err != nil {
+			s.logger.Warn("expense categorization failed",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("description", req.Description),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
 	}
 	if err := s.expenses.Create(expense); err != nil {
 		s.logger.Error("expense create failed",
@@ -66,14 +123,15 @@ func (s *expenseService) CreateExpense(userID uint, req models.CreateExpenseRequ
 		slog.Uint64("expense_id", uint64(expense.ID)),
 		slog.Uint64("user_id", uint64(userID)),
 		slog.Uint64("category_id", uint64(expense.CategoryID)),
-		slog.Float64("amount", expense.Amount),
+		slog.String("amount", expense.Amount.String()),
+		slog.String("currency", expense.Currency),
 		slog.Time("date", expense.Date),
 	)
 	return expense, nil
 }
 
-func (s *expenseService) GetExpenseList(filter models.ExpenseFilter) ([]models.Expense, error) {
-	expenses, err := s.expenses.List(filter)
+func (s *expenseService) GetExpenseList(filter models.ExpenseFilter, page models.PageRequest) (*models.Page[models.Expense], error) {
+	expenses, err := s.expenses.ListPage(filter, page)
 
 	if err != nil {
 		s.logger.Error("failed to list expenses",
@@ -84,7 +142,7 @@ func (s *expenseService) GetExpenseList(filter models.ExpenseFilter) ([]models.E
 	}
 
 	s.logger.Info("expenses listed",
-		slog.Int("count", len(expenses)),
+		slog.Int("count", len(expenses.Items)),
 	)
 
 	return expenses, nil
@@ -111,7 +169,7 @@ func (s *expenseService) GetExpenseByID(id uint) (*models.Expense, error) {
 	s.logger.Info("expense retrieved",
 		slog.Uint64("expense_id", uint64(expense.ID)),
 		slog.Uint64("category_id", uint64(expense.CategoryID)),
-		slog.Float64("amount", expense.Amount),
+		slog.String("amount", expense.Amount.String()),
 	)
 
 	return expense, nil
@@ -144,7 +202,54 @@ func (s *expenseService) UpdateExpense(id uint, req models.UpdateExpenseRequest)
 		return nil, err
 	}
 
-	if err := s.expenses.Update(expense); err != nil {
+	var tags []models.Tag
+	if req.TagIDs != nil {
+		tags, err = loadUserTags(s.tags, expense.UserID, *req.TagIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var splits []models.ExpenseSplit
+	replaceSplits := false
+	switch {
+	case req.Splits != nil:
+		if splits, err = s.buildSplits(expense, *req.Splits); err != nil {
+			s.logger.Warn("expense splits validation failed",
+				slog.Uint64("expense_id", uint64(id)),
+				slog.Int("splits", len(*req.Splits)),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
+		replaceSplits = true
+	case len(expense.Splits) > 0 && (req.Currency != nil || req.Date != nil):
+		// Курс на новую дату или для новой валюты другой, суммы частей в базовой валюте пересчитываются
+		splits = expense.Splits
+		distributeSplitAmounts(expense, splits)
+		replaceSplits = true
+	}
+
+	err = repository.RunInTransaction(func(tx repository.TxProvider) error {
+		expenses := s.expenses.WithTx(tx)
+		if err := expenses.Update(expense); err != nil {
+			return err
+		}
+		if req.TagIDs != nil {
+			if err := expenses.ReplaceTags(expense, tags); err != nil {
+				return err
+			}
+			expense.Tags = tags
+		}
+		if replaceSplits {
+			if err := expenses.ReplaceSplits(expense, splits); err != nil {
+				return err
+			}
+			expense.Splits = splits
+		}
+		return nil
+	})
+	if err != nil {
 		s.logger.Error("expense update failed",
 			slog.String("op", "update_expense"),
 			slog.Uint64("expense_id", uint64(expense.ID)),
@@ -155,7 +260,7 @@ func (s *expenseService) UpdateExpense(id uint, req models.UpdateExpenseRequest)
 	s.logger.Info("expense updated",
 		slog.Uint64("expense_id", uint64(expense.ID)),
 		slog.Uint64("category_id", uint64(expense.CategoryID)),
-		slog.Float64("amount", expense.Amount),
+		slog.String("amount", expense.Amount.String()),
 		slog.Time("date", expense.Date),
 	)
 	return expense, nil
@@ -179,6 +284,7 @@ func (s *expenseService) DeleteExpense(id uint) error {
 		return err
 	}
 
+	// Расход удаляется мягко и попадает в корзину, вложения остаются до его безвозвратного удаления
 	if err := s.expenses.Delete(id); err != nil {
 		s.logger.Error("expense delete failed",
 			slog.String("op", "delete_expense"),
@@ -195,31 +301,50 @@ func (s *expenseService) DeleteExpense(id uint) error {
 	return nil
 }
 
-func (s *expenseService) validateExpenseCreate(req models.CreateExpenseRequest) error {
+func (s *expenseService) validateExpenseCreate(userID uint, req models.CreateExpenseRequest) error {
 	if req.Amount <= 0 {
 		return errors.New("сумма должна быть больше нуля")
 	}
 
-	// Проверка существования категории
-	_, err := s.categories.GetByID(req.CategoryID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("категория не найдена")
+	// Категория должна принадлежать пользователю, без категории она будет выбрана правилами
+	if req.CategoryID != 0 {
+		if err := validateExpenseCategory(s.categories, userID, req.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	if req.AccountID != nil {
+		if err := validateAccountOwner(s.accounts, userID, *req.AccountID); err != nil {
+			return err
 		}
-		s.logger.Error("failed to check category existence",
-			slog.Uint64("category_id", uint64(req.CategoryID)),
-			slog.String("error", err.Error()),
-		)
-		return errors.New("ошибка при проверке категории")
 	}
 
 	return nil
 }
 
 func (s *expenseService) applyExpenseUpdate(expense *models.Expense, req models.UpdateExpenseRequest) error {
+	if req.AccountID != nil {
+		if err := validateAccountOwner(s.accounts, expense.UserID, *req.AccountID); err != nil {
+			return err
+		}
+		expense.AccountID = req.AccountID
+	}
+
+	// Категории и суммы разделенного расхода задаются частями, поэтому меняются только вместе с ними
+	if len(expense.Splits) > 0 && req.Splits == nil {
+		if req.CategoryID != nil && *req.CategoryID != expense.CategoryID {
+			return errors.New("категории разделенного расхода задаются частями splits")
+		}
+		if req.Amount != nil && *req.Amount != expense.OriginalAmount {
+			return errors.New("сумма разделенного расхода меняется вместе с частями splits")
+		}
+	}
+
 	if req.CategoryID != nil {
+		if err := validateExpenseCategory(s.categories, expense.UserID, *req.CategoryID); err != nil {
+			return err
+		}
 		expense.CategoryID = *req.CategoryID
-		// TODO: добавить валидацию существования категории при обновлении
 	}
 
 	if req.Description != nil {
@@ -230,12 +355,121 @@ func (s *expenseService) applyExpenseUpdate(expense *models.Expense, req models.
 		if *req.Amount <= 0 {
 			return errors.New("сумма должна быть больше нуля")
 		}
-		expense.Amount = *req.Amount
+		expense.OriginalAmount = *req.Amount
+	}
+
+	if req.Currency != nil {
+		expense.Currency = *req.Currency
 	}
 
 	if req.Date != nil {
 		expense.Date = *req.Date
 	}
 
+	// Сумма в базовой валюте пересчитывается при изменении суммы, валюты или даты
+	if req.Amount != nil || req.Currency != nil || req.Date != nil {
+		if expense.OriginalAmount == 0 {
+			expense.OriginalAmount = expense.Amount
+		}
+		return s.convertExpense(expense)
+	}
+
+	return nil
+}
+
+// categorize назначает расходу категорию и описание по первому подходящему правилу пользователя
+func (s *expenseService) categorize(expense *models.Expense) error {
+	rules, err := loadCategoryRules(s.rules, expense.UserID)
+	if err != nil {
+		return err
+	}
+
+	rule, description := matchCategoryRule(rules, expense.Description, expense.Amount, expense.Date)
+	if rule == nil {
+		return ErrNoMatchingRule
+	}
+	expense.CategoryID = rule.CategoryID
+	expense.Description = description
+	return nil
+}
+
+// buildSplits проверяет части расхода и пересчитывает их в базовую валюту по курсу расхода.
+// Суммы частей в валюте расхода должны давать сумму расхода. Пустой список означает расход без разделения.
+func (s *expenseService) buildSplits(expense *models.Expense, lines []models.ExpenseSplitRequest) ([]models.ExpenseSplit, error) {
+	if len(lines) == 0 {
+		return []models.ExpenseSplit{}, nil
+	}
+	if len(lines) < 2 {
+		return nil, errors.New("расход делится минимум на две части")
+	}
+
+	splits := make([]models.ExpenseSplit, 0, len(lines))
+	seen := make(map[uint]bool, len(lines))
+	var total models.Money
+	for _, line := range lines {
+		if line.Amount <= 0 {
+			return nil, errors.New("сумма части должна быть больше нуля")
+		}
+		if seen[line.CategoryID] {
+			return nil, errors.New("категории частей не должны повторяться")
+		}
+		seen[line.CategoryID] = true
+		if err := validateExpenseCategory(s.categories, expense.UserID, line.CategoryID); err != nil {
+			return nil, err
+		}
+		total += line.Amount
+		splits = append(splits, models.ExpenseSplit{
+			CategoryID:     line.CategoryID,
+			OriginalAmount: line.Amount,
+			Description:    line.Description,
+		})
+	}
+	if total != expense.OriginalAmount {
+		return nil, fmt.Errorf("сумма частей %s не совпадает с суммой расхода %s", total, expense.OriginalAmount)
+	}
+
+	distributeSplitAmounts(expense, splits)
+	return splits, nil
+}
+
+// distributeSplitAmounts пересчитывает части в базовую валюту. Разница округления относится к наибольшей части,
+// чтобы части в сумме давали сумму расхода, а ее категория становится основной категорией расхода.
+func distributeSplitAmounts(expense *models.Expense, splits []models.ExpenseSplit) {
+	largest := 0
+	var total models.Money
+	for i := range splits {
+		splits[i].Amount = splits[i].OriginalAmount.MulRate(expense.ExchangeRate)
+		total += splits[i].Amount
+		if splits[i].OriginalAmount > splits[largest].OriginalAmount {
+			largest = i
+		}
+	}
+	splits[largest].Amount += expense.Amount - total
+	expense.CategoryID = splits[largest].CategoryID
+}
+
+// convertExpense заполняет сумму расхода в базовой валюте пользователя по курсу на дату расхода.
+// Пустая валюта означает базовую валюту.
+func (s *expenseService) convertExpense(expense *models.Expense) error {
+	if expense.Currency == "" {
+		base, err := s.currency.GetBaseCurrency(expense.UserID)
+		if err != nil {
+			return err
+		}
+		expense.Currency = base
+	}
+
+	currency, err := normalizeCurrency(expense.Currency)
+	if err != nil {
+		return err
+	}
+	expense.Currency = currency
+
+	amount, rate, err := s.currency.ConvertToBase(expense.UserID, expense.OriginalAmount, currency, expense.Date)
+	if err != nil {
+		return err
+	}
+	expense.Amount = amount
+	expense.ExchangeRate = rate
 	return nil
 }
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidExport = errors.New("некорректные параметры выгрузки")
+
+type ExportService interface {
+	// ExportExpenses записывает расходы по фильтру в w построчно. Параметры проверяются до первой записи в w,
+	// поэтому при ErrInvalidExport ответ еще можно заменить сообщением об ошибке.
+	ExportExpenses(w io.Writer, filter models.ExpenseFilter, format models.ExportFormat, columns []string) error
+}
+
+type exportService struct {
+	expenses repository.ExpenseRepository
+	logger   *slog.Logger
+}
+
+func NewExportService(expenses repository.ExpenseRepository, logger *slog.Logger) ExportService {
+	return &exportService{expenses: expenses, logger: logger}
+}
+
+// exportColumn столбец выгрузки. Value возвращает string, uint, *uint, float64, models.Money или time.Time.
+type exportColumn struct {
+	Key   string
+	Value func(row *models.ExpenseExportRow) any
+}
+
+// expenseExportColumns доступные столбцы в порядке выгрузки по умолчанию
+var expenseExportColumns = []exportColumn{
+	{"id", func(row *models.ExpenseExportRow) any { return row.ID }},
+	{"date", func(row *models.ExpenseExportRow) any { return row.Date }},
+	{"amount", func(row *models.ExpenseExportRow) any { return row.Amount }},
+	{"currency", func(row *models.ExpenseExportRow) any { return row.Currency }},
+	{"original_amount", func(row *models.ExpenseExportRow) any { return row.OriginalAmount }},
+	{"exchange_rate", func(row *models.ExpenseExportRow) any { return row.ExchangeRate }},
+	{"description", func(row *models.ExpenseExportRow) any { return row.Description }},
+	{"category_id", func(row *models.ExpenseExportRow) any { return row.CategoryID }},
+	{"category", func(row *models.ExpenseExportRow) any { return row.CategoryName }},
+	{"category_color", func(row *models.ExpenseExportRow) any { return row.CategoryColor }},
+	{"account_id", func(row *models.ExpenseExportRow) any { return row.AccountID }},
+	{"account", func(row *models.ExpenseExportRow) any { return row.AccountName }},
+	{"tags", func(row *models.ExpenseExportRow) any { return row.Tags }},
+}
+
+// exportWriter записывает строки выгрузки в одном из форматов
+type exportWriter interface {
+	WriteHeader(keys []string) error
+	WriteRow(keys []string, values []any) error
+	Close() error
+}
+
+func (s *exportService) ExportExpenses(w io.Writer, filter models.ExpenseFilter, format models.ExportFormat, columns []string) error {
+	selected, err := resolveExportColumns(columns)
+	if err != nil {
+		return err
+	}
+
+	var writer exportWriter
+	switch format {
+	case models.ExportFormatCSV:
+		writer = &csvExportWriter{w: csv.NewWriter(w)}
+	case models.ExportFormatJSON:
+		writer = &jsonExportWriter{w: w}
+	case models.ExportFormatXLSX:
+		writer = newXLSXWriter(w)
+	default:
+		return fmt.Errorf("%w: неизвестный формат %q", ErrInvalidExport, format)
+	}
+
+	keys := make([]string, len(selected))
+	for i, column := range selected {
+		keys[i] = column.Key
+	}
+
+	if err := writer.WriteHeader(keys); err != nil {
+		return err
+	}
+
+	count := 0
+	values := make([]any, len(selected))
+	err = s.expenses.Stream(filter, func(row models.ExpenseExportRow) error {
+		for i, column := range selected {
+			values[i] = column.Value(&row)
+		}
+		count++
+		return writer.WriteRow(keys, values)
+	})
+	if err != nil {
+		s.logger.Error("expense export failed",
+			slog.String("op", "export_expenses"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("format", string(format)),
+			slog.Int("rows", count),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	s.logger.Info("expenses exported",
+		slog.Uint64("user_id", uint64(filter.UserID)),
+		slog.String("format", string(format)),
+		slog.Int("rows", count),
+	)
+	return nil
+}
+
+// resolveExportColumns возвращает выбранные столбцы в указанном порядке, без выбора — все столбцы
+func resolveExportColumns(columns []string) ([]exportColumn, error) {
+	if len(columns) == 0 {
+		return expenseExportColumns, nil
+	}
+
+	selected := make([]exportColumn, 0, len(columns))
+	seen := make(map[string]bool, len(columns))
+	for _, key := range columns {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" || seen[key] {
+			continue
+		}
+		found := false
+		for _, column := range expenseExportColumns {
+			if column.Key == key {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: неизвестный столбец %q", ErrInvalidExport, key)
+		}
+		seen[key] = true
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%w: не выбраны столбцы", ErrInvalidExport)
+	}
+	return selected, nil
+}
+
+// formatExportValue переводит значение столбца в текст для CSV
+func formatExportValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case *uint:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*v), 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case models.Money:
+		return v.String()
+	case time.Time:
+		return v.Format("2006-01-02")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type csvExportWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (e *csvExportWriter) WriteHeader(keys []string) error {
+	e.record = make([]string, len(keys))
+	return e.w.Write(keys)
+}
+
+func (e *csvExportWriter) WriteRow(keys []string, values []any) error {
+	for i, value := range values {
+		e.record[i] = formatExportValue(value)
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *csvExportWriter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonExportWriter пишет массив объектов по одному элементу, сохраняя порядок столбцов
+type jsonExportWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonExportWriter) WriteHeader(keys []string) error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonExportWriter) WriteRow(keys []string, values []any) error {
+	var b strings.Builder
+	if e.count > 0 {
+		b.WriteString(",")
+	}
+	b.WriteString("\n{")
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		b.Write(name)
+		b.WriteString(":")
+		b.Write(value)
+	}
+	b.WriteString("}")
+	e.count++
+
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *jsonExportWriter) Close() error {
+	_, err := io.WriteString(e.w, "\n]\n")
+	return err
+}
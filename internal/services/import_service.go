@@ -0,0 +1,625 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrImportInvalid = errors.New("файл содержит ошибки, расходы не сохранены")
+
+type ImportService interface {
+	// ImportExpensesCSV разбирает CSV по сопоставлению столбцов. В режиме dry_run только возвращает
+	// результат проверки, иначе сохраняет все строки в одной транзакции или не сохраняет ни одной.
+	ImportExpensesCSV(userID uint, r io.Reader, req models.CSVImportRequest) (*models.ImportResult, error)
+	// ImportStatement загружает списания из банковской выписки OFX/QFX или QIF как расходы.
+	// Поступления возвращаются отдельным списком credits, ранее загруженные операции пропускаются.
+	ImportStatement(userID uint, r io.Reader, format models.StatementFormat, req models.StatementImportRequest) (*models.ImportResult, error)
+}
+
+type importService struct {
+	expenses   repository.ExpenseRepository
+	categories repository.CategoryRepository
+	accounts   repository.AccountRepository
+	rules      repository.CategoryRuleRepository
+	currency   CurrencyService
+	logger     *slog.Logger
+}
+
+func NewImportService(
+	expenses repository.ExpenseRepository,
+	categories repository.CategoryRepository,
+	accounts repository.AccountRepository,
+	rules repository.CategoryRuleRepository,
+	currency CurrencyService,
+	logger *slog.Logger,
+) ImportService {
+	return &importService{
+		expenses:   expenses,
+		categories: categories,
+		accounts:   accounts,
+		rules:      rules,
+		currency:   currency,
+		logger:     logger,
+	}
+}
+
+// csvColumns индексы столбцов файла, -1 означает, что столбец не используется
+type csvColumns struct {
+	date        int
+	amount      int
+	description int
+	category    int
+}
+
+func (s *importService) ImportExpensesCSV(userID uint, r io.Reader, req models.CSVImportRequest) (*models.ImportResult, error) {
+	currency, err := s.importCurrency(userID, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if req.AccountID != nil {
+		if err := validateAccountOwner(s.accounts, userID, *req.AccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	categories, err := s.expenseCategoriesByName(userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.DefaultCategoryID != nil {
+		if !containsCategory(categories, *req.DefaultCategoryID) {
+			return nil, errors.New("категория по умолчанию не найдена")
+		}
+	}
+	rules, err := loadCategoryRules(s.rules, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+	if req.Delimiter != "" {
+		reader.Comma, _ = utf8.DecodeRuneInString(req.Delimiter)
+	}
+
+	layout := "2006-01-02"
+	if req.DateFormat != "" {
+		layout = dateLayout(req.DateFormat)
+	}
+
+	result := &models.ImportResult{
+		DryRun:            req.DryRun,
+		Rows:              []models.ImportRow{},
+		UnknownCategories: []string{},
+		Errors:            []string{},
+	}
+	unknown := make(map[string]bool)
+	var pending []models.Expense
+	var columns *csvColumns
+	line := 0
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+		}
+
+		if columns == nil {
+			var header []string
+			if req.HasHeader {
+				header = record
+			}
+			columns, err = resolveCSVColumns(req, header)
+			if err != nil {
+				return nil, err
+			}
+			if req.HasHeader {
+				continue
+			}
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+		result.Total++
+
+		row, err := parseCSVRecord(record, *columns, layout)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("строка %d: %s", line, err.Error()))
+			continue
+		}
+		row.Line = line
+
+		amount, rate, err := s.currency.ConvertToBase(userID, row.Amount, currency, row.Date)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("строка %d: %s", line, err.Error()))
+			continue
+		}
+
+		switch {
+		case row.CategoryName == "":
+			// Строка без категории распределяется правилами, затем попадает в категорию по умолчанию
+			if rule, description := matchCategoryRule(rules, row.Description, amount, row.Date); rule != nil {
+				categoryID := rule.CategoryID
+				row.CategoryID = &categoryID
+				row.Description = description
+			} else if req.DefaultCategoryID != nil {
+				row.CategoryID = req.DefaultCategoryID
+			} else {
+				result.Errors = append(result.Errors, fmt.Sprintf("строка %d: не указана категория", line))
+				continue
+			}
+		default:
+			if category, ok := categories[strings.ToLower(row.CategoryName)]; ok {
+				row.CategoryID = &category.ID
+			} else if !unknown[strings.ToLower(row.CategoryName)] {
+				unknown[strings.ToLower(row.CategoryName)] = true
+				result.UnknownCategories = append(result.UnknownCategories, row.CategoryName)
+			}
+		}
+
+		result.Rows = append(result.Rows, row)
+		pending = append(pending, models.Expense{
+			UserID:         userID,
+			AccountID:      req.AccountID,
+			Amount:         amount,
+			Currency:       currency,
+			OriginalAmount: row.Amount,
+			ExchangeRate:   rate,
+			Description:    row.Description,
+			Date:           row.Date,
+		})
+	}
+	result.Valid = len(result.Rows)
+
+	if req.DryRun {
+		s.logger.Info("expense import checked",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Int("total", result.Total),
+			slog.Int("valid", result.Valid),
+			slog.Int("errors", len(result.Errors)),
+			slog.Int("unknown_categories", len(result.UnknownCategories)),
+		)
+		return result, nil
+	}
+
+	// Неизвестные категории создаются или заменяются категорией по умолчанию, иначе импорт невозможен
+	if len(result.Errors) > 0 || (len(result.UnknownCategories) > 0 && !req.CreateCategories && req.DefaultCategoryID == nil) {
+		s.logger.Warn("expense import rejected",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Int("errors", len(result.Errors)),
+			slog.Int("unknown_categories", len(result.UnknownCategories)),
+		)
+		return result, ErrImportInvalid
+	}
+	if len(pending) == 0 {
+		return nil, errors.New("в файле нет строк для импорта")
+	}
+
+	err = repository.RunInTransaction(func(tx repository.TxProvider) error {
+		expenses := s.expenses.WithTx(tx)
+		categoryRepo := s.categories.WithTx(tx)
+
+		if req.CreateCategories {
+			for _, name := range result.UnknownCategories {
+				category := &models.Category{
+					UserID: userID,
+					Name:   name,
+					Color:  "#3B82F6",
+					Type:   models.CategoryTypeExpense,
+				}
+				if err := categoryRepo.Create(category); err != nil {
+					return err
+				}
+				categories[strings.ToLower(name)] = *category
+			}
+		}
+
+		for i := range pending {
+			row := &result.Rows[i]
+			if row.CategoryID == nil {
+				if category, ok := categories[strings.ToLower(row.CategoryName)]; ok {
+					id := category.ID
+					row.CategoryID = &id
+				} else {
+					row.CategoryID = req.DefaultCategoryID
+				}
+			}
+			pending[i].CategoryID = *row.CategoryID
+
+			if err := expenses.Create(&pending[i]); err != nil {
+				return fmt.Errorf("строка %d: %w", row.Line, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("expense import failed",
+			slog.String("op", "import_expenses_csv"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	result.Imported = len(pending)
+
+	s.logger.Info("expenses imported",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("imported", result.Imported),
+		slog.Int("unknown_categories", len(result.UnknownCategories)),
+		slog.Bool("create_categories", req.CreateCategories),
+	)
+
+	return result, nil
+}
+
+func (s *importService) ImportStatement(userID uint, r io.Reader, format models.StatementFormat, req models.StatementImportRequest) (*models.ImportResult, error) {
+	var (
+		transactions []statementTransaction
+		parseErrors  []statementParseError
+		err          error
+		label        string
+	)
+	switch format {
+	case models.StatementFormatOFX:
+		transactions, parseErrors, err = parseOFX(r)
+		label = "операция"
+	case models.StatementFormatQIF:
+		transactions, parseErrors, err = parseQIF(r, req.DateFormat)
+		label = "строка"
+	default:
+		return nil, errors.New("неподдерживаемый формат выписки")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defaultCurrency, err := s.importCurrency(userID, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if req.AccountID != nil {
+		if err := validateAccountOwner(s.accounts, userID, *req.AccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	categories, err := s.expenseCategoriesByName(userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.CategoryID != nil && !containsCategory(categories, *req.CategoryID) {
+		return nil, errors.New("категория не найдена")
+	}
+	rules, err := loadCategoryRules(s.rules, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ImportResult{
+		DryRun:            req.DryRun,
+		Rows:              []models.ImportRow{},
+		UnknownCategories: []string{},
+		Errors:            []string{},
+		Credits:           []models.ImportRow{},
+	}
+	for _, parseErr := range parseErrors {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s %d: %s", label, parseErr.Line, parseErr.Err.Error()))
+	}
+	result.Total = len(transactions) + len(parseErrors)
+
+	// Повторная загрузка пересекающейся выписки не создает дубликатов
+	externalIDs := make([]string, 0, len(transactions))
+	for _, t := range transactions {
+		if t.ExternalID != "" && t.Amount < 0 {
+			externalIDs = append(externalIDs, t.ExternalID)
+		}
+	}
+	existingIDs, err := s.expenses.FindExternalIDs(userID, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		seen[id] = true
+	}
+
+	unknown := make(map[string]bool)
+	var pending []models.Expense
+
+	for _, t := range transactions {
+		row := models.ImportRow{
+			Line:         t.Line,
+			Date:         t.Date,
+			Amount:       t.Amount,
+			Description:  t.description(),
+			CategoryName: t.Category,
+			ExternalID:   t.ExternalID,
+		}
+
+		if t.Amount > 0 {
+			result.Credits = append(result.Credits, row)
+			continue
+		}
+		row.Amount = -t.Amount
+
+		if t.ExternalID != "" {
+			if seen[t.ExternalID] {
+				result.Duplicates++
+				continue
+			}
+			seen[t.ExternalID] = true
+		}
+
+		currency := defaultCurrency
+		if t.Currency != "" {
+			if currency, err = normalizeCurrency(t.Currency); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s %d: %s", label, t.Line, err.Error()))
+				continue
+			}
+		}
+		amount, rate, err := s.currency.ConvertToBase(userID, row.Amount, currency, row.Date)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s %d: %s", label, t.Line, err.Error()))
+			continue
+		}
+
+		// Категория из выписки, затем правила пользователя, затем категория из запроса
+		if category, ok := categories[strings.ToLower(t.Category)]; ok && t.Category != "" {
+			row.CategoryID = &category.ID
+		} else {
+			if t.Category != "" && !unknown[strings.ToLower(t.Category)] {
+				unknown[strings.ToLower(t.Category)] = true
+				result.UnknownCategories = append(result.UnknownCategories, t.Category)
+			}
+			if rule, description := matchCategoryRule(rules, row.Description, amount, row.Date); rule != nil {
+				categoryID := rule.CategoryID
+				row.CategoryID = &categoryID
+				row.Description = description
+			} else if req.CategoryID != nil {
+				row.CategoryID = req.CategoryID
+			} else {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s %d: не удалось определить категорию, укажите category_id", label, t.Line))
+				continue
+			}
+		}
+
+		var externalID *string
+		if t.ExternalID != "" {
+			id := t.ExternalID
+			externalID = &id
+		}
+
+		result.Rows = append(result.Rows, row)
+		pending = append(pending, models.Expense{
+			UserID:         userID,
+			AccountID:      req.AccountID,
+			CategoryID:     *row.CategoryID,
+			Amount:         amount,
+			Currency:       currency,
+			OriginalAmount: row.Amount,
+			ExchangeRate:   rate,
+			Description:    row.Description,
+			Date:           row.Date,
+			ExternalID:     externalID,
+		})
+	}
+	result.Valid = len(result.Rows)
+
+	if req.DryRun {
+		s.logger.Info("statement import checked",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("format", string(format)),
+			slog.Int("total", result.Total),
+			slog.Int("valid", result.Valid),
+			slog.Int("duplicates", result.Duplicates),
+			slog.Int("credits", len(result.Credits)),
+			slog.Int("errors", len(result.Errors)),
+		)
+		return result, nil
+	}
+
+	if len(result.Errors) > 0 {
+		s.logger.Warn("statement import rejected",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("format", string(format)),
+			slog.Int("errors", len(result.Errors)),
+		)
+		return result, ErrImportInvalid
+	}
+
+	err = repository.RunInTransaction(func(tx repository.TxProvider) error {
+		expenses := s.expenses.WithTx(tx)
+		for i := range pending {
+			if err := expenses.Create(&pending[i]); err != nil {
+				return fmt.Errorf("%s %d: %w", label, result.Rows[i].Line, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("statement import failed",
+			slog.String("op", "import_statement"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("format", string(format)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	result.Imported = len(pending)
+
+	s.logger.Info("statement imported",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("format", string(format)),
+		slog.Int("imported", result.Imported),
+		slog.Int("duplicates", result.Duplicates),
+		slog.Int("credits", len(result.Credits)),
+	)
+
+	return result, nil
+}
+
+// importCurrency возвращает валюту файла, по умолчанию базовую валюту пользователя
+func (s *importService) importCurrency(userID uint, currency string) (string, error) {
+	if currency == "" {
+		return s.currency.GetBaseCurrency(userID)
+	}
+	return normalizeCurrency(currency)
+}
+
+// expenseCategoriesByName возвращает категории расходов пользователя по названию в нижнем регистре
+func (s *importService) expenseCategoriesByName(userID uint) (map[string]models.Category, error) {
+	list, err := s.categories.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list categories for import",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	categories := make(map[string]models.Category, len(list))
+	for _, category := range list {
+		if category.Type == models.CategoryTypeIncome {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(category.Name))
+		if _, exists := categories[key]; !exists {
+			categories[key] = category
+		}
+	}
+	return categories, nil
+}
+
+func containsCategory(categories map[string]models.Category, id uint) bool {
+	for _, category := range categories {
+		if category.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCSVColumns сопоставляет параметры запроса со столбцами файла.
+// Столбец задается номером с 1 или названием из заголовка без учета регистра.
+func resolveCSVColumns(req models.CSVImportRequest, header []string) (*csvColumns, error) {
+	names := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		names[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	resolve := func(field, value string, required bool) (int, error) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			if required {
+				return -1, fmt.Errorf("не указан столбец %s", field)
+			}
+			return -1, nil
+		}
+		if number, err := strconv.Atoi(value); err == nil {
+			if number < 1 {
+				return -1, fmt.Errorf("номер столбца %s должен начинаться с 1", field)
+			}
+			return number - 1, nil
+		}
+		if index, ok := names[strings.ToLower(value)]; ok {
+			return index, nil
+		}
+		return -1, fmt.Errorf("столбец %q не найден в заголовке", value)
+	}
+
+	var columns csvColumns
+	var err error
+	if columns.date, err = resolve("date_column", req.DateColumn, true); err != nil {
+		return nil, err
+	}
+	if columns.amount, err = resolve("amount_column", req.AmountColumn, true); err != nil {
+		return nil, err
+	}
+	if columns.description, err = resolve("description_column", req.DescriptionColumn, false); err != nil {
+		return nil, err
+	}
+	if columns.category, err = resolve("category_column", req.CategoryColumn, false); err != nil {
+		return nil, err
+	}
+	return &columns, nil
+}
+
+func parseCSVRecord(record []string, columns csvColumns, layout string) (models.ImportRow, error) {
+	var row models.ImportRow
+
+	field := func(index int) (string, bool) {
+		if index < 0 || index >= len(record) {
+			return "", false
+		}
+		return strings.TrimSpace(record[index]), true
+	}
+
+	rawDate, ok := field(columns.date)
+	if !ok || rawDate == "" {
+		return row, errors.New("не указана дата")
+	}
+	date, err := time.Parse(layout, rawDate)
+	if err != nil {
+		return row, fmt.Errorf("некорректная дата %q", rawDate)
+	}
+	row.Date = date
+
+	rawAmount, ok := field(columns.amount)
+	if !ok || rawAmount == "" {
+		return row, errors.New("не указана сумма")
+	}
+	amount, err := parseImportAmount(rawAmount)
+	if err != nil {
+		return row, err
+	}
+	row.Amount = amount
+
+	row.Description, _ = field(columns.description)
+	row.CategoryName, _ = field(columns.category)
+
+	return row, nil
+}
+
+// parseImportAmount разбирает сумму расхода из таблицы.
+// Знак отбрасывается, так как выгрузки часто записывают расходы отрицательными числами.
+func parseImportAmount(raw string) (models.Money, error) {
+	amount, err := parseSignedAmount(raw)
+	if err != nil {
+		return 0, err
+	}
+	if amount < 0 {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// dateLayout переводит формат вида DD.MM.YYYY в шаблон time.Parse
+func dateLayout(format string) string {
+	return strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02").Replace(format)
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
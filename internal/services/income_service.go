@@ -0,0 +1,275 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var ErrIncomeNotFound = errors.New("доход не найден")
+
+type IncomeService interface {
+	CreateIncome(userID uint, req models.CreateIncomeRequest) (*models.Income, error)
+	GetIncomeList(filter models.IncomeFilter) ([]models.Income, error)
+	GetIncomeByID(id uint) (*models.Income, error)
+	UpdateIncome(id uint, req models.UpdateIncomeRequest) (*models.Income, error)
+	DeleteIncome(id uint) error
+}
+
+type incomeService struct {
+	incomes    repository.IncomeRepository
+	categories repository.CategoryRepository
+	accounts   repository.AccountRepository
+	logger     *slog.Logger
+}
+
+func NewIncomeService(incomes repository.IncomeRepository, categories repository.CategoryRepository, accounts repository.AccountRepository, logger *slog.Logger) IncomeService {
+	return &incomeService{
+		incomes:    incomes,
+		categories: categories,
+		accounts:   accounts,
+		logger:     logger,
+	}
+}
+
+func (s *incomeService) CreateIncome(userID uint, req models.CreateIncomeRequest) (*models.Income, error) {
+
+	if err := s.validateIncomeCreate(userID, req); err != nil {
+		s.logger.Warn("income create validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Uint64("category_id", uint64(req.CategoryID)),
+			slog.String("amount", req.Amount.String()),
+			slog.Time("date", req.Date),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	income := &models.Income{
+		UserID:      userID,
+		AccountID:   req.AccountID,
+		CategoryID:  req.CategoryID,
+		Description: req.Description,
+		Date:        req.Date,
+		Amount:      req.Amount,
+	}
+	if err := s.incomes.Create(income); err != nil {
+		s.logger.Error("income create failed",
+			slog.String("op", "create_income"),
+			slog.Any("request", req),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.logger.Info("income created",
+		slog.Uint64("income_id", uint64(income.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("category_id", uint64(income.CategoryID)),
+		slog.String("amount", income.Amount.String()),
+		slog.Time("date", income.Date),
+	)
+	return income, nil
+}
+
+func (s *incomeService) GetIncomeList(filter models.IncomeFilter) ([]models.Income, error) {
+	incomes, err := s.incomes.List(filter)
+
+	if err != nil {
+		s.logger.Error("failed to list incomes",
+			slog.String("op", "list_incomes"),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("incomes listed",
+		slog.Int("count", len(incomes)),
+	)
+
+	return incomes, nil
+}
+
+func (s *incomeService) GetIncomeByID(id uint) (*models.Income, error) {
+	income, err := s.incomes.GetByID(id)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("income not found",
+				slog.Uint64("income_id", uint64(id)),
+			)
+			return nil, ErrIncomeNotFound
+		}
+		s.logger.Error("failed to get income",
+			slog.String("op", "get_income_by_id"),
+			slog.Uint64("income_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("income retrieved",
+		slog.Uint64("income_id", uint64(income.ID)),
+		slog.Uint64("category_id", uint64(income.CategoryID)),
+		slog.String("amount", income.Amount.String()),
+	)
+
+	return income, nil
+}
+
+func (s *incomeService) UpdateIncome(id uint, req models.UpdateIncomeRequest) (*models.Income, error) {
+	income, err := s.incomes.GetByID(id)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("income not found for update",
+				slog.Uint64("income_id", uint64(id)),
+			)
+			return nil, ErrIncomeNotFound
+		}
+		s.logger.Error("failed to fetch income before update",
+			slog.String("op", "update_income"),
+			slog.Uint64("income_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.applyIncomeUpdate(income, req); err != nil {
+		s.logger.Warn("income update validation failed",
+			slog.Uint64("income_id", uint64(id)),
+			slog.Any("request", req),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.incomes.Update(income); err != nil {
+		s.logger.Error("income update failed",
+			slog.String("op", "update_income"),
+			slog.Uint64("income_id", uint64(income.ID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.logger.Info("income updated",
+		slog.Uint64("income_id", uint64(income.ID)),
+		slog.Uint64("category_id", uint64(income.CategoryID)),
+		slog.String("amount", income.Amount.String()),
+		slog.Time("date", income.Date),
+	)
+	return income, nil
+}
+
+func (s *incomeService) DeleteIncome(id uint) error {
+	_, err := s.incomes.GetByID(id)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("income not found for delete",
+				slog.Uint64("income_id", uint64(id)),
+			)
+			return ErrIncomeNotFound
+		}
+		s.logger.Error("failed to fetch income before delete",
+			slog.String("op", "delete_income"),
+			slog.Uint64("income_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	if err := s.incomes.Delete(id); err != nil {
+		s.logger.Error("income delete failed",
+			slog.String("op", "delete_income"),
+			slog.Uint64("income_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("income deleted",
+		slog.Uint64("income_id", uint64(id)),
+	)
+
+	return nil
+}
+
+func (s *incomeService) validateIncomeCreate(userID uint, req models.CreateIncomeRequest) error {
+	if req.Amount <= 0 {
+		return errors.New("сумма должна быть больше нуля")
+	}
+
+	if err := s.validateIncomeCategory(userID, req.CategoryID); err != nil {
+		return err
+	}
+
+	if req.AccountID != nil {
+		if err := validateAccountOwner(s.accounts, userID, *req.AccountID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateIncomeCategory проверяет, что категория существует, принадлежит пользователю и предназначена для доходов
+func (s *incomeService) validateIncomeCategory(userID, categoryID uint) error {
+	category, err := s.categories.GetByID(categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("категория не найдена")
+		}
+		s.logger.Error("failed to check category existence",
+			slog.Uint64("category_id", uint64(categoryID)),
+			slog.String("error", err.Error()),
+		)
+		return errors.New("ошибка при проверке категории")
+	}
+
+	// Чужая категория для пользователя не существует
+	if category.UserID != userID {
+		return errors.New("категория не найдена")
+	}
+
+	if category.Type != models.CategoryTypeIncome {
+		return errors.New("категория не предназначена для доходов")
+	}
+
+	return nil
+}
+
+func (s *incomeService) applyIncomeUpdate(income *models.Income, req models.UpdateIncomeRequest) error {
+	if req.AccountID != nil {
+		if err := validateAccountOwner(s.accounts, income.UserID, *req.AccountID); err != nil {
+			return err
+		}
+		income.AccountID = req.AccountID
+	}
+
+	if req.CategoryID != nil {
+		if err := s.validateIncomeCategory(income.UserID, *req.CategoryID); err != nil {
+			return err
+		}
+		income.CategoryID = *req.CategoryID
+	}
+
+	if req.Description != nil {
+		income.Description = *req.Description
+	}
+
+	if req.Amount != nil {
+		if *req.Amount <= 0 {
+			return errors.New("сумма должна быть больше нуля")
+		}
+		income.Amount = *req.Amount
+	}
+
+	if req.Date != nil {
+		income.Date = *req.Date
+	}
+
+	return nil
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInstallmentPlanNotFound = errors.New("план рассрочки не найден")
+
+type InstallmentService interface {
+	// CreateInstallmentPlan создает план и сразу создает расходами платежи, дата которых уже наступила
+	CreateInstallmentPlan(userID uint, req models.CreateInstallmentPlanRequest) (*models.InstallmentPlan, error)
+	GetInstallmentPlanList(userID uint) ([]models.InstallmentPlan, error)
+	GetInstallmentPlanByID(id uint) (*models.InstallmentPlan, error)
+	// GetInstallmentSchedule возвращает полный график платежей плана
+	GetInstallmentSchedule(plan *models.InstallmentPlan) []models.InstallmentPayment
+	// GetUpcomingPayments возвращает еще не созданные платежи всех планов пользователя с датой не позже until
+	GetUpcomingPayments(userID uint, until time.Time) ([]models.InstallmentPayment, error)
+	UpdateInstallmentPlan(id uint, req models.UpdateInstallmentPlanRequest) (*models.InstallmentPlan, error)
+	DeleteInstallmentPlan(id uint) error
+	// ProcessDuePayments создает расходами платежи, дата которых наступила к now
+	ProcessDuePayments(now time.Time) error
+}
+
+type installmentService struct {
+	plans      repository.InstallmentPlanRepository
+	expenses   repository.ExpenseRepository
+	categories repository.CategoryRepository
+	accounts   repository.AccountRepository
+	currency   CurrencyService
+	logger     *slog.Logger
+}
+
+func NewInstallmentService(plans repository.InstallmentPlanRepository, expenses repository.ExpenseRepository, categories repository.CategoryRepository, accounts repository.AccountRepository, currency CurrencyService, logger *slog.Logger) InstallmentService {
+	return &installmentService{
+		plans:      plans,
+		expenses:   expenses,
+		categories: categories,
+		accounts:   accounts,
+		currency:   currency,
+		logger:     logger,
+	}
+}
+
+func (s *installmentService) CreateInstallmentPlan(userID uint, req models.CreateInstallmentPlanRequest) (*models.InstallmentPlan, error) {
+	if req.Payments < 1 || req.Payments > models.MaxInstallmentPayments {
+		return nil, fmt.Errorf("количество платежей должно быть от 1 до %d", models.MaxInstallmentPayments)
+	}
+	if req.TotalAmount <= 0 {
+		return nil, errors.New("сумма должна быть больше нуля")
+	}
+	if req.TotalAmount < models.Money(req.Payments) {
+		return nil, errors.New("сумма слишком мала для такого количества платежей")
+	}
+	if err := validateExpenseCategory(s.categories, userID, req.CategoryID); err != nil {
+		return nil, err
+	}
+	if req.AccountID != nil {
+		if err := validateAccountOwner(s.accounts, userID, *req.AccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	startDate := req.StartDate
+	plan := &models.InstallmentPlan{
+		UserID:      userID,
+		CategoryID:  req.CategoryID,
+		AccountID:   req.AccountID,
+		Description: req.Description,
+		TotalAmount: req.TotalAmount,
+		Payments:    req.Payments,
+		StartDate:   startDate,
+		NextDate:    &startDate,
+	}
+
+	// Покупка, начатая в прошлом, сразу получает все наступившие платежи
+	err := repository.RunInTransaction(func(tx repository.TxProvider) error {
+		if err := s.plans.WithTx(tx).Create(plan); err != nil {
+			return err
+		}
+		_, err := s.createDuePayments(tx, plan, time.Now())
+		return err
+	})
+	if err != nil {
+		s.logger.Error("installment plan create failed",
+			slog.String("op", "create_installment_plan"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("installment plan created",
+		slog.Uint64("installment_plan_id", uint64(plan.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("total_amount", plan.TotalAmount.String()),
+		slog.Int("payments", plan.Payments),
+		slog.Int("payments_made", plan.PaymentsMade),
+	)
+
+	return s.GetInstallmentPlanByID(plan.ID)
+}
+
+func (s *installmentService) GetInstallmentPlanList(userID uint) ([]models.InstallmentPlan, error) {
+	plans, err := s.plans.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list installment plans",
+			slog.String("op", "list_installment_plans"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.fillInstallmentAmounts(plans); err != nil {
+		s.logger.Error("failed to get installment paid amounts",
+			slog.String("op", "list_installment_plans"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (s *installmentService) GetInstallmentPlanByID(id uint) (*models.InstallmentPlan, error) {
+	plan, err := s.plans.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInstallmentPlanNotFound
+		}
+		s.logger.Error("failed to get installment plan",
+			slog.String("op", "get_installment_plan_by_id"),
+			slog.Uint64("installment_plan_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	plans := []models.InstallmentPlan{*plan}
+	if err := s.fillInstallmentAmounts(plans); err != nil {
+		s.logger.Error("failed to get installment paid amounts",
+			slog.String("op", "get_installment_plan_by_id"),
+			slog.Uint64("installment_plan_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &plans[0], nil
+}
+
+func (s *installmentService) GetInstallmentSchedule(plan *models.InstallmentPlan) []models.InstallmentPayment {
+	schedule := make([]models.InstallmentPayment, 0, plan.Payments)
+	for number := 1; number <= plan.Payments; number++ {
+		schedule = append(schedule, installmentPayment(plan, number))
+	}
+	return schedule
+}
+
+func (s *installmentService) GetUpcomingPayments(userID uint, until time.Time) ([]models.InstallmentPayment, error) {
+	plans, err := s.plans.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list installment plans",
+			slog.String("op", "get_upcoming_installment_payments"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	payments := []models.InstallmentPayment{}
+	for i := range plans {
+		for number := plans[i].PaymentsMade + 1; number <= plans[i].Payments; number++ {
+			payment := installmentPayment(&plans[i], number)
+			if payment.Date.After(until) {
+				break
+			}
+			payments = append(payments, payment)
+		}
+	}
+	sort.SliceStable(payments, func(i, j int) bool {
+		return payments[i].Date.Before(payments[j].Date)
+	})
+	return payments, nil
+}
+
+func (s *installmentService) UpdateInstallmentPlan(id uint, req models.UpdateInstallmentPlanRequest) (*models.InstallmentPlan, error) {
+	plan, err := s.GetInstallmentPlanByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Сумма и график не меняются: часть платежей уже создана расходами
+	if req.CategoryID != nil {
+		if err := validateExpenseCategory(s.categories, plan.UserID, *req.CategoryID); err != nil {
+			return nil, err
+		}
+		plan.CategoryID = *req.CategoryID
+	}
+	if req.AccountID != nil {
+		if err := validateAccountOwner(s.accounts, plan.UserID, *req.AccountID); err != nil {
+			return nil, err
+		}
+		plan.AccountID = req.AccountID
+	}
+	if req.Description != nil {
+		plan.Description = *req.Description
+	}
+
+	if err := s.plans.Update(plan); err != nil {
+		s.logger.Error("installment plan update failed",
+			slog.String("op", "update_installment_plan"),
+			slog.Uint64("installment_plan_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("installment plan updated",
+		slog.Uint64("installment_plan_id", uint64(id)),
+		slog.Uint64("category_id", uint64(plan.CategoryID)),
+	)
+
+	return s.GetInstallmentPlanByID(id)
+}
+
+func (s *installmentService) DeleteInstallmentPlan(id uint) error {
+	if _, err := s.GetInstallmentPlanByID(id); err != nil {
+		return err
+	}
+
+	// Созданные платежи остаются расходами, следующие платежи больше не создаются
+	if err := s.plans.Delete(id); err != nil {
+		s.logger.Error("installment plan delete failed",
+			slog.String("op", "delete_installment_plan"),
+			slog.Uint64("installment_plan_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("installment plan deleted",
+		slog.Uint64("installment_plan_id", uint64(id)),
+	)
+	return nil
+}
+
+func (s *installmentService) ProcessDuePayments(now time.Time) error {
+	due, err := s.plans.GetDue(now)
+	if err != nil {
+		s.logger.Error("failed to get due installment plans",
+			slog.String("op", "process_installment_payments"),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	for _, plan := range due {
+		var created int
+		// План блокируется, чтобы параллельный запуск не создал тот же платеж дважды
+		err := repository.RunInTransaction(func(tx repository.TxProvider) error {
+			locked, err := s.plans.WithTx(tx).GetForUpdate(plan.ID)
+			if err != nil {
+				return err
+			}
+			created, err = s.createDuePayments(tx, locked, now)
+			return err
+		})
+		if err != nil {
+			s.logger.Error("process installment plan failed",
+				slog.Uint64("installment_plan_id", uint64(plan.ID)),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		s.logger.Info("processed installment plan",
+			slog.Uint64("installment_plan_id", uint64(plan.ID)),
+			slog.Int("payments_created", created),
+		)
+	}
+
+	return nil
+}
+
+// createDuePayments создает расходы для платежей плана с датой не позже now и сдвигает дату следующего платежа.
+// Платежи создаются в базовой валюте пользователя, как и регулярные расходы.
+func (s *installmentService) createDuePayments(tx repository.TxProvider, plan *models.InstallmentPlan, now time.Time) (int, error) {
+	if plan.NextDate == nil || plan.NextDate.After(now) {
+		return 0, nil
+	}
+
+	baseCurrency, err := s.currency.GetBaseCurrency(plan.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("get base currency for installment plan %d: %w", plan.ID, err)
+	}
+
+	expenses := s.expenses.WithTx(tx)
+	created := 0
+	for plan.PaymentsMade < plan.Payments {
+		payment := installmentPayment(plan, plan.PaymentsMade+1)
+		if payment.Date.After(now) {
+			break
+		}
+
+		expense := &models.Expense{
+			UserID:            plan.UserID,
+			AccountID:         plan.AccountID,
+			CategoryID:        plan.CategoryID,
+			Amount:            payment.Amount,
+			Currency:          baseCurrency,
+			OriginalAmount:    payment.Amount,
+			ExchangeRate:      1,
+			Description:       installmentPaymentDescription(plan, payment.Number),
+			Date:              payment.Date,
+			InstallmentPlanID: &plan.ID,
+		}
+		if err := expenses.Create(expense); err != nil {
+			return created, fmt.Errorf("create payment %d of installment plan %d: %w", payment.Number, plan.ID, err)
+		}
+		plan.PaymentsMade = payment.Number
+		created++
+	}
+
+	plan.NextDate = nil
+	if plan.PaymentsMade < plan.Payments {
+		next := installmentPayment(plan, plan.PaymentsMade+1).Date
+		plan.NextDate = &next
+	}
+	if err := s.plans.WithTx(tx).Update(plan); err != nil {
+		return created, fmt.Errorf("update installment plan %d: %w", plan.ID, err)
+	}
+	return created, nil
+}
+
+// installmentPayment возвращает платеж плана с номером number, начиная с 1. Платежи списываются
+// в день первого платежа каждого месяца (31-е число в коротком месяце становится последним днем).
+// Платежи равны с округлением вниз до копейки, остаток добавляется к последнему, чтобы в сумме они давали полную сумму плана.
+func installmentPayment(plan *models.InstallmentPlan, number int) models.InstallmentPayment {
+	amount := plan.TotalAmount / models.Money(plan.Payments)
+	if number == plan.Payments {
+		amount = plan.TotalAmount - amount*models.Money(plan.Payments-1)
+	}
+	return models.InstallmentPayment{
+		PlanID:      plan.ID,
+		Description: plan.Description,
+		Number:      number,
+		Date:        shiftDate(plan.StartDate, number-1, 0),
+		Amount:      amount,
+		Paid:        number <= plan.PaymentsMade,
+	}
+}
+
+// fillInstallmentAmounts заполняет ежемесячный платеж, выплаченную сумму и остаток планов.
+// Выплаченная сумма считается по существующим расходам-платежам, поэтому учитывает
+// их изменение и удаление.
+func (s *installmentService) fillInstallmentAmounts(plans []models.InstallmentPlan) error {
+	ids := make([]uint, len(plans))
+	for i := range plans {
+		ids[i] = plans[i].ID
+	}
+	paid, err := s.plans.GetPaidAmounts(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range plans {
+		plan := &plans[i]
+		plan.PaymentAmount = plan.TotalAmount / models.Money(plan.Payments)
+		plan.PaidAmount = paid[plan.ID].Amount
+		plan.RemainingAmount = plan.TotalAmount - plan.PaidAmount
+		plan.RemainingPayments = max(plan.Payments-paid[plan.ID].Payments, 0)
+	}
+	return nil
+}
+
+func installmentPaymentDescription(plan *models.InstallmentPlan, number int) string {
+	description := plan.Description
+	if description == "" {
+		description = "Рассрочка"
+	}
+	return fmt.Sprintf("%s: платеж %d из %d", description, number, plan.Payments)
+}
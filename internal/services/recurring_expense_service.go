@@ -15,7 +15,7 @@ var ErrRecurringExpenseNotFound = errors.New("регулярный расход
 
 type RecurringExpenseService interface {
 	CreateRecurringExpense(userID uint, req models.CreateRecurringExpenseRequest) (*models.RecurringExpense, error)
-	GetRecurringExpenseList(userID uint) ([]models.RecurringExpense, error)
+	GetRecurringExpenseList(userID uint, page models.PageRequest) (*models.Page[models.RecurringExpense], error)
 	GetRecurringExpenseByID(id uint) (*models.RecurringExpense, error)
 	GetActiveRecurringExpenses(userID uint) ([]models.RecurringExpense, error)
 	UpdateRecurringExpense(id uint, req models.UpdateRecurringExpenseRequest) (*models.RecurringExpense, error)
@@ -29,6 +29,8 @@ type RecurringExpenseService interface {
 type recurringExpenseService struct {
 	recurringExpenses repository.RecurringExpenseRepository
 	expenses          repository.ExpenseRepository
+	tags              repository.TagRepository
+	currency          CurrencyService
 	notifier          NotificationService
 	logger            *slog.Logger
 }
@@ -36,12 +38,16 @@ type recurringExpenseService struct {
 func NewRecurringExpenseService(
 	recurringExpenses repository.RecurringExpenseRepository,
 	expenses repository.ExpenseRepository,
+	tags repository.TagRepository,
+	currency CurrencyService,
 	notifier NotificationService,
 	logger *slog.Logger,
 ) RecurringExpenseService {
 	return &recurringExpenseService{
 		recurringExpenses: recurringExpenses,
 		expenses:          expenses,
+		tags:              tags,
+		currency:          currency,
 		notifier:          notifier,
 		logger:            logger,
 	}
@@ -71,6 +77,13 @@ func (s *recurringExpenseService) CreateRecurringExpense(userID uint, req models
 		IsActive:    true,
 		NextDate:    nextDate,
 	}
+	if len(req.TagIDs) > 0 {
+		tags, err := loadUserTags(s.tags, userID, req.TagIDs)
+		if err != nil {
+			return nil, err
+		}
+		recurringExpense.Tags = tags
+	}
 
 	if err := s.recurringExpenses.Create(recurringExpense); err != nil {
 		s.logger.Error("recurring expense create failed",
@@ -91,8 +104,8 @@ func (s *recurringExpenseService) CreateRecurringExpense(userID uint, req models
 
 	if s.notifier != nil {
 		go func() {
-			msg := fmt.Sprintf("🔁 Создан регулярный расход: %.2f ₽ (%s). Следующая дата: %s",
-				recurringExpense.Amount,
+			msg := fmt.Sprintf("🔁 Создан регулярный расход: %s (%s). Следующая дата: %s",
+				s.formatAmount(userID, recurringExpense.Amount),
 				recurringExpense.Description,
 				recurringExpense.NextDate.Format("02.01.2006"),
 			)
@@ -108,8 +121,8 @@ func (s *recurringExpenseService) CreateRecurringExpense(userID uint, req models
 	return recurringExpense, nil
 }
 
-func (s *recurringExpenseService) GetRecurringExpenseList(userID uint) ([]models.RecurringExpense, error) {
-	recurringExpenses, err := s.recurringExpenses.GetByUserID(userID)
+func (s *recurringExpenseService) GetRecurringExpenseList(userID uint, page models.PageRequest) (*models.Page[models.RecurringExpense], error) {
+	recurringExpenses, err := s.recurringExpenses.ListPage(userID, page)
 	if err != nil {
 		s.logger.Error("failed to list recurring expenses",
 			slog.String("op", "list_recurring_expenses"),
@@ -121,7 +134,7 @@ func (s *recurringExpenseService) GetRecurringExpenseList(userID uint) ([]models
 
 	s.logger.Info("recurring expenses listed",
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Int("count", len(recurringExpenses)),
+		slog.Int("count", len(recurringExpenses.Items)),
 	)
 
 	return recurringExpenses, nil
@@ -208,7 +221,28 @@ func (s *recurringExpenseService) UpdateRecurringExpense(id uint, req models.Upd
 		recurringExpense.NextDate = s.CalculateNextDate(recurringExpense)
 	}
 
-	if err := s.recurringExpenses.Update(recurringExpense); err != nil {
+	var tags []models.Tag
+	if req.TagIDs != nil {
+		tags, err = loadUserTags(s.tags, recurringExpense.UserID, *req.TagIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = repository.RunInTransaction(func(tx repository.TxProvider) error {
+		recurringExpenses := s.recurringExpenses.WithTx(tx)
+		if err := recurringExpenses.Update(recurringExpense); err != nil {
+			return err
+		}
+		if req.TagIDs != nil {
+			if err := recurringExpenses.ReplaceTags(recurringExpense, tags); err != nil {
+				return err
+			}
+			recurringExpense.Tags = tags
+		}
+		return nil
+	})
+	if err != nil {
 		s.logger.Error("recurring expense update failed",
 			slog.String("op", "update_recurring_expense"),
 			slog.Uint64("recurring_expense_id", uint64(recurringExpense.ID)),
@@ -281,8 +315,8 @@ func (s *recurringExpenseService) ActivateRecurringExpense(id uint) (*models.Rec
 
 	if s.notifier != nil {
 		go func() {
-			msg := fmt.Sprintf("✅ Регулярный расход включен: %.2f ₽ (%s). Следующая дата: %s",
-				recurringExpense.Amount,
+			msg := fmt.Sprintf("✅ Регулярный расход включен: %s (%s). Следующая дата: %s",
+				s.formatAmount(recurringExpense.UserID, recurringExpense.Amount),
 				recurringExpense.Description,
 				recurringExpense.NextDate.Format("02.01.2006"),
 			)
@@ -336,13 +370,22 @@ func (s *recurringExpenseService) ProcessRecurringExpenses() error {
 
 	for _, recurringExpense := range dueRecurringExpenses {
 		err = repository.RunInTransaction(func(tx repository.TxProvider) error {
-			// Создаем расход
+			baseCurrency, err := s.currency.GetBaseCurrency(recurringExpense.UserID)
+			if err != nil {
+				return fmt.Errorf("get base currency for recurring %d: %w", recurringExpense.ID, err)
+			}
+
+			// Создаем расход, сумма регулярного расхода задана в базовой валюте
 			expense := &models.Expense{
-				UserID:      recurringExpense.UserID,
-				CategoryID:  recurringExpense.CategoryID,
-				Amount:      recurringExpense.Amount,
-				Description: recurringExpense.Description,
-				Date:        recurringExpense.NextDate,
+				UserID:         recurringExpense.UserID,
+				CategoryID:     recurringExpense.CategoryID,
+				Amount:         recurringExpense.Amount,
+				Currency:       baseCurrency,
+				OriginalAmount: recurringExpense.Amount,
+				ExchangeRate:   1,
+				Description:    recurringExpense.Description,
+				Date:           recurringExpense.NextDate,
+				Tags:           recurringExpense.Tags,
 			}
 
 			if err := s.expenses.WithTx(tx).Create(expense); err != nil {
@@ -357,8 +400,8 @@ func (s *recurringExpenseService) ProcessRecurringExpenses() error {
 
 			// Уведомление после commit
 			if s.notifier != nil {
-				msg := fmt.Sprintf("🔁 Сегодня списание: %.2f ₽ (%s)%s",
-					recurringExpense.Amount,
+				msg := fmt.Sprintf("🔁 Сегодня списание: %s (%s)%s",
+					models.FormatAmount(recurringExpense.Amount, baseCurrency),
 					recurringExpense.Description,
 					func() string {
 						if recurringExpense.Category.Name != "" {
@@ -381,7 +424,7 @@ func (s *recurringExpenseService) ProcessRecurringExpenses() error {
 
 			s.logger.Info("processed recurring expense",
 				slog.Uint64("recurring_expense_id", uint64(recurringExpense.ID)),
-				slog.Float64("amount", recurringExpense.Amount),
+				slog.String("amount", recurringExpense.Amount.String()),
 				slog.Time("next_date", recurringExpense.NextDate),
 			)
 			return nil
@@ -574,3 +617,12 @@ func (s *recurringExpenseService) calculateInitialNextDate(
 		return now.AddDate(0, 0, 1)
 	}
 }
+
+// formatAmount форматирует сумму в базовой валюте пользователя для уведомлений
+func (s *recurringExpenseService) formatAmount(userID uint, amount models.Money) string {
+	currency, err := s.currency.GetBaseCurrency(userID)
+	if err != nil {
+		currency = models.DefaultCurrency
+	}
+	return models.FormatAmount(amount, currency)
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"bufio"
+	"cashcontrol/internal/models"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// statementTransaction операция банковской выписки. Отрицательная сумма означает списание.
+type statementTransaction struct {
+	Line       int
+	ExternalID string
+	Date       time.Time
+	Amount     models.Money
+	Payee      string
+	Memo       string
+	Category   string
+	Currency   string
+}
+
+// description собирает описание расхода из получателя и комментария операции
+func (t statementTransaction) description() string {
+	switch {
+	case t.Payee == "":
+		return t.Memo
+	case t.Memo == "" || strings.EqualFold(t.Payee, t.Memo):
+		return t.Payee
+	default:
+		return t.Payee + " — " + t.Memo
+	}
+}
+
+// statementParseError ошибка разбора отдельной операции, остальные операции файла при этом читаются
+type statementParseError struct {
+	Line int
+	Err  error
+}
+
+var ofxTagPattern = regexp.MustCompile(`<(/?)([A-Za-z0-9.]+)>([^<]*)`)
+
+// parseOFX читает выписку OFX/QFX версий 1.x (SGML без закрывающих тегов) и 2.x (XML)
+func parseOFX(r io.Reader) ([]statementTransaction, []statementParseError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+	content := string(data)
+	if !strings.Contains(strings.ToUpper(content), "<OFX>") {
+		return nil, nil, errors.New("файл не является выпиской OFX")
+	}
+
+	var (
+		transactions []statementTransaction
+		parseErrors  []statementParseError
+		current      map[string]string
+		currency     string
+		accountID    string
+		number       int
+	)
+
+	for _, match := range ofxTagPattern.FindAllStringSubmatch(content, -1) {
+		closing := match[1] == "/"
+		tag := strings.ToUpper(match[2])
+		value := strings.TrimSpace(match[3])
+
+		switch {
+		case tag == "STMTTRN" && !closing:
+			current = map[string]string{}
+		case tag == "STMTTRN" && closing:
+			if current == nil {
+				continue
+			}
+			number++
+			transaction, err := buildOFXTransaction(current, number, accountID, currency)
+			if err != nil {
+				parseErrors = append(parseErrors, statementParseError{Line: number, Err: err})
+			} else {
+				transactions = append(transactions, transaction)
+			}
+			current = nil
+		case closing:
+			continue
+		case current != nil:
+			current[tag] = decodeOFXValue(value)
+		case tag == "CURDEF":
+			currency = value
+		case tag == "ACCTID":
+			accountID = value
+		}
+	}
+
+	return transactions, parseErrors, nil
+}
+
+func buildOFXTransaction(fields map[string]string, number int, accountID, currency string) (statementTransaction, error) {
+	transaction := statementTransaction{
+		Line:     number,
+		Payee:    fields["NAME"],
+		Memo:     fields["MEMO"],
+		Currency: currency,
+	}
+	if transaction.Payee == "" {
+		transaction.Payee = fields["PAYEE"]
+	}
+	if value := fields["CURRENCY"]; value != "" {
+		transaction.Currency = value
+	}
+
+	posted := fields["DTPOSTED"]
+	if len(posted) < 8 {
+		return transaction, errors.New("не указана дата операции")
+	}
+	date, err := time.Parse("20060102", posted[:8])
+	if err != nil {
+		return transaction, fmt.Errorf("некорректная дата %q", posted)
+	}
+	transaction.Date = date
+
+	amount, err := parseSignedAmount(fields["TRNAMT"])
+	if err != nil {
+		return transaction, err
+	}
+	transaction.Amount = amount
+
+	if fitID := fields["FITID"]; fitID != "" {
+		transaction.ExternalID = "ofx:" + accountID + ":" + fitID
+	}
+	return transaction, nil
+}
+
+// decodeOFXValue раскрывает сущности SGML, которые банки используют в названиях операций
+func decodeOFXValue(value string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'").Replace(value)
+}
+
+// qifDateLayouts форматы дат QIF, которые перебираются, если формат не задан явно
+var qifDateLayouts = []string{"01/02/2006", "1/2/2006", "01/02/06", "1/2/06", "02.01.2006", "2.1.2006", "2006-01-02"}
+
+// parseQIF читает выписку QIF. Записи разделяются строкой "^", поля начинаются с буквы-кода.
+// QIF не содержит идентификаторов операций, поэтому они строятся из содержимого записи.
+func parseQIF(r io.Reader, dateFormat string) ([]statementTransaction, []statementParseError, error) {
+	layouts := qifDateLayouts
+	if dateFormat != "" {
+		layouts = []string{dateLayout(dateFormat)}
+	}
+
+	var (
+		transactions []statementTransaction
+		parseErrors  []statementParseError
+		fields       = map[byte]string{}
+		recordLine   int
+		line         int
+		header       bool
+	)
+
+	finish := func() {
+		defer func() { fields = map[byte]string{} }()
+		if len(fields) == 0 {
+			return
+		}
+		transaction, err := buildQIFTransaction(fields, recordLine, layouts)
+		if err != nil {
+			parseErrors = append(parseErrors, statementParseError{Line: recordLine, Err: err})
+			return
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "!") {
+			header = true
+			continue
+		}
+		if text == "^" {
+			finish()
+			continue
+		}
+		if len(fields) == 0 {
+			recordLine = line
+		}
+		code := text[0]
+		// Сплиты (S, E, $) и адреса (A) не используются
+		if _, exists := fields[code]; !exists {
+			fields[code] = strings.TrimSpace(text[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+	finish()
+
+	if !header && len(transactions) == 0 && len(parseErrors) == 0 {
+		return nil, nil, errors.New("файл не является выпиской QIF")
+	}
+
+	assignQIFExternalIDs(transactions)
+	return transactions, parseErrors, nil
+}
+
+func buildQIFTransaction(fields map[byte]string, line int, layouts []string) (statementTransaction, error) {
+	transaction := statementTransaction{
+		Line:     line,
+		Payee:    fields['P'],
+		Memo:     fields['M'],
+		Category: fields['L'],
+	}
+	// Перевод между счетами записывается как [Название счета]
+	if strings.HasPrefix(transaction.Category, "[") {
+		transaction.Category = ""
+	}
+
+	rawDate := strings.ReplaceAll(fields['D'], "'", "/")
+	if rawDate == "" {
+		return transaction, errors.New("не указана дата операции")
+	}
+	parsed := false
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, strings.ReplaceAll(rawDate, " ", "")); err == nil {
+			transaction.Date = date
+			parsed = true
+			break
+		}
+	}
+	if !parsed {
+		return transaction, fmt.Errorf("некорректная дата %q", fields['D'])
+	}
+
+	rawAmount := fields['T']
+	if rawAmount == "" {
+		rawAmount = fields['U']
+	}
+	amount, err := parseSignedAmount(rawAmount)
+	if err != nil {
+		return transaction, err
+	}
+	transaction.Amount = amount
+
+	return transaction, nil
+}
+
+// assignQIFExternalIDs строит идентификаторы операций по дате, сумме, получателю и комментарию.
+// Одинаковые записи в одном файле различаются порядковым номером.
+func assignQIFExternalIDs(transactions []statementTransaction) {
+	occurrences := make(map[string]int)
+	for i := range transactions {
+		t := &transactions[i]
+		key := strings.Join([]string{t.Date.Format("2006-01-02"), t.Amount.String(), t.Payee, t.Memo}, "|")
+		occurrences[key]++
+		sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d", key, occurrences[key])))
+		t.ExternalID = "qif:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// parseSignedAmount разбирает сумму со знаком в записи "1 234,56", "-1,234.56" или "1234.56"
+func parseSignedAmount(raw string) (models.Money, error) {
+	value := strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "").Replace(strings.TrimSpace(raw))
+	if value == "" {
+		return 0, errors.New("не указана сумма")
+	}
+	if strings.Contains(value, ",") {
+		if strings.Contains(value, ".") {
+			value = strings.ReplaceAll(value, ",", "")
+		} else {
+			value = strings.ReplaceAll(value, ",", ".")
+		}
+	}
+
+	amount, err := models.ParseMoney(value)
+	if err != nil {
+		return 0, fmt.Errorf("некорректная сумма %q", raw)
+	}
+	if amount == 0 {
+		return 0, errors.New("сумма должна быть больше нуля")
+	}
+	return amount, nil
+}
@@ -89,7 +89,7 @@ func (s *statisticsService) GetStatistics(
 	
 	// Вычисляем среднее значение
 	if stats.Count > 0 {
-		stats.AverageAmount = stats.TotalAmount / float64(stats.Count)
+		stats.AverageAmount = stats.TotalAmount.Div(stats.Count)
 	} else {
 		stats.AverageAmount = 0
 	}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrTagNotFound = errors.New("тег не найден")
+
+type TagService interface {
+	CreateTag(userID uint, req models.CreateTagRequest) (*models.Tag, error)
+	GetTagList(userID uint) ([]models.Tag, error)
+	GetTagByID(id uint) (*models.Tag, error)
+	UpdateTag(id uint, req models.UpdateTagRequest) (*models.Tag, error)
+	DeleteTag(id uint) error
+}
+
+type tagService struct {
+	tags   repository.TagRepository
+	logger *slog.Logger
+}
+
+func NewTagService(tags repository.TagRepository, logger *slog.Logger) TagService {
+	return &tagService{tags: tags, logger: logger}
+}
+
+func (s *tagService) CreateTag(userID uint, req models.CreateTagRequest) (*models.Tag, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.validateTagName(userID, 0, name); err != nil {
+		s.logger.Warn("tag create validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("name", req.Name),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	color := req.Color
+	if color == "" {
+		color = "#6B7280" // Default цвет
+	}
+
+	tag := &models.Tag{
+		UserID: userID,
+		Name:   name,
+		Color:  color,
+	}
+
+	if err := s.tags.Create(tag); err != nil {
+		s.logger.Error("tag create failed",
+			slog.String("op", "create_tag"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("tag created",
+		slog.Uint64("tag_id", uint64(tag.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("name", tag.Name),
+	)
+
+	return tag, nil
+}
+
+func (s *tagService) GetTagList(userID uint) ([]models.Tag, error) {
+	tags, err := s.tags.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list tags",
+			slog.String("op", "list_tags"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (s *tagService) GetTagByID(id uint) (*models.Tag, error) {
+	tag, err := s.tags.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("tag not found",
+				slog.Uint64("tag_id", uint64(id)),
+			)
+			return nil, ErrTagNotFound
+		}
+		s.logger.Error("failed to get tag",
+			slog.String("op", "get_tag_by_id"),
+			slog.Uint64("tag_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *tagService) UpdateTag(id uint, req models.UpdateTagRequest) (*models.Tag, error) {
+	tag, err := s.GetTagByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := s.validateTagName(tag.UserID, tag.ID, name); err != nil {
+			return nil, err
+		}
+		tag.Name = name
+	}
+	if req.Color != nil {
+		tag.Color = *req.Color
+	}
+
+	if err := s.tags.Update(tag); err != nil {
+		s.logger.Error("tag update failed",
+			slog.String("op", "update_tag"),
+			slog.Uint64("tag_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("tag updated",
+		slog.Uint64("tag_id", uint64(id)),
+	)
+
+	return tag, nil
+}
+
+func (s *tagService) DeleteTag(id uint) error {
+	if _, err := s.GetTagByID(id); err != nil {
+		return err
+	}
+
+	if err := s.tags.Delete(id); err != nil {
+		s.logger.Error("tag delete failed",
+			slog.String("op", "delete_tag"),
+			slog.Uint64("tag_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("tag deleted",
+		slog.Uint64("tag_id", uint64(id)),
+	)
+
+	return nil
+}
+
+// validateTagName проверяет, что название не пустое и не совпадает с другим тегом пользователя без учета регистра
+func (s *tagService) validateTagName(userID, tagID uint, name string) error {
+	if name == "" {
+		return errors.New("название тега не может быть пустым")
+	}
+
+	tags, err := s.tags.GetByUserID(userID)
+	if err != nil {
+		return errors.New("ошибка при проверке тегов")
+	}
+	for _, tag := range tags {
+		if tag.ID != tagID && strings.EqualFold(tag.Name, name) {
+			return errors.New("тег с таким названием уже существует")
+		}
+	}
+	return nil
+}
+
+// loadUserTags возвращает теги пользователя по идентификаторам и проверяет, что все они существуют.
+// Используется сервисами расходов и регулярных расходов при привязке тегов.
+func loadUserTags(tags repository.TagRepository, userID uint, ids []uint) ([]models.Tag, error) {
+	unique := make([]uint, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	result, err := tags.GetByIDs(userID, unique)
+	if err != nil {
+		return nil, errors.New("ошибка при проверке тегов")
+	}
+	if len(result) != len(unique) {
+		return nil, ErrTagNotFound
+	}
+	return result, nil
+}
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTrashItemNotFound = errors.New("запись не найдена в корзине")
+	ErrTrashRestore      = errors.New("запись нельзя восстановить")
+	ErrTrashItemInUse    = errors.New("на категорию ссылаются другие записи, сначала удалите их из корзины")
+)
+
+// trashPurgeBatchSize сколько просроченных записей удаляется за один проход фоновой очистки
+const trashPurgeBatchSize = 500
+
+type TrashService interface {
+	// GetTrash возвращает удаленные записи пользователя, пустой itemType — записи всех видов
+	GetTrash(userID uint, itemType models.TrashItemType) ([]models.TrashItem, error)
+	GetTrashItem(itemType models.TrashItemType, id uint) (*models.TrashItem, error)
+	// RestoreItem восстанавливает запись, предварительно проверив, что записи, на которые она ссылается,
+	// не удалены. Восстановленная категория, родитель которой удален, становится корневой.
+	RestoreItem(item *models.TrashItem) error
+	// PurgeItem безвозвратно удаляет запись, у расхода вместе с файлами вложений
+	PurgeItem(item *models.TrashItem) error
+	// EmptyTrash безвозвратно удаляет все записи корзины пользователя
+	EmptyTrash(userID uint) (*models.TrashPurgeResult, error)
+	// PurgeExpired безвозвратно удаляет записи всех пользователей, которые лежат в корзине дольше срока хранения
+	PurgeExpired(now time.Time) (*models.TrashPurgeResult, error)
+}
+
+type trashService struct {
+	trash         repository.TrashRepository
+	categories    repository.CategoryRepository
+	accounts      repository.AccountRepository
+	budgets       repository.BudgetRepository
+	recurring     RecurringExpenseService
+	attachments   AttachmentService
+	retentionDays int
+	logger        *slog.Logger
+}
+
+func NewTrashService(
+	trash repository.TrashRepository,
+	categories repository.CategoryRepository,
+	accounts repository.AccountRepository,
+	budgets repository.BudgetRepository,
+	recurring RecurringExpenseService,
+	attachments AttachmentService,
+	retentionDays int,
+	logger *slog.Logger,
+) TrashService {
+	return &trashService{
+		trash:         trash,
+		categories:    categories,
+		accounts:      accounts,
+		budgets:       budgets,
+		recurring:     recurring,
+		attachments:   attachments,
+		retentionDays: retentionDays,
+		logger:        logger,
+	}
+}
+
+func (s *trashService) GetTrash(userID uint, itemType models.TrashItemType) ([]models.TrashItem, error) {
+	items, err := s.trash.List(userID, itemType)
+	if err != nil {
+		s.logger.Error("failed to list trash",
+			slog.String("op", "get_trash"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	for i := range items {
+		s.setPurgeAt(&items[i])
+	}
+	return items, nil
+}
+
+func (s *trashService) GetTrashItem(itemType models.TrashItemType, id uint) (*models.TrashItem, error) {
+	item, err := s.trash.GetItem(itemType, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTrashItemNotFound
+		}
+		return nil, err
+	}
+	s.setPurgeAt(item)
+	return item, nil
+}
+
+// setPurgeAt заполняет время безвозвратного удаления, если срок хранения задан
+func (s *trashService) setPurgeAt(item *models.TrashItem) {
+	if s.retentionDays > 0 {
+		purgeAt := item.DeletedAt.AddDate(0, 0, s.retentionDays)
+		item.PurgeAt = &purgeAt
+	}
+}
+
+func (s *trashService) RestoreItem(item *models.TrashItem) error {
+	var err error
+	switch item.Type {
+	case models.TrashItemExpense:
+		err = s.restoreExpense(item.ID)
+	case models.TrashItemCategory:
+		err = s.restoreCategory(item.ID)
+	case models.TrashItemBudget:
+		err = s.restoreBudget(item.ID)
+	case models.TrashItemRecurringExpense:
+		err = s.restoreRecurringExpense(item.ID)
+	default:
+		err = fmt.Errorf("неизвестный вид записи корзины %q", item.Type)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTrashItemNotFound
+	}
+	if errors.Is(err, ErrTrashRestore) {
+		s.logger.Warn("trash restore rejected",
+			slog.String("type", string(item.Type)),
+			slog.Uint64("id", uint64(item.ID)),
+			slog.String("reason", err.Error()),
+		)
+		return err
+	}
+	if err != nil {
+		s.logger.Error("trash restore failed",
+			slog.String("op", "restore_trash_item"),
+			slog.String("type", string(item.Type)),
+			slog.Uint64("id", uint64(item.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("trash item restored",
+		slog.String("type", string(item.Type)),
+		slog.Uint64("id", uint64(item.ID)),
+		slog.Uint64("user_id", uint64(item.UserID)),
+	)
+	return nil
+}
+
+func (s *trashService) restoreExpense(id uint) error {
+	expense, err := s.trash.GetExpense(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkCategory(expense.UserID, expense.CategoryID); err != nil {
+		return err
+	}
+	for _, split := range expense.Splits {
+		if err := s.checkCategory(expense.UserID, split.CategoryID); err != nil {
+			return err
+		}
+	}
+	if expense.AccountID != nil {
+		account, err := s.accounts.GetByID(*expense.AccountID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: счет расхода удален", ErrTrashRestore)
+			}
+			return err
+		}
+		if account.UserID != expense.UserID {
+			return fmt.Errorf("%w: счет расхода удален", ErrTrashRestore)
+		}
+	}
+
+	return s.trash.RestoreExpense(id)
+}
+
+// checkCategory проверяет, что категория, на которую ссылается запись, не удалена
+func (s *trashService) checkCategory(userID, categoryID uint) error {
+	category, err := s.categories.GetByID(categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: категория %d удалена, сначала восстановите ее", ErrTrashRestore, categoryID)
+		}
+		return err
+	}
+	if category.UserID != userID {
+		return fmt.Errorf("%w: категория %d удалена, сначала восстановите ее", ErrTrashRestore, categoryID)
+	}
+	return nil
+}
+
+func (s *trashService) restoreCategory(id uint) error {
+	category, err := s.trash.GetCategory(id)
+	if err != nil {
+		return err
+	}
+
+	// Подкатегории удаленной категории перешли к ее родителю, поэтому она восстанавливается без них
+	// и обычно остается листом. Если родитель удален или вложенность превысит предел, категория
+	// становится корневой, а не остается недоступной в корзине.
+	parentID := category.ParentID
+	if parentID != nil {
+		categories, err := s.categories.GetByUserID(category.UserID)
+		if err != nil {
+			return err
+		}
+		parentAvailable := false
+		for _, c := range categories {
+			if c.ID == *parentID && c.Type == category.Type {
+				parentAvailable = categoryDepth(c.ID, categories)+1 <= models.MaxCategoryDepth
+				break
+			}
+		}
+		if !parentAvailable {
+			parentID = nil
+		}
+	}
+
+	return s.trash.RestoreCategory(id, parentID)
+}
+
+func (s *trashService) restoreBudget(id uint) error {
+	budget, err := s.trash.GetBudget(id)
+	if err != nil {
+		return err
+	}
+
+	// На любую дату может действовать только один бюджет, как и при создании
+	budgets, err := s.budgets.GetByUserID(budget.UserID)
+	if err != nil {
+		return err
+	}
+	if other := findOverlappingBudget(budgets, budget); other != nil {
+		return fmt.Errorf("%w: на этот период уже действует %s", ErrTrashRestore, describeBudgetPeriod(other))
+	}
+
+	return s.trash.RestoreBudget(id)
+}
+
+func (s *trashService) restoreRecurringExpense(id uint) error {
+	recurringExpense, err := s.trash.GetRecurringExpense(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkCategory(recurringExpense.UserID, recurringExpense.CategoryID); err != nil {
+		return err
+	}
+
+	// Пропущенные за время в корзине списания не создаются задним числом: следующая дата
+	// переносится на ближайшую после текущей
+	nextDate := recurringExpense.NextDate
+	if nextDate.Before(time.Now()) {
+		nextDate = s.recurring.CalculateNextDate(recurringExpense)
+	}
+
+	return s.trash.RestoreRecurringExpense(id, nextDate)
+}
+
+func (s *trashService) PurgeItem(item *models.TrashItem) error {
+	switch item.Type {
+	case models.TrashItemCategory:
+		count, err := s.trash.CountCategoryReferences(item.ID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrTrashItemInUse
+		}
+	case models.TrashItemExpense:
+		// Файлы удаляются до записей: если хранилище недоступно, расход остается в корзине
+		// и удаление можно повторить
+		if err := s.attachments.DeleteExpenseAttachments(item.ID); err != nil {
+			s.logger.Error("trash expense attachments delete failed",
+				slog.String("op", "purge_trash_item"),
+				slog.Uint64("expense_id", uint64(item.ID)),
+				slog.String("error", err.Error()),
+			)
+			return err
+		}
+	}
+
+	if err := s.trash.Purge(item.Type, item.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTrashItemNotFound
+		}
+		s.logger.Error("trash purge failed",
+			slog.String("op", "purge_trash_item"),
+			slog.String("type", string(item.Type)),
+			slog.Uint64("id", uint64(item.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("trash item purged",
+		slog.String("type", string(item.Type)),
+		slog.Uint64("id", uint64(item.ID)),
+		slog.Uint64("user_id", uint64(item.UserID)),
+	)
+	return nil
+}
+
+func (s *trashService) EmptyTrash(userID uint) (*models.TrashPurgeResult, error) {
+	items, err := s.trash.List(userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Категории удаляются последними, после ссылавшихся на них расходов и регулярных расходов
+	var categories []models.TrashItem
+	result := &models.TrashPurgeResult{}
+	for _, item := range items {
+		if item.Type == models.TrashItemCategory {
+			categories = append(categories, item)
+			continue
+		}
+		if err := s.purgeCounted(&item, result); err != nil {
+			return result, err
+		}
+	}
+	for _, item := range categories {
+		if err := s.purgeCounted(&item, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (s *trashService) PurgeExpired(now time.Time) (*models.TrashPurgeResult, error) {
+	result := &models.TrashPurgeResult{}
+	if s.retentionDays <= 0 {
+		return result, nil
+	}
+	before := now.AddDate(0, 0, -s.retentionDays)
+
+	// Пропущенные категории остаются в выборке, поэтому пакет увеличивается на их количество
+	skipped := make(map[uint]bool)
+	for {
+		limit := trashPurgeBatchSize + len(skipped)
+		items, err := s.trash.ListExpired(before, limit)
+		if err != nil {
+			return result, err
+		}
+		purged := result.Purged
+		for _, item := range items {
+			if item.Type == models.TrashItemCategory && skipped[item.ID] {
+				continue
+			}
+			skippedBefore := result.Skipped
+			if err := s.purgeCounted(&item, result); err != nil {
+				return result, err
+			}
+			if result.Skipped > skippedBefore {
+				skipped[item.ID] = true
+			}
+		}
+		if len(items) < limit || result.Purged == purged {
+			break
+		}
+	}
+
+	if result.Purged > 0 || result.Skipped > 0 {
+		s.logger.Info("expired trash purged",
+			slog.Int("purged", result.Purged),
+			slog.Int("skipped", result.Skipped),
+			slog.Int("retention_days", s.retentionDays),
+		)
+	}
+	return result, nil
+}
+
+// purgeCounted удаляет запись и учитывает ее в result. Категория, на которую еще ссылаются
+// другие записи, например удаленные доходы, пропускается.
+func (s *trashService) purgeCounted(item *models.TrashItem, result *models.TrashPurgeResult) error {
+	err := s.PurgeItem(item)
+	switch {
+	case err == nil:
+		result.Purged++
+	case errors.Is(err, ErrTrashItemInUse):
+		result.Skipped++
+	case errors.Is(err, ErrTrashItemNotFound):
+		// Запись уже восстановлена или удалена параллельным запросом
+	default:
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,601 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDeleteNotConfirmed = errors.New("удаление не подтверждено: передайте confirm=" + models.DeleteAccountConfirmation)
+	ErrArchiveInvalid     = errors.New("некорректный архив данных")
+)
+
+// maxArchiveErrors количество ошибок проверки архива, которые попадают в сообщение
+const maxArchiveErrors = 10
+
+type UserDataService interface {
+	// ExportUserData записывает все данные пользователя в w как JSON или ZIP-архив с файлом data.json.
+	// Данные загружаются целиком до начала записи, поэтому при ошибке ответ еще не отправлен.
+	ExportUserData(w io.Writer, userID uint, format models.ExportFormat) error
+	// DeleteUserData безвозвратно удаляет пользователя и все его данные
+	DeleteUserData(userID uint, req models.DeleteAccountRequest) error
+	// ImportUserData восстанавливает у пользователя данные из архива, выгруженного ExportUserData (JSON или ZIP)
+	ImportUserData(userID uint, r io.Reader) (*models.UserDataImportResult, error)
+}
+
+type userDataService struct {
+	data        repository.UserDataRepository
+	users       repository.UserRepository
+	budgets     repository.BudgetRepository
+	attachments AttachmentService
+	logger      *slog.Logger
+}
+
+func NewUserDataService(data repository.UserDataRepository, users repository.UserRepository, budgets repository.BudgetRepository, attachments AttachmentService, logger *slog.Logger) UserDataService {
+	return &userDataService{data: data, users: users, budgets: budgets, attachments: attachments, logger: logger}
+}
+
+func (s *userDataService) ExportUserData(w io.Writer, userID uint, format models.ExportFormat) error {
+	if format != models.ExportFormatJSON && format != models.ExportFormatZIP {
+		return fmt.Errorf("%w: неизвестный формат %q", ErrInvalidExport, format)
+	}
+
+	data, err := s.data.Load(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	archive := buildUserDataArchive(data, time.Now().UTC())
+
+	if format == models.ExportFormatZIP {
+		zw := zip.NewWriter(w)
+		f, err := zw.Create(models.UserDataArchiveFile)
+		if err != nil {
+			return err
+		}
+		if err := writeUserDataArchive(f, archive); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	} else if err := writeUserDataArchive(w, archive); err != nil {
+		return err
+	}
+
+	s.logger.Info("user data exported",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("format", string(format)),
+		slog.Int("expenses", len(archive.Expenses)),
+	)
+	return nil
+}
+
+func (s *userDataService) DeleteUserData(userID uint, req models.DeleteAccountRequest) error {
+	if req.Confirm != models.DeleteAccountConfirmation {
+		return ErrDeleteNotConfirmed
+	}
+
+	storageKeys, err := s.data.Purge(userID)
+	if err != nil {
+		s.logger.Error("user data purge failed",
+			slog.String("op", "delete_user_data"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	// Файлы вложений лежат вне базы и удаляются только после фиксации транзакции:
+	// при сбое очистки данных файлы остаются на месте
+	s.attachments.DeleteFiles(storageKeys)
+
+	s.logger.Info("user data purged",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("attachments", len(storageKeys)),
+	)
+	return nil
+}
+
+func (s *userDataService) ImportUserData(userID uint, r io.Reader) (*models.UserDataImportResult, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	archive, err := readUserDataArchive(r)
+	if err != nil {
+		return nil, err
+	}
+
+	baseCurrency := user.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = models.DefaultCurrency
+	}
+	errs := validateUserDataArchive(archive, baseCurrency)
+	var skipped []string
+	if len(errs) == 0 {
+		if skipped, errs, err = s.checkArchiveBudgets(userID, archive); err != nil {
+			return nil, err
+		}
+	}
+	if len(errs) > 0 {
+		s.logger.Warn("user data archive rejected",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Int("errors", len(errs)),
+			slog.String("first_error", errs[0]),
+		)
+		if len(errs) > maxArchiveErrors {
+			errs = append(errs[:maxArchiveErrors], fmt.Sprintf("и еще %d", len(errs)-maxArchiveErrors))
+		}
+		return nil, fmt.Errorf("%w: %s", ErrArchiveInvalid, strings.Join(errs, "; "))
+	}
+
+	result, err := s.data.Restore(userID, archive)
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		result.Skipped = append(skipped, result.Skipped...)
+	}
+
+	s.logger.Info("user data restored",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("version", archive.Version),
+		slog.Int("categories", result.Categories),
+		slog.Int("expenses", result.Expenses),
+		slog.Int("budgets", result.Budgets),
+		slog.Int("recurring_expenses", result.RecurringExpenses),
+		slog.Int("installment_plans", result.InstallmentPlans),
+		slog.Int("category_rules", result.CategoryRules),
+		slog.Int("duplicates", result.Duplicates),
+		slog.Int("skipped", len(result.Skipped)),
+	)
+	return result, nil
+}
+
+// checkArchiveBudgets сверяет периоды бюджетов архива с бюджетами пользователя и уже принятыми бюджетами архива.
+// Бюджет того же типа за тот же период пропускается и убирается из архива, частичное пересечение периодов —
+// ошибка архива, как и при создании бюджета.
+func (s *userDataService) checkArchiveBudgets(userID uint, archive *models.UserDataArchive) (skipped, errs []string, err error) {
+	if len(archive.Budgets) == 0 {
+		return nil, nil, nil
+	}
+	existing, err := s.budgets.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to load budgets for archive check",
+			slog.String("op", "import_user_data"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, nil, err
+	}
+
+	accepted := make([]models.ArchiveBudget, 0, len(archive.Budgets))
+	for _, b := range archive.Budgets {
+		candidate := &models.Budget{
+			Month:      b.Month,
+			Year:       b.Year,
+			PeriodType: b.PeriodType,
+			AnchorDate: b.AnchorDate,
+			EndDate:    b.EndDate,
+		}
+		if candidate.PeriodType == "" {
+			candidate.PeriodType = models.BudgetPeriodMonthly
+		}
+
+		var conflict *models.Budget
+		for i := range existing {
+			if budgetsOverlap(&existing[i], candidate) {
+				conflict = &existing[i]
+				break
+			}
+		}
+		switch {
+		case conflict == nil:
+			existing = append(existing, *candidate)
+			accepted = append(accepted, b)
+		case sameBudgetPeriod(conflict, candidate):
+			skipped = append(skipped, fmt.Sprintf("бюджет %d: уже есть %s", b.ID, describeBudgetPeriod(conflict)))
+		default:
+			errs = append(errs, fmt.Sprintf("бюджет %d: период пересекается с %s", b.ID, describeBudgetPeriod(conflict)))
+		}
+	}
+	archive.Budgets = accepted
+	return skipped, errs, nil
+}
+
+// readUserDataArchive читает архив в формате JSON или ZIP с файлом data.json
+func readUserDataArchive(r io.Reader) (*models.UserDataArchive, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	content := io.Reader(bytes.NewReader(data))
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrArchiveInvalid, err.Error())
+		}
+		f, err := zr.Open(models.UserDataArchiveFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: в ZIP-архиве нет файла %s", ErrArchiveInvalid, models.UserDataArchiveFile)
+		}
+		defer f.Close()
+		content = f
+	}
+
+	var archive models.UserDataArchive
+	if err := json.NewDecoder(content).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrArchiveInvalid, err.Error())
+	}
+	return &archive, nil
+}
+
+// validateUserDataArchive проверяет версию архива и ссылки между его разделами
+func validateUserDataArchive(archive *models.UserDataArchive, baseCurrency string) []string {
+	if archive.Format != models.UserDataArchiveFormat {
+		return []string{fmt.Sprintf("ожидается format %q", models.UserDataArchiveFormat)}
+	}
+	if archive.Version < 1 || archive.Version > models.UserDataArchiveVersion {
+		return []string{fmt.Sprintf("версия %d не поддерживается, поддерживаются версии до %d", archive.Version, models.UserDataArchiveVersion)}
+	}
+	upgradeUserDataArchive(archive)
+
+	var errs []string
+	// Суммы расходов в архиве пересчитаны в базовую валюту исходного пользователя
+	if archive.Profile.BaseCurrency != "" && len(archive.Expenses) > 0 && archive.Profile.BaseCurrency != baseCurrency {
+		errs = append(errs, fmt.Sprintf("базовая валюта архива %s отличается от базовой валюты пользователя %s", archive.Profile.BaseCurrency, baseCurrency))
+	}
+
+	categories := make(map[uint]models.ArchiveCategory, len(archive.Categories))
+	for _, c := range archive.Categories {
+		if _, exists := categories[c.ID]; exists {
+			errs = append(errs, fmt.Sprintf("категория %d указана дважды", c.ID))
+		}
+		if strings.TrimSpace(c.Name) == "" {
+			errs = append(errs, fmt.Sprintf("категория %d: пустое название", c.ID))
+		}
+		categories[c.ID] = c
+	}
+	for _, c := range archive.Categories {
+		// Подъем по родителям должен закончиться на корневой категории за число шагов не больше числа категорий
+		current, steps := c, 0
+		for current.ParentID != nil && steps <= len(categories) {
+			parent, ok := categories[*current.ParentID]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("категория %d: нет родительской категории %d", current.ID, *current.ParentID))
+				break
+			}
+			current = parent
+			steps++
+		}
+		if steps > len(categories) {
+			errs = append(errs, fmt.Sprintf("категория %d: циклическая ссылка на родителя", c.ID))
+		} else if steps+1 > models.MaxCategoryDepth {
+			errs = append(errs, fmt.Sprintf("категория %d: превышена максимальная вложенность категорий (%d)", c.ID, models.MaxCategoryDepth))
+		}
+	}
+
+	accounts := make(map[uint]bool, len(archive.Accounts))
+	for _, a := range archive.Accounts {
+		accounts[a.ID] = true
+	}
+	tags := make(map[uint]bool, len(archive.Tags))
+	for _, t := range archive.Tags {
+		tags[t.ID] = true
+	}
+
+	checkCategory := func(section string, id, categoryID uint) {
+		if _, ok := categories[categoryID]; !ok {
+			errs = append(errs, fmt.Sprintf("%s %d: нет категории %d", section, id, categoryID))
+		}
+	}
+	checkAccount := func(section string, id uint, accountID *uint) {
+		if accountID != nil && !accounts[*accountID] {
+			errs = append(errs, fmt.Sprintf("%s %d: нет счета %d", section, id, *accountID))
+		}
+	}
+	checkTags := func(section string, id uint, tagIDs []uint) {
+		for _, tagID := range tagIDs {
+			if !tags[tagID] {
+				errs = append(errs, fmt.Sprintf("%s %d: нет тега %d", section, id, tagID))
+			}
+		}
+	}
+
+	for _, e := range archive.Expenses {
+		checkCategory("расход", e.ID, e.CategoryID)
+		checkAccount("расход", e.ID, e.AccountID)
+		checkTags("расход", e.ID, e.TagIDs)
+		for _, split := range e.Splits {
+			checkCategory("часть расхода", e.ID, split.CategoryID)
+		}
+	}
+	for _, i := range archive.Incomes {
+		checkCategory("доход", i.ID, i.CategoryID)
+		checkAccount("доход", i.ID, i.AccountID)
+	}
+	for _, t := range archive.Transfers {
+		checkAccount("перевод", t.ID, &t.FromAccountID)
+		checkAccount("перевод", t.ID, &t.ToAccountID)
+	}
+	for _, b := range archive.Budgets {
+		for _, limit := range b.Categories {
+			checkCategory("бюджет", b.ID, limit.CategoryID)
+		}
+	}
+	for _, r := range archive.CategoryRules {
+		checkCategory("правило", r.ID, r.CategoryID)
+		rule := models.CategoryRule{
+			DescriptionContains: r.DescriptionContains,
+			DescriptionRegex:    r.DescriptionRegex,
+			MinAmount:           r.MinAmount,
+			MaxAmount:           r.MaxAmount,
+			Weekdays:            r.Weekdays,
+		}
+		if !categoryRuleHasCondition(&rule) {
+			errs = append(errs, fmt.Sprintf("правило %d: нет условий", r.ID))
+		}
+		if r.DescriptionRegex != "" {
+			if _, err := regexp.Compile(r.DescriptionRegex); err != nil {
+				errs = append(errs, fmt.Sprintf("правило %d: некорректное регулярное выражение", r.ID))
+			}
+		}
+	}
+	for _, r := range archive.RecurringExpenses {
+		checkCategory("регулярный расход", r.ID, r.CategoryID)
+		checkTags("регулярный расход", r.ID, r.TagIDs)
+	}
+	plans := make(map[uint]bool, len(archive.InstallmentPlans))
+	for _, p := range archive.InstallmentPlans {
+		plans[p.ID] = true
+		checkCategory("план рассрочки", p.ID, p.CategoryID)
+		checkAccount("план рассрочки", p.ID, p.AccountID)
+		if p.Payments < 1 || p.Payments > models.MaxInstallmentPayments {
+			errs = append(errs, fmt.Sprintf("план рассрочки %d: число платежей должно быть от 1 до %d", p.ID, models.MaxInstallmentPayments))
+		}
+		if p.PaymentsMade < 0 || p.PaymentsMade > p.Payments {
+			errs = append(errs, fmt.Sprintf("план рассрочки %d: создано %d платежей из %d", p.ID, p.PaymentsMade, p.Payments))
+		}
+	}
+	for _, e := range archive.Expenses {
+		if e.InstallmentPlanID != nil && !plans[*e.InstallmentPlanID] {
+			errs = append(errs, fmt.Sprintf("расход %d: нет плана рассрочки %d", e.ID, *e.InstallmentPlanID))
+		}
+	}
+
+	return errs
+}
+
+// upgradeUserDataArchive приводит архив прежней версии к текущей структуре. В версии 1 не было правил
+// категоризации, частей расходов, планов рассрочки и даты окончания бюджетов, поэтому такие поля
+// в архиве версии 1 не восстанавливаются.
+func upgradeUserDataArchive(archive *models.UserDataArchive) {
+	if archive.Version >= 2 {
+		return
+	}
+	archive.CategoryRules = nil
+	archive.InstallmentPlans = nil
+	for i := range archive.Expenses {
+		archive.Expenses[i].Splits = nil
+		archive.Expenses[i].InstallmentPlanID = nil
+	}
+	for i := range archive.Budgets {
+		archive.Budgets[i].EndDate = nil
+	}
+}
+
+func writeUserDataArchive(w io.Writer, archive *models.UserDataArchive) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(archive)
+}
+
+// buildUserDataArchive переводит записи БД в формат архива
+func buildUserDataArchive(data *models.UserData, exportedAt time.Time) *models.UserDataArchive {
+	archive := &models.UserDataArchive{
+		Format:     models.UserDataArchiveFormat,
+		Version:    models.UserDataArchiveVersion,
+		ExportedAt: exportedAt,
+		Profile: models.ArchiveProfile{
+			Email:        data.User.Email,
+			Username:     data.User.Username,
+			TelegramID:   data.User.TelegramID,
+			BaseCurrency: data.User.BaseCurrency,
+			CreatedAt:    data.User.CreatedAt,
+		},
+		Categories:        make([]models.ArchiveCategory, 0, len(data.Categories)),
+		Accounts:          make([]models.ArchiveAccount, 0, len(data.Accounts)),
+		Tags:              make([]models.ArchiveTag, 0, len(data.Tags)),
+		CategoryRules:     make([]models.ArchiveCategoryRule, 0, len(data.CategoryRules)),
+		Expenses:          make([]models.ArchiveExpense, 0, len(data.Expenses)),
+		Incomes:           make([]models.ArchiveIncome, 0, len(data.Incomes)),
+		Transfers:         make([]models.ArchiveTransfer, 0, len(data.Transfers)),
+		Budgets:           make([]models.ArchiveBudget, 0, len(data.Budgets)),
+		RecurringExpenses: make([]models.ArchiveRecurringExpense, 0, len(data.RecurringExpenses)),
+		InstallmentPlans:  make([]models.ArchiveInstallmentPlan, 0, len(data.InstallmentPlans)),
+		ActivityHistory:   make([]models.ArchiveActivity, 0, len(data.ActivityHistory)),
+	}
+
+	for _, c := range data.Categories {
+		archive.Categories = append(archive.Categories, models.ArchiveCategory{
+			ID:        c.ID,
+			ParentID:  c.ParentID,
+			Name:      c.Name,
+			Color:     c.Color,
+			Icon:      c.Icon,
+			Type:      c.Type,
+			IsDefault: c.IsDefault,
+		})
+	}
+	for _, a := range data.Accounts {
+		archive.Accounts = append(archive.Accounts, models.ArchiveAccount{
+			ID:             a.ID,
+			Name:           a.Name,
+			Type:           a.Type,
+			OpeningBalance: a.OpeningBalance,
+		})
+	}
+	for _, t := range data.Tags {
+		archive.Tags = append(archive.Tags, models.ArchiveTag{ID: t.ID, Name: t.Name, Color: t.Color})
+	}
+	for _, r := range data.CategoryRules {
+		archive.CategoryRules = append(archive.CategoryRules, models.ArchiveCategoryRule{
+			ID:                  r.ID,
+			Name:                r.Name,
+			Priority:            r.Priority,
+			IsActive:            r.IsActive,
+			DescriptionContains: r.DescriptionContains,
+			DescriptionRegex:    r.DescriptionRegex,
+			MinAmount:           r.MinAmount,
+			MaxAmount:           r.MaxAmount,
+			Weekdays:            r.Weekdays,
+			CategoryID:          r.CategoryID,
+			SetDescription:      r.SetDescription,
+		})
+	}
+	for _, e := range data.Expenses {
+		archive.Expenses = append(archive.Expenses, models.ArchiveExpense{
+			ID:                e.ID,
+			AccountID:         e.AccountID,
+			CategoryID:        e.CategoryID,
+			Amount:            e.Amount,
+			Currency:          e.Currency,
+			OriginalAmount:    e.OriginalAmount,
+			ExchangeRate:      e.ExchangeRate,
+			Description:       e.Description,
+			Date:              e.Date,
+			ExternalID:        e.ExternalID,
+			InstallmentPlanID: e.InstallmentPlanID,
+			TagIDs:            archiveTagIDs(e.Tags),
+			Splits:            archiveExpenseSplits(e.Splits),
+		})
+	}
+	for _, i := range data.Incomes {
+		archive.Incomes = append(archive.Incomes, models.ArchiveIncome{
+			ID:          i.ID,
+			AccountID:   i.AccountID,
+			CategoryID:  i.CategoryID,
+			Amount:      i.Amount,
+			Description: i.Description,
+			Date:        i.Date,
+		})
+	}
+	for _, t := range data.Transfers {
+		archive.Transfers = append(archive.Transfers, models.ArchiveTransfer{
+			ID:            t.ID,
+			FromAccountID: t.FromAccountID,
+			ToAccountID:   t.ToAccountID,
+			Amount:        t.Amount,
+			Description:   t.Description,
+			Date:          t.Date,
+		})
+	}
+	for _, b := range data.Budgets {
+		budget := models.ArchiveBudget{
+			ID:         b.ID,
+			Amount:     b.Amount,
+			Month:      b.Month,
+			Year:       b.Year,
+			Rollover:   b.Rollover,
+			PeriodType: b.PeriodType,
+			AnchorDate: b.AnchorDate,
+			EndDate:    b.EndDate,
+		}
+		for _, limit := range b.Categories {
+			budget.Categories = append(budget.Categories, models.ArchiveBudgetCategory{
+				CategoryID: limit.CategoryID,
+				Amount:     limit.Amount,
+			})
+		}
+		archive.Budgets = append(archive.Budgets, budget)
+	}
+	for _, r := range data.RecurringExpenses {
+		archive.RecurringExpenses = append(archive.RecurringExpenses, models.ArchiveRecurringExpense{
+			ID:          r.ID,
+			CategoryID:  r.CategoryID,
+			Amount:      r.Amount,
+			Description: r.Description,
+			Type:        r.Type,
+			DayOfMonth:  r.DayOfMonth,
+			DayOfWeek:   r.DayOfWeek,
+			IsActive:    r.IsActive,
+			NextDate:    r.NextDate,
+			TagIDs:      archiveTagIDs(r.Tags),
+		})
+	}
+	for _, p := range data.InstallmentPlans {
+		archive.InstallmentPlans = append(archive.InstallmentPlans, models.ArchiveInstallmentPlan{
+			ID:           p.ID,
+			CategoryID:   p.CategoryID,
+			AccountID:    p.AccountID,
+			Description:  p.Description,
+			TotalAmount:  p.TotalAmount,
+			Payments:     p.Payments,
+			StartDate:    p.StartDate,
+			PaymentsMade: p.PaymentsMade,
+			NextDate:     p.NextDate,
+		})
+	}
+	for _, a := range data.ActivityHistory {
+		activity := models.ArchiveActivity{
+			ActivityType: a.ActivityType,
+			EntityType:   a.EntityType,
+			EntityID:     a.EntityID,
+			Description:  a.Description,
+			CreatedAt:    a.CreatedAt,
+		}
+		if a.Metadata != "" && json.Valid([]byte(a.Metadata)) {
+			activity.Metadata = json.RawMessage(a.Metadata)
+		}
+		archive.ActivityHistory = append(archive.ActivityHistory, activity)
+	}
+
+	return archive
+}
+
+func archiveTagIDs(tags []models.Tag) []uint {
+	if len(tags) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	return ids
+}
+
+func archiveExpenseSplits(splits []models.ExpenseSplit) []models.ArchiveExpenseSplit {
+	if len(splits) == 0 {
+		return nil
+	}
+	result := make([]models.ArchiveExpenseSplit, len(splits))
+	for i, split := range splits {
+		result[i] = models.ArchiveExpenseSplit{
+			CategoryID:     split.CategoryID,
+			Amount:         split.Amount,
+			OriginalAmount: split.OriginalAmount,
+			Description:    split.Description,
+		}
+	}
+	return result
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"cashcontrol/internal/models"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// xlsxWriter пишет книгу Excel (Office Open XML) с одним листом по мере поступления строк.
+// Служебные части книги неизменны, поэтому записываются сразу, а лист дописывается построчно.
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	row   int
+	err   error
+}
+
+const (
+	xlsxStyleDate   = 1 // Индекс стиля ячейки с датой в xl/styles.xml
+	xlsxStyleMoney  = 2 // Индекс стиля ячейки с суммой в xl/styles.xml
+	xlsxStyleHeader = 3 // Индекс стиля строки заголовка (полужирный шрифт)
+)
+
+var xlsxStaticParts = []struct {
+	Name    string
+	Content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Expenses" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	// Стили ячеек: 0 — обычная, 1 — дата (формат 14), 2 — сумма с двумя знаками (формат 4), 3 — заголовок
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="4">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`</cellXfs>` +
+		`</styleSheet>`},
+}
+
+// xlsxEpoch точка отсчета дат Excel с учетом ошибки 1900 года
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	x := &xlsxWriter{zip: zip.NewWriter(w)}
+
+	for _, part := range xlsxStaticParts {
+		f, err := x.zip.Create(part.Name)
+		if err != nil {
+			x.err = err
+			return x
+		}
+		if _, err := io.WriteString(f, part.Content); err != nil {
+			x.err = err
+			return x
+		}
+	}
+
+	f, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		x.err = err
+		return x
+	}
+	x.sheet = bufio.NewWriter(f)
+	x.writeString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return x
+}
+
+func (x *xlsxWriter) WriteHeader(keys []string) error {
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		values[i] = key
+	}
+	return x.writeRow(values, xlsxStyleHeader)
+}
+
+func (x *xlsxWriter) WriteRow(keys []string, values []any) error {
+	return x.writeRow(values, 0)
+}
+
+func (x *xlsxWriter) Close() error {
+	x.writeString(`</sheetData></worksheet>`)
+	if x.err != nil {
+		return x.err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+func (x *xlsxWriter) writeRow(values []any, style int) error {
+	x.row++
+	row := strconv.Itoa(x.row)
+	x.writeString(`<row r="` + row + `">`)
+
+	for i, value := range values {
+		ref := xlsxColumnName(i) + row
+		switch v := value.(type) {
+		case models.Money:
+			x.writeNumberCell(ref, v.String(), xlsxStyleMoney)
+		case float64:
+			x.writeNumberCell(ref, strconv.FormatFloat(v, 'f', -1, 64), style)
+		case uint:
+			x.writeNumberCell(ref, strconv.FormatUint(uint64(v), 10), style)
+		case *uint:
+			if v != nil {
+				x.writeNumberCell(ref, strconv.FormatUint(uint64(*v), 10), style)
+			}
+		case time.Time:
+			y, m, d := v.Date()
+			days := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(xlsxEpoch).Hours() / 24
+			x.writeNumberCell(ref, strconv.FormatFloat(days, 'f', 0, 64), xlsxStyleDate)
+		default:
+			x.writeStringCell(ref, formatExportValue(v), style)
+		}
+	}
+
+	x.writeString(`</row>`)
+	return x.err
+}
+
+func (x *xlsxWriter) writeNumberCell(ref, value string, style int) {
+	x.writeString(`<c r="` + ref + `"` + xlsxStyleAttr(style) + `><v>` + value + `</v></c>`)
+}
+
+// writeStringCell пишет строку прямо в ячейку (inlineStr), без таблицы общих строк
+func (x *xlsxWriter) writeStringCell(ref, value string, style int) {
+	if value == "" {
+		return
+	}
+	x.writeString(`<c r="` + ref + `" t="inlineStr"` + xlsxStyleAttr(style) + `><is><t xml:space="preserve">`)
+	if x.err == nil {
+		x.err = xml.EscapeText(x.sheet, []byte(value))
+	}
+	x.writeString(`</t></is></c>`)
+}
+
+func (x *xlsxWriter) writeString(s string) {
+	if x.err != nil {
+		return
+	}
+	_, x.err = x.sheet.WriteString(s)
+}
+
+func xlsxStyleAttr(style int) string {
+	if style == 0 {
+		return ""
+	}
+	return ` s="` + strconv.Itoa(style) + `"`
+}
+
+// xlsxColumnName переводит индекс столбца с 0 в буквенное обозначение: A, B, ..., Z, AA
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
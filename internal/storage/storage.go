@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"cashcontrol/internal/config"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound объект с указанным ключом отсутствует в хранилище
+var ErrNotFound = errors.New("объект не найден в хранилище")
+
+const (
+	BackendLocal = "local" // Файлы на локальном диске
+	BackendS3    = "s3"    // S3-совместимое хранилище (AWS S3, MinIO и т.п.)
+)
+
+// Storage хранилище файлов вложений. Ключ — относительный путь вида "users/1/expenses/2/abc.pdf",
+// разделитель всегда "/" независимо от бэкенда.
+type Storage interface {
+	// Put сохраняет объект размером size байт, существующий объект с тем же ключом перезаписывается
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект для чтения, вызывающий обязан закрыть его. Отсутствующий объект — ErrNotFound.
+	Get(key string) (io.ReadCloser, error)
+	// Delete удаляет объект, удаление отсутствующего объекта не считается ошибкой
+	Delete(key string) error
+}
+
+// New создает хранилище, выбранное в конфигурации (ATTACHMENT_STORAGE)
+func New(cfg *config.Config) (Storage, error) {
+	switch cfg.AttachmentStorage {
+	case BackendLocal, "":
+		return NewLocalStorage(cfg.AttachmentDir)
+	case BackendS3:
+		return NewS3Storage(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("неизвестное хранилище вложений %q", cfg.AttachmentStorage)
+	}
+}
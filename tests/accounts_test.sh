@@ -0,0 +1,55 @@
+#!/bin/bash
+
+# Тесты для Account и Transfer эндпоинтов
+
+source "$(dirname "$0")/common.sh"
+
+echo "=== Account эндпоинты ==="
+
+# Создаем тестового пользователя
+USER_ID=$(create_test_user "account_test_$(date +%s)@example.com" "accounttest")
+if [ -z "$USER_ID" ]; then
+    echo "  ⚠ Не удалось создать пользователя, используем ID=1"
+    USER_ID=1
+fi
+
+# Список счетов
+test_endpoint "GET" "/accounts" "" "Список счетов"
+
+# Создание счетов
+test_endpoint "POST" "/accounts" \
+    '{"name":"Наличные","type":"cash","opening_balance":5000}' \
+    "Создание счета наличных" "CASH_ID"
+test_endpoint "POST" "/accounts" \
+    '{"name":"Дебетовая карта","type":"debit_card","opening_balance":20000}' \
+    "Создание карточного счета" "CARD_ID"
+
+if [ -n "$CASH_ID" ] && [ -n "$CARD_ID" ]; then
+    current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+
+    # Получение и обновление счета
+    test_endpoint "GET" "/accounts/$CASH_ID" "" "Получение счета по ID"
+    test_endpoint "PATCH" "/accounts/$CASH_ID" '{"name":"Кошелек"}' "Обновление счета"
+
+    # Перевод между счетами
+    test_endpoint "POST" "/transfers" \
+        "{\"from_account_id\":$CARD_ID,\"to_account_id\":$CASH_ID,\"amount\":3000,\"description\":\"Снятие наличных\",\"date\":\"$current_date\"}" \
+        "Перевод между счетами" "TRANSFER_ID"
+
+    # Перевод на тот же счет должен быть отклонен
+    test_endpoint "POST" "/transfers" \
+        "{\"from_account_id\":$CASH_ID,\"to_account_id\":$CASH_ID,\"amount\":100,\"date\":\"$current_date\"}" \
+        "Перевод на тот же счет (ожидается 400)"
+
+    test_endpoint "GET" "/transfers?account_id=$CASH_ID" "" "Список переводов по счету"
+
+    if [ -n "$TRANSFER_ID" ]; then
+        test_endpoint "GET" "/transfers/$TRANSFER_ID" "" "Получение перевода по ID"
+        test_endpoint "DELETE" "/accounts/$CASH_ID" "" "Удаление счета с переводом (ожидается 409)"
+        test_endpoint "DELETE" "/transfers/$TRANSFER_ID" "" "Удаление перевода"
+    fi
+
+    test_endpoint "DELETE" "/accounts/$CASH_ID" "" "Удаление счета"
+fi
+
+print_stats
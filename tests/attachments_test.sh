@@ -0,0 +1,96 @@
+#!/bin/bash
+
+# Тесты для вложений к расходам
+
+source "$(dirname "$0")/common.sh"
+
+echo "=== Attachment эндпоинты ==="
+
+# Создаем тестового пользователя
+USER_ID=$(create_test_user "attachment_test_$(date +%s)@example.com" "attachmenttest")
+if [ -z "$USER_ID" ]; then
+    echo "  ⚠ Не удалось создать пользователя, используем ID=1"
+    USER_ID=1
+fi
+
+# test_upload отправляет файл multipart-формой: test_upload <endpoint> <file> <description> [имя переменной для ID]
+test_upload() {
+    local endpoint=$1
+    local file=$2
+    local description=$3
+    local save_id_var=$4
+
+    TOTAL=$((TOTAL + 1))
+    echo -n "  Testing POST $endpoint ... "
+
+    local response code body
+    response=$(curl -s -w "\n%{http_code}" -X "POST" "$BASE_URL$endpoint" -F "file=@$file")
+    code=$(echo "$response" | tail -n1)
+    body=$(echo "$response" | sed '$d')
+    if [ "$code" -ge 200 ] && [ "$code" -lt 300 ]; then
+        SUCCESS=$((SUCCESS + 1))
+        echo -e "${GREEN}✓${NC} ($code)"
+        if [ -n "$save_id_var" ]; then
+            eval "$save_id_var=$(extract_id "$body")"
+        fi
+    elif [ "$code" -ge 400 ] && [ "$code" -lt 500 ]; then
+        CLIENT_ERROR=$((CLIENT_ERROR + 1))
+        echo -e "${YELLOW}⚠${NC} ($code) - Client Error"
+    else
+        SERVER_ERROR=$((SERVER_ERROR + 1))
+        echo -e "${RED}✗${NC} ($code)"
+    fi
+    echo "    → $description"
+}
+
+test_endpoint "POST" "/categories/$USER_ID" '{"name":"Техника"}' "Создание категории" "CATEGORY_ID"
+current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+test_endpoint "POST" "/expenses" \
+    "{\"category_id\":${CATEGORY_ID:-1},\"amount\":45000,\"description\":\"Ноутбук\",\"date\":\"$current_date\"}" \
+    "Создание расхода" "EXPENSE_ID"
+
+if [ -n "$EXPENSE_ID" ]; then
+    pdf_file=$(mktemp --suffix=.pdf)
+    printf '%%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%%%EOF\n' > "$pdf_file"
+    test_upload "/expenses/$EXPENSE_ID/attachments" "$pdf_file" "Загрузка гарантийного талона (PDF)" "ATTACHMENT_ID"
+
+    png_file=$(mktemp --suffix=.png)
+    printf '\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89' > "$png_file"
+    test_upload "/expenses/$EXPENSE_ID/attachments" "$png_file" "Загрузка фото чека (PNG)"
+
+    # Тип определяется по содержимому, расширение .pdf не помогает
+    text_file=$(mktemp --suffix=.pdf)
+    echo "обычный текст" > "$text_file"
+    test_upload "/expenses/$EXPENSE_ID/attachments" "$text_file" "Загрузка текстового файла (ожидается 415)"
+
+    empty_file=$(mktemp --suffix=.jpg)
+    test_upload "/expenses/$EXPENSE_ID/attachments" "$empty_file" "Загрузка пустого файла (ожидается 400)"
+    rm -f "$pdf_file" "$png_file" "$text_file" "$empty_file"
+
+    test_endpoint "GET" "/expenses/$EXPENSE_ID/attachments" "" "Список вложений расхода"
+
+    if [ -n "$ATTACHMENT_ID" ]; then
+        TOTAL=$((TOTAL + 1))
+        echo -n "  Testing GET /expenses/$EXPENSE_ID/attachments/$ATTACHMENT_ID ... "
+        download=$(curl -s -w "\n%{http_code}" "$BASE_URL/expenses/$EXPENSE_ID/attachments/$ATTACHMENT_ID")
+        code=$(echo "$download" | tail -n1)
+        if [ "$code" = "200" ] && echo "$download" | head -1 | grep -q '^%PDF'; then
+            SUCCESS=$((SUCCESS + 1))
+            echo -e "${GREEN}✓${NC} ($code)"
+        else
+            SERVER_ERROR=$((SERVER_ERROR + 1))
+            echo -e "${RED}✗${NC} ($code)"
+        fi
+        echo "    → Скачивание вложения совпадает с загруженным файлом"
+
+        test_endpoint "DELETE" "/expenses/$EXPENSE_ID/attachments/$ATTACHMENT_ID" "" "Удаление вложения"
+        test_endpoint "GET" "/expenses/$EXPENSE_ID/attachments/$ATTACHMENT_ID" "" "Получение удаленного вложения (ожидается 404)"
+    fi
+
+    test_endpoint "GET" "/expenses/999999/attachments" "" "Вложения несуществующего расхода (ожидается 404)"
+
+    # Удаление расхода удаляет и оставшиеся вложения
+    test_endpoint "DELETE" "/expenses/$EXPENSE_ID" "" "Удаление расхода с вложениями"
+fi
+
+print_stats
@@ -19,12 +19,27 @@ current_year=$(date +%Y)
 
 # Список бюджетов
 test_endpoint "GET" "/budgets?user_id=$USER_ID" "" "Список бюджетов"
+test_endpoint "GET" "/budgets?user_id=$USER_ID&sort=amount&direction=desc&limit=10" "" "Список бюджетов по сумме"
 
 # Создание бюджета
 test_endpoint "POST" "/budgets?user_id=$USER_ID" \
     "{\"amount\":5000,\"month\":$current_month,\"year\":$current_year}" \
     "Создание бюджета" "BUDGET_ID"
 
+# Создание бюджета на платежный цикл (с 25-го числа) за прошлый год
+previous_year=$((current_year - 1))
+test_endpoint "POST" "/budgets?user_id=$USER_ID" \
+    "{\"amount\":30000,\"period_type\":\"pay_cycle\",\"anchor_date\":\"$previous_year-01-25T00:00:00Z\",\"end_date\":\"$previous_year-12-24T00:00:00Z\"}" \
+    "Создание бюджета на платежный цикл"
+
+# Бессрочный бюджет пересекается с месячным бюджетом
+test_endpoint "POST" "/budgets?user_id=$USER_ID" \
+    "{\"amount\":30000,\"period_type\":\"pay_cycle\",\"anchor_date\":\"$current_year-01-25T00:00:00Z\"}" \
+    "Создание пересекающегося бюджета (ожидается 409)"
+
+# Статус бюджета на дату
+test_endpoint "GET" "/budgets/status?user_id=$USER_ID&date=$(date +%Y-%m-%d)" "" "Статус бюджета на дату"
+
 # Статус бюджета
 test_endpoint "GET" "/budgets/status?user_id=$USER_ID&month=$current_month&year=$current_year" "" "Статус бюджета"
 
@@ -40,6 +55,18 @@ if [ -n "$BUDGET_ID" ]; then
         '{"amount":6000}' \
         "Обновление бюджета"
     
+    # Включение переноса остатка
+    test_endpoint "PATCH" "/budgets/$BUDGET_ID" \
+        '{"rollover":true}' \
+        "Включение переноса остатка"
+
+    # Лимит по категории внутри бюджета
+    test_endpoint "PUT" "/budgets/$BUDGET_ID/categories" \
+        '{"category_id":1,"amount":2000}' \
+        "Установка лимита по категории"
+
+    test_endpoint "DELETE" "/budgets/$BUDGET_ID/categories/1" "" "Удаление лимита по категории"
+
     # Удаление бюджета
     test_endpoint "DELETE" "/budgets/$BUDGET_ID" "" "Удаление бюджета"
 fi
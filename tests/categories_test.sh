@@ -30,8 +30,35 @@ if [ -n "$CATEGORY_ID" ]; then
         '{"name":"Обновленная категория","color":"#33FF57"}' \
         "Обновление категории"
     
+    # Создание подкатегории
+    test_endpoint "POST" "/categories/$USER_ID" \
+        "{\"name\":\"Подкатегория\",\"color\":\"#3357FF\",\"parent_id\":$CATEGORY_ID}" \
+        "Создание подкатегории" "SUBCATEGORY_ID"
+
+    # Дерево категорий
+    test_endpoint "GET" "/categories/tree" "" "Дерево категорий"
+
+    # Категория для объединения
+    test_endpoint "POST" "/categories/$USER_ID" \
+        '{"name":"Категория для объединения","color":"#AA33FF"}' \
+        "Создание категории для объединения" "MERGE_CATEGORY_ID"
+
+    if [ -n "$MERGE_CATEGORY_ID" ]; then
+        test_endpoint "POST" "/categories/$MERGE_CATEGORY_ID/merge" \
+            "{\"target_category_id\":$CATEGORY_ID}" \
+            "Объединение категорий"
+    fi
+
+    # Удаление с переносом в несуществующую категорию
+    test_endpoint "DELETE" "/categories/$CATEGORY_ID?target_category_id=999999" "" "Удаление с переносом в несуществующую категорию (ожидается 400)"
+
     # Удаление категории
     test_endpoint "DELETE" "/categories/$CATEGORY_ID" "" "Удаление категории"
+
+    # Подкатегория после удаления родителя становится корневой
+    if [ -n "$SUBCATEGORY_ID" ]; then
+        test_endpoint "DELETE" "/categories/$SUBCATEGORY_ID" "" "Удаление подкатегории"
+    fi
 fi
 
 print_stats
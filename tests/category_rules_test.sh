@@ -0,0 +1,73 @@
+#!/bin/bash
+
+# Тесты для Category Rule эндпоинтов
+
+source "$(dirname "$0")/common.sh"
+
+echo "=== Category Rule эндпоинты ==="
+
+# Создаем тестового пользователя
+USER_ID=$(create_test_user "rule_test_$(date +%s)@example.com" "ruletest")
+if [ -z "$USER_ID" ]; then
+    echo "  ⚠ Не удалось создать пользователя, используем ID=1"
+    USER_ID=1
+fi
+
+# Категории: общая для нераспределенных расходов и целевая для правил
+test_endpoint "POST" "/categories/$USER_ID" '{"name":"Прочее"}' "Создание общей категории" "OTHER_CATEGORY_ID"
+test_endpoint "POST" "/categories/$USER_ID" '{"name":"Кафе"}' "Создание категории для правил" "CAFE_CATEGORY_ID"
+
+# Список правил
+test_endpoint "GET" "/category-rules" "" "Список правил"
+
+if [ -n "$OTHER_CATEGORY_ID" ] && [ -n "$CAFE_CATEGORY_ID" ]; then
+    current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+
+    # Расход в общей категории до появления правил
+    test_endpoint "POST" "/expenses" \
+        "{\"category_id\":$OTHER_CATEGORY_ID,\"amount\":350,\"description\":\"STARBUCKS 1234\",\"date\":\"$current_date\"}" \
+        "Создание расхода в общей категории"
+
+    # Создание правил
+    test_endpoint "POST" "/category-rules" \
+        "{\"name\":\"Кофейни\",\"priority\":1,\"description_regex\":\"(?i)^starbucks\",\"max_amount\":1000,\"category_id\":$CAFE_CATEGORY_ID,\"set_description\":\"Starbucks\"}" \
+        "Создание правила с регулярным выражением" "RULE_ID"
+    test_endpoint "POST" "/category-rules" \
+        "{\"name\":\"Обеды по будням\",\"priority\":2,\"description_contains\":\"обед\",\"weekdays\":[1,2,3,4,5],\"category_id\":$CAFE_CATEGORY_ID}" \
+        "Создание правила с подстрокой и днями недели" "WEEKDAY_RULE_ID"
+
+    # Некорректные правила
+    test_endpoint "POST" "/category-rules" \
+        "{\"name\":\"Ошибка в regex\",\"description_regex\":\"(\",\"category_id\":$CAFE_CATEGORY_ID}" \
+        "Создание правила с некорректным regex (ожидается 400)"
+    test_endpoint "POST" "/category-rules" \
+        "{\"name\":\"Без условий\",\"category_id\":$CAFE_CATEGORY_ID}" \
+        "Создание правила без условий (ожидается 400)"
+
+    # Расход без категории распределяется правилом
+    test_endpoint "POST" "/expenses" \
+        "{\"amount\":420,\"description\":\"Starbucks Arbat\",\"date\":\"$current_date\"}" \
+        "Создание расхода без категории"
+    test_endpoint "POST" "/expenses" \
+        "{\"amount\":420,\"description\":\"Такси\",\"date\":\"$current_date\"}" \
+        "Создание расхода без подходящего правила (ожидается 400)"
+
+    # Повторное применение правил к общей категории
+    test_endpoint "POST" "/category-rules/apply" \
+        "{\"category_id\":$OTHER_CATEGORY_ID,\"dry_run\":true}" \
+        "Проверка применения правил (dry_run)"
+    test_endpoint "POST" "/category-rules/apply" \
+        "{\"category_id\":$OTHER_CATEGORY_ID}" \
+        "Применение правил к общей категории"
+
+    if [ -n "$RULE_ID" ]; then
+        test_endpoint "GET" "/category-rules/$RULE_ID" "" "Получение правила по ID"
+        test_endpoint "PATCH" "/category-rules/$RULE_ID" '{"is_active":false}' "Отключение правила"
+    fi
+
+    if [ -n "$WEEKDAY_RULE_ID" ]; then
+        test_endpoint "DELETE" "/category-rules/$WEEKDAY_RULE_ID" "" "Удаление правила"
+    fi
+fi
+
+print_stats
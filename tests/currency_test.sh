@@ -0,0 +1,66 @@
+#!/bin/bash
+
+# Тесты для Currency и Exchange Rate эндпоинтов
+
+source "$(dirname "$0")/common.sh"
+
+echo "=== Currency эндпоинты ==="
+
+# Создаем тестового пользователя
+USER_ID=$(create_test_user "currency_test_$(date +%s)@example.com" "currencytest")
+if [ -z "$USER_ID" ]; then
+    echo "  ⚠ Не удалось создать пользователя, используем ID=1"
+    USER_ID=1
+fi
+
+# Базовая валюта
+test_endpoint "GET" "/currency/base" "" "Получение базовой валюты"
+test_endpoint "PUT" "/currency/base" '{"currency":"RUB"}' "Установка базовой валюты"
+
+# Курсы валют
+test_endpoint "POST" "/exchange-rates" \
+    '{"from_currency":"EUR","to_currency":"RUB","date":"2024-01-01T00:00:00Z","rate":98.5}' \
+    "Добавление курса EUR/RUB" "RATE_ID"
+test_endpoint "GET" "/exchange-rates?from=EUR&to=RUB" "" "Список курсов EUR/RUB"
+
+# Импорт курсов из CSV
+TOTAL=$((TOTAL + 1))
+echo -n "  Testing POST /exchange-rates/import ... "
+import_file=$(mktemp)
+printf "date,from_currency,to_currency,rate\n2024-01-01,USD,RUB,90.1\n2024-01-01,TRY,RUB,3.05\n" > "$import_file"
+import_code=$(curl -s -o /dev/null -w "%{http_code}" -X "POST" "$BASE_URL/exchange-rates/import" -F "file=@$import_file")
+rm -f "$import_file"
+if [ "$import_code" -ge 200 ] && [ "$import_code" -lt 300 ]; then
+    SUCCESS=$((SUCCESS + 1))
+    echo -e "${GREEN}✓${NC} ($import_code)"
+elif [ "$import_code" -ge 400 ] && [ "$import_code" -lt 500 ]; then
+    CLIENT_ERROR=$((CLIENT_ERROR + 1))
+    echo -e "${YELLOW}⚠${NC} ($import_code) - Client Error"
+else
+    SERVER_ERROR=$((SERVER_ERROR + 1))
+    echo -e "${RED}✗${NC} ($import_code)"
+fi
+echo "    → Импорт курсов из CSV"
+
+# Расход в иностранной валюте
+category_response=$(curl -s -w "\n%{http_code}" -X "POST" "$BASE_URL/categories/$USER_ID" \
+    -H "Content-Type: application/json" \
+    -d '{"name":"Путешествия"}')
+category_code=$(echo "$category_response" | tail -n1)
+category_body=$(echo "$category_response" | sed '$d')
+if [ "$category_code" -ge 200 ] && [ "$category_code" -lt 300 ]; then
+    CATEGORY_ID=$(extract_id "$category_body")
+    current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+    test_endpoint "POST" "/expenses?user_id=$USER_ID" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":25,\"currency\":\"EUR\",\"description\":\"Кофе в Берлине\",\"date\":\"$current_date\"}" \
+        "Создание расхода в EUR" "EXPENSE_ID"
+    test_endpoint "POST" "/expenses?user_id=$USER_ID" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":25,\"currency\":\"JPY\",\"date\":\"$current_date\"}" \
+        "Расход в валюте без курса (ожидается 400)"
+fi
+
+if [ -n "$RATE_ID" ]; then
+    test_endpoint "DELETE" "/exchange-rates/$RATE_ID" "" "Удаление курса"
+fi
+
+print_stats
@@ -45,11 +45,137 @@ if [ -n "$CATEGORY_ID" ]; then
         test_endpoint "PATCH" "/expenses/$EXPENSE_ID" \
             '{"amount":1500.75,"description":"Обновленный расход"}' \
             "Обновление расхода"
+        test_endpoint "PATCH" "/expenses/$EXPENSE_ID" '{"category_id":999999}' \
+            "Перенос расхода в несуществующую категорию (ожидается 400)"
         
         # Удаление расхода
         test_endpoint "DELETE" "/expenses/$EXPENSE_ID" "" "Удаление расхода"
     fi
 fi
 
+# Разделение расхода по категориям
+test_endpoint "POST" "/categories/$USER_ID" '{"name":"Бытовая химия"}' "Создание второй категории" "HOUSEHOLD_CATEGORY_ID"
+if [ -n "$CATEGORY_ID" ] && [ -n "$HOUSEHOLD_CATEGORY_ID" ]; then
+    current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+    test_endpoint "POST" "/expenses" \
+        "{\"amount\":2500,\"description\":\"Чек из супермаркета\",\"date\":\"$current_date\",\"splits\":[{\"category_id\":$CATEGORY_ID,\"amount\":1800},{\"category_id\":$HOUSEHOLD_CATEGORY_ID,\"amount\":700,\"description\":\"Порошок\"}]}" \
+        "Создание разделенного расхода" "SPLIT_EXPENSE_ID"
+    test_endpoint "POST" "/expenses" \
+        "{\"amount\":2500,\"date\":\"$current_date\",\"splits\":[{\"category_id\":$CATEGORY_ID,\"amount\":1000},{\"category_id\":$HOUSEHOLD_CATEGORY_ID,\"amount\":700}]}" \
+        "Части не дают сумму расхода (ожидается 400)"
+    test_endpoint "GET" "/expenses?category_id=$HOUSEHOLD_CATEGORY_ID" "" "Расходы по категории части"
+
+    if [ -n "$SPLIT_EXPENSE_ID" ]; then
+        test_endpoint "PATCH" "/expenses/$SPLIT_EXPENSE_ID" \
+            "{\"amount\":3000,\"splits\":[{\"category_id\":$CATEGORY_ID,\"amount\":2000},{\"category_id\":$HOUSEHOLD_CATEGORY_ID,\"amount\":1000}]}" \
+            "Изменение суммы и частей расхода"
+        test_endpoint "PATCH" "/expenses/$SPLIT_EXPENSE_ID" '{"amount":3500}' "Изменение суммы без частей (ожидается 400)"
+        test_endpoint "PATCH" "/expenses/$SPLIT_EXPENSE_ID" '{"splits":[]}' "Отмена разделения расхода"
+    fi
+fi
+
+# Выгрузка расходов
+test_endpoint "GET" "/expenses/export?format=csv" "" "Выгрузка расходов в CSV"
+test_endpoint "GET" "/expenses/export?format=json&columns=date,amount,category,category_color" "" "Выгрузка выбранных столбцов в JSON"
+test_endpoint "GET" "/expenses/export?format=xlsx&min_amount=100&start_date=2024-01-01" "" "Выгрузка в XLSX с фильтром"
+test_endpoint "GET" "/expenses/export?format=pdf" "" "Неизвестный формат выгрузки (ожидается 400)"
+test_endpoint "GET" "/expenses/export?columns=unknown" "" "Неизвестный столбец (ожидается 400)"
+
+# Подсказка категории по истории расходов
+test_endpoint "POST" "/expenses/suggest-category" '{"description":"Тестовый расход","amount":950}' "Подсказка категории"
+test_endpoint "POST" "/expenses/suggest-category" '{"description":"Тестовый расход"}' "Подсказка категории без суммы (ожидается 400)"
+
+# Дубликаты расходов
+test_endpoint "GET" "/expenses/duplicates" "" "Поиск дубликатов"
+test_endpoint "GET" "/expenses/duplicates?days=3&similarity=0.3&start_date=2024-01-01" "" "Поиск дубликатов с параметрами"
+test_endpoint "GET" "/expenses/duplicates?days=100" "" "Слишком большое окно поиска (ожидается 400)"
+if [ -n "$CATEGORY_ID" ]; then
+    current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+    test_endpoint "POST" "/expenses" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":777,\"description\":\"Двойное нажатие\",\"date\":\"$current_date\"}" \
+        "Создание расхода" "KEEP_EXPENSE_ID"
+    test_endpoint "POST" "/expenses" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":777,\"description\":\"Двойное нажатие\",\"date\":\"$current_date\"}" \
+        "Создание дубликата расхода" "DUPLICATE_EXPENSE_ID"
+    if [ -n "$KEEP_EXPENSE_ID" ] && [ -n "$DUPLICATE_EXPENSE_ID" ]; then
+        test_endpoint "POST" "/expenses/duplicates/merge" \
+            "{\"keep_id\":$KEEP_EXPENSE_ID,\"duplicate_ids\":[$DUPLICATE_EXPENSE_ID]}" \
+            "Объединение дубликатов"
+        test_endpoint "POST" "/expenses/duplicates/merge" \
+            "{\"keep_id\":$KEEP_EXPENSE_ID,\"duplicate_ids\":[$KEEP_EXPENSE_ID]}" \
+            "Объединение расхода с самим собой (ожидается 400)"
+    fi
+    test_endpoint "POST" "/expenses" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":1500,\"description\":\"Другая покупка\",\"date\":\"$current_date\"}" \
+        "Создание другого расхода" "OTHER_EXPENSE_ID"
+    if [ -n "$KEEP_EXPENSE_ID" ] && [ -n "$OTHER_EXPENSE_ID" ]; then
+        test_endpoint "POST" "/expenses/duplicates/merge" \
+            "{\"keep_id\":$KEEP_EXPENSE_ID,\"duplicate_ids\":[$OTHER_EXPENSE_ID]}" \
+            "Объединение расходов, которые не являются дубликатами (ожидается 400)"
+    fi
+fi
+
+# Массовые операции
+if [ -n "$CATEGORY_ID" ] && [ -n "$HOUSEHOLD_CATEGORY_ID" ]; then
+    current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+    test_endpoint "POST" "/expenses" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":120,\"description\":\"PYATEROCHKA 1234\",\"date\":\"$current_date\"}" \
+        "Создание расхода для массовых операций" "BULK_EXPENSE_ID"
+    if [ -n "$BULK_EXPENSE_ID" ]; then
+        test_endpoint "POST" "/expenses/bulk/recategorize" \
+            "{\"ids\":[$BULK_EXPENSE_ID,999999],\"category_id\":$HOUSEHOLD_CATEGORY_ID}" \
+            "Массовая смена категории (999999 — not_found в отчете)"
+        test_endpoint "POST" "/expenses/bulk/describe" \
+            "{\"ids\":[$BULK_EXPENSE_ID],\"find\":\"PYATEROCHKA\",\"replace\":\"Пятерочка\"}" \
+            "Массовая замена в описаниях"
+        test_endpoint "POST" "/expenses/bulk/shift-dates" \
+            "{\"ids\":[$BULK_EXPENSE_ID],\"months\":-1,\"days\":2}" \
+            "Массовый сдвиг дат"
+        test_endpoint "POST" "/expenses/bulk/delete" \
+            "{\"ids\":[$BULK_EXPENSE_ID]}" \
+            "Массовое удаление в корзину"
+    fi
+    test_endpoint "POST" "/expenses/bulk/describe" \
+        "{\"filter\":{\"category_id\":$HOUSEHOLD_CATEGORY_ID,\"q\":\"порошок\"},\"description\":\"Бытовая химия\"}" \
+        "Массовая смена описаний по фильтру"
+fi
+test_endpoint "POST" "/expenses/bulk/delete" '{"filter":{}}' "Массовое удаление с пустым фильтром (ожидается 400)"
+test_endpoint "POST" "/expenses/bulk/delete" '{"ids":[1],"filter":{"min_amount":1}}' "Одновременно ids и filter (ожидается 400)"
+test_endpoint "POST" "/expenses/bulk/shift-dates" '{"ids":[1]}' "Сдвиг дат без сдвига (ожидается 400)"
+test_endpoint "POST" "/expenses/bulk/recategorize" '{"filter":{"start_date":"01.05.2026"},"category_id":1}' "Некорректная дата фильтра (ожидается 400)"
+
+# Полнотекстовый поиск
+if [ -n "$CATEGORY_ID" ]; then
+    current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+    test_endpoint "POST" "/expenses" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":3490,\"description\":\"Заказы Ozon: наушники\",\"date\":\"$current_date\"}" \
+        "Создание расхода для поиска"
+    test_endpoint "POST" "/expenses" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":990,\"description\":\"Ozon <b>чехол</b>\",\"date\":\"$current_date\"}" \
+        "Создание расхода с разметкой в описании"
+fi
+test_endpoint "GET" "/expenses?q=ozon" "" "Поиск расходов по слову"
+test_endpoint "GET" "/expenses?q=%D0%B7%D0%B0%D0%BA%D0%B0%D0%B7" "" "Поиск по другой словоформе (заказ)"
+test_endpoint "GET" "/expenses?q=%22ozon%20наушники%22%20-книги&min_amount=1000&limit=10" "" "Поиск фразы с исключением и фильтрами"
+test_endpoint "GET" "/expenses?q=ozon%20-%D1%87%D0%B5%D1%85%D0%BE%D0%BB" "" "Поиск с исключением слова (чехол)"
+test_endpoint "GET" "/expenses?q=%D1%87%D0%B5%D1%85%D0%BE%D0%BB" "" "Фрагмент с экранированной разметкой описания"
+test_endpoint "GET" "/expenses/export?format=json&q=ozon" "" "Выгрузка результатов поиска"
+
+# Пагинация по курсору
+test_endpoint "GET" "/expenses?limit=1&sort=amount&direction=asc" "" "Первая страница расходов по сумме"
+NEXT_CURSOR=$(echo "$body" | grep -o '"next_cursor":"[^"]*"' | cut -d'"' -f4)
+if [ -n "$NEXT_CURSOR" ]; then
+    test_endpoint "GET" "/expenses?limit=1&sort=amount&direction=asc&cursor=$NEXT_CURSOR" "" "Следующая страница по курсору"
+    test_endpoint "GET" "/expenses?limit=1&sort=date&cursor=$NEXT_CURSOR" "" "Курсор другой сортировки (ожидается 400)"
+fi
+test_endpoint "GET" "/expenses?sort=created_at&direction=asc" "" "Сортировка по времени создания"
+test_endpoint "GET" "/expenses?q=ozon&sort=date" "" "Поиск с сортировкой по дате"
+test_endpoint "GET" "/expenses?sort=relevance" "" "Сортировка по релевантности без q (ожидается 400)"
+test_endpoint "GET" "/expenses?limit=0" "" "Некорректный limit (ожидается 400)"
+test_endpoint "GET" "/expenses?offset=10" "" "Смещение не поддерживается (ожидается 400)"
+test_endpoint "GET" "/expenses?start_date=01.05.2026" "" "Некорректная дата фильтра (ожидается 400)"
+test_endpoint "GET" "/expenses?cursor=broken" "" "Некорректный курсор (ожидается 400)"
+test_endpoint "GET" "/logs?limit=5" "" "Журнал действий"
+
 print_stats
 
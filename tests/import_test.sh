@@ -0,0 +1,104 @@
+#!/bin/bash
+
+# Тесты для импорта расходов
+
+source "$(dirname "$0")/common.sh"
+
+echo "=== Import эндпоинты ==="
+
+# Создаем тестового пользователя
+USER_ID=$(create_test_user "import_test_$(date +%s)@example.com" "importtest")
+if [ -z "$USER_ID" ]; then
+    echo "  ⚠ Не удалось создать пользователя, используем ID=1"
+    USER_ID=1
+fi
+
+# test_upload отправляет файл multipart-формой: test_upload <endpoint> <file> <description> [поля формы -F ...]
+test_upload() {
+    local endpoint=$1
+    local file=$2
+    local description=$3
+    shift 3
+
+    TOTAL=$((TOTAL + 1))
+    echo -n "  Testing POST $endpoint ... "
+
+    local form_args=()
+    for field in "$@"; do
+        form_args+=(-F "$field")
+    done
+
+    local code
+    code=$(curl -s -o /dev/null -w "%{http_code}" -X "POST" "$BASE_URL$endpoint" -F "file=@$file" "${form_args[@]}")
+    if [ "$code" -ge 200 ] && [ "$code" -lt 300 ]; then
+        SUCCESS=$((SUCCESS + 1))
+        echo -e "${GREEN}✓${NC} ($code)"
+    elif [ "$code" -ge 400 ] && [ "$code" -lt 500 ]; then
+        CLIENT_ERROR=$((CLIENT_ERROR + 1))
+        echo -e "${YELLOW}⚠${NC} ($code) - Client Error"
+    else
+        SERVER_ERROR=$((SERVER_ERROR + 1))
+        echo -e "${RED}✗${NC} ($code)"
+    fi
+    echo "    → $description"
+}
+
+csv_file=$(mktemp)
+printf "Дата;Сумма;Описание;Категория\n01.03.2024;-1 250,50;Продукты;Еда\n02.03.2024;890;Такси;Транспорт\n03.03.2024;abc;Ошибка;Еда\n" > "$csv_file"
+
+# Проверка файла без сохранения
+test_upload "/expenses/import/csv" "$csv_file" "Проверка CSV (dry-run)" \
+    "date_column=Дата" "amount_column=Сумма" "description_column=Описание" "category_column=Категория" \
+    "has_header=true" "delimiter=;" "date_format=DD.MM.YYYY" "dry_run=true"
+
+# Файл с ошибками не загружается
+test_upload "/expenses/import/csv" "$csv_file" "Загрузка CSV с ошибками (ожидается 422)" \
+    "date_column=1" "amount_column=2" "description_column=3" "category_column=4" \
+    "has_header=true" "delimiter=;" "date_format=DD.MM.YYYY"
+
+# Корректный файл с созданием категорий
+printf "Дата;Сумма;Описание;Категория\n01.03.2024;-1 250,50;Продукты;Еда\n02.03.2024;890;Такси;Транспорт\n" > "$csv_file"
+test_upload "/expenses/import/csv" "$csv_file" "Загрузка CSV с созданием категорий" \
+    "date_column=Дата" "amount_column=Сумма" "description_column=Описание" "category_column=Категория" \
+    "has_header=true" "delimiter=;" "date_format=DD.MM.YYYY" "create_categories=true"
+rm -f "$csv_file"
+
+# Категория для операций из выписок
+category_response=$(curl -s -w "\n%{http_code}" -X "POST" "$BASE_URL/categories/$USER_ID" \
+    -H "Content-Type: application/json" \
+    -d '{"name":"Банк"}')
+category_code=$(echo "$category_response" | tail -n1)
+category_body=$(echo "$category_response" | sed '$d')
+if [ "$category_code" -ge 200 ] && [ "$category_code" -lt 300 ]; then
+    CATEGORY_ID=$(extract_id "$category_body")
+    echo "  ✓ Категория создана (ID: $CATEGORY_ID)"
+else
+    echo "  ⚠ Не удалось создать категорию, используем ID=1"
+    CATEGORY_ID=1
+fi
+
+# Выписка OFX: одно списание и одно поступление
+ofx_file=$(mktemp)
+cat > "$ofx_file" <<'OFX'
+OFXHEADER:100
+DATA:OFXSGML
+
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>RUB
+<BANKACCTFROM><ACCTID>40817810000000000001</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240301<TRNAMT>-450.00<FITID>TX-1001<NAME>Кофейня</STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20240302<TRNAMT>1200.00<FITID>TX-1002<NAME>Возврат</STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>
+OFX
+test_upload "/expenses/import/ofx" "$ofx_file" "Проверка выписки OFX (dry-run)" "category_id=$CATEGORY_ID" "dry_run=true"
+test_upload "/expenses/import/ofx" "$ofx_file" "Загрузка выписки OFX" "category_id=$CATEGORY_ID"
+test_upload "/expenses/import/ofx" "$ofx_file" "Повторная загрузка выписки OFX (дубликаты пропускаются)" "category_id=$CATEGORY_ID"
+rm -f "$ofx_file"
+
+# Выписка QIF
+qif_file=$(mktemp)
+printf "!Type:Bank\nD03/05/2024\nT-320.00\nPАптека\n^\nD03/06/2024\nT5000.00\nPЗарплата\n^\n" > "$qif_file"
+test_upload "/expenses/import/qif" "$qif_file" "Загрузка выписки QIF" "category_id=$CATEGORY_ID"
+rm -f "$qif_file"
+
+print_stats
@@ -0,0 +1,62 @@
+#!/bin/bash
+
+# Тесты для Income эндпоинтов
+
+source "$(dirname "$0")/common.sh"
+
+echo "=== Income эндпоинты ==="
+
+# Создаем тестового пользователя
+USER_ID=$(create_test_user "income_test_$(date +%s)@example.com" "incometest")
+if [ -z "$USER_ID" ]; then
+    echo "  ⚠ Не удалось создать пользователя, используем ID=1"
+    USER_ID=1
+fi
+
+# Создаем категорию доходов
+category_response=$(curl -s -w "\n%{http_code}" -X "POST" "$BASE_URL/categories/$USER_ID" \
+    -H "Content-Type: application/json" \
+    -d '{"name":"Категория для доходов","type":"income"}')
+category_code=$(echo "$category_response" | tail -n1)
+category_body=$(echo "$category_response" | sed '$d')
+if [ "$category_code" -ge 200 ] && [ "$category_code" -lt 300 ]; then
+    CATEGORY_ID=$(extract_id "$category_body")
+    echo "  ✓ Категория создана (ID: $CATEGORY_ID)"
+else
+    echo "  ⚠ Не удалось создать категорию, используем ID=1"
+    CATEGORY_ID=1
+fi
+
+# Список категорий доходов
+test_endpoint "GET" "/categories/$USER_ID?type=income" "" "Список категорий доходов"
+
+# Список доходов
+test_endpoint "GET" "/incomes" "" "Список доходов"
+
+# Создание дохода
+if [ -n "$CATEGORY_ID" ]; then
+    current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+    test_endpoint "POST" "/incomes" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":50000,\"description\":\"Тестовый доход\",\"date\":\"$current_date\"}" \
+        "Создание дохода" "INCOME_ID"
+
+    # Расход в категории доходов должен быть отклонен
+    test_endpoint "POST" "/expenses?user_id=$USER_ID" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":100,\"date\":\"$current_date\"}" \
+        "Создание расхода в категории доходов (ожидается 400)"
+
+    # Получение дохода по ID
+    if [ -n "$INCOME_ID" ]; then
+        test_endpoint "GET" "/incomes/$INCOME_ID" "" "Получение дохода по ID"
+
+        # Обновление дохода
+        test_endpoint "PATCH" "/incomes/$INCOME_ID" \
+            '{"amount":55000,"description":"Обновленный доход"}' \
+            "Обновление дохода"
+
+        # Удаление дохода
+        test_endpoint "DELETE" "/incomes/$INCOME_ID" "" "Удаление дохода"
+    fi
+fi
+
+print_stats
@@ -0,0 +1,53 @@
+#!/bin/bash
+
+# Тесты для планов рассрочки
+
+source "$(dirname "$0")/common.sh"
+
+echo "=== Installment эндпоинты ==="
+
+# Создаем тестового пользователя
+USER_ID=$(create_test_user "installments_test_$(date +%s)@example.com" "installmentstest")
+if [ -z "$USER_ID" ]; then
+    echo "  ⚠ Не удалось создать пользователя, используем ID=1"
+    USER_ID=1
+fi
+
+# Первый платеж два месяца назад: два платежа создаются расходами сразу
+start_date=$(date -u -d "2 months ago" +"%Y-%m-%dT00:00:00Z")
+
+test_endpoint "POST" "/categories/$USER_ID" '{"name":"Техника"}' "Создание категории" "CATEGORY_ID"
+
+test_endpoint "POST" "/installments" \
+    "{\"category_id\":${CATEGORY_ID:-1},\"description\":\"Ноутбук\",\"total_amount\":100000,\"payments\":12,\"start_date\":\"$start_date\"}" \
+    "Создание плана рассрочки" "PLAN_ID"
+test_endpoint "POST" "/installments" \
+    "{\"category_id\":${CATEGORY_ID:-1},\"total_amount\":1000,\"payments\":361,\"start_date\":\"$start_date\"}" \
+    "Создание плана с 361 платежом (ожидается 400)"
+test_endpoint "POST" "/installments" \
+    "{\"category_id\":${CATEGORY_ID:-1},\"total_amount\":0,\"payments\":6,\"start_date\":\"$start_date\"}" \
+    "Создание плана с нулевой суммой (ожидается 400)"
+
+test_endpoint "GET" "/installments" "" "Список планов рассрочки"
+test_endpoint "GET" "/installments/upcoming" "" "Ближайшие платежи за 30 дней"
+test_endpoint "GET" "/installments/upcoming?days=90" "" "Ближайшие платежи за 90 дней"
+test_endpoint "GET" "/installments/upcoming?days=0" "" "Ближайшие платежи с days=0 (ожидается 400)"
+
+if [ -n "$PLAN_ID" ]; then
+    test_endpoint "GET" "/installments/$PLAN_ID" "" "Получение плана рассрочки"
+    test_endpoint "GET" "/installments/$PLAN_ID/schedule" "" "График платежей"
+    test_endpoint "PATCH" "/installments/$PLAN_ID" '{"description":"Ноутбук для работы"}' "Изменение описания плана"
+    test_endpoint "PATCH" "/installments/$PLAN_ID" '{"category_id":999999}' "Изменение на несуществующую категорию (ожидается 400)"
+    test_endpoint "GET" "/expenses?category_id=${CATEGORY_ID:-1}" "" "Созданные расходы-платежи" "PAYMENT_EXPENSE_ID"
+    if [ -n "$PAYMENT_EXPENSE_ID" ]; then
+        test_endpoint "DELETE" "/expenses/$PAYMENT_EXPENSE_ID" "" "Удаление расхода-платежа"
+        test_endpoint "GET" "/installments/$PLAN_ID" "" "Выплаченная сумма и оставшиеся платежи без удаленного платежа"
+    fi
+    test_endpoint "DELETE" "/installments/$PLAN_ID" "" "Удаление плана рассрочки"
+    test_endpoint "GET" "/installments/$PLAN_ID" "" "Получение удаленного плана (ожидается 404)"
+fi
+
+test_endpoint "GET" "/installments/abc" "" "Получение плана с некорректным ID (ожидается 400)"
+test_endpoint "GET" "/installments/999999" "" "Получение несуществующего плана (ожидается 404)"
+
+print_stats
@@ -29,6 +29,7 @@ fi
 
 # Список регулярных расходов
 test_endpoint "GET" "/recurring-expenses?user_id=$USER_ID" "" "Список регулярных расходов"
+test_endpoint "GET" "/recurring-expenses?user_id=$USER_ID&sort=created_at&limit=10" "" "Список регулярных расходов по времени создания"
 
 # Создание регулярного расхода
 if [ -n "$CATEGORY_ID" ]; then
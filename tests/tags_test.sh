@@ -0,0 +1,69 @@
+#!/bin/bash
+
+# Тесты для Tag эндпоинтов
+
+source "$(dirname "$0")/common.sh"
+
+echo "=== Tag эндпоинты ==="
+
+# Создаем тестового пользователя
+USER_ID=$(create_test_user "tag_test_$(date +%s)@example.com" "tagtest")
+if [ -z "$USER_ID" ]; then
+    echo "  ⚠ Не удалось создать пользователя, используем ID=1"
+    USER_ID=1
+fi
+
+# Создаем категорию расходов
+category_response=$(curl -s -w "\n%{http_code}" -X "POST" "$BASE_URL/categories/$USER_ID" \
+    -H "Content-Type: application/json" \
+    -d '{"name":"Категория для тегов"}')
+category_code=$(echo "$category_response" | tail -n1)
+category_body=$(echo "$category_response" | sed '$d')
+if [ "$category_code" -ge 200 ] && [ "$category_code" -lt 300 ]; then
+    CATEGORY_ID=$(extract_id "$category_body")
+    echo "  ✓ Категория создана (ID: $CATEGORY_ID)"
+else
+    echo "  ⚠ Не удалось создать категорию, используем ID=1"
+    CATEGORY_ID=1
+fi
+
+# Список тегов
+test_endpoint "GET" "/tags" "" "Список тегов"
+
+# Создание тегов
+test_endpoint "POST" "/tags" '{"name":"отпуск-2026","color":"#F59E0B"}' "Создание тега" "VACATION_TAG_ID"
+test_endpoint "POST" "/tags" '{"name":"работа"}' "Создание второго тега" "WORK_TAG_ID"
+
+# Повторное название должно быть отклонено
+test_endpoint "POST" "/tags" '{"name":"Работа"}' "Создание тега с существующим названием (ожидается 400)"
+
+if [ -n "$VACATION_TAG_ID" ] && [ -n "$WORK_TAG_ID" ]; then
+    current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+
+    test_endpoint "GET" "/tags/$VACATION_TAG_ID" "" "Получение тега по ID"
+    test_endpoint "PATCH" "/tags/$WORK_TAG_ID" '{"color":"#10B981"}' "Обновление тега"
+
+    # Расход с тегами
+    test_endpoint "POST" "/expenses" \
+        "{\"category_id\":$CATEGORY_ID,\"amount\":1500,\"description\":\"Билеты\",\"date\":\"$current_date\",\"tag_ids\":[$VACATION_TAG_ID,$WORK_TAG_ID]}" \
+        "Создание расхода с тегами" "EXPENSE_ID"
+
+    # Фильтры по тегам
+    test_endpoint "GET" "/expenses?tag_ids=$VACATION_TAG_ID" "" "Расходы с тегом"
+    test_endpoint "GET" "/expenses?exclude_tag_ids=$WORK_TAG_ID" "" "Расходы без тега"
+
+    if [ -n "$EXPENSE_ID" ]; then
+        test_endpoint "PATCH" "/expenses/$EXPENSE_ID" \
+            "{\"tag_ids\":[$VACATION_TAG_ID]}" \
+            "Замена тегов расхода"
+    fi
+
+    # Статистика по тегам
+    start_date=$(date -u +"%Y-%m-01")
+    end_date=$(date -u +"%Y-%m-%d")
+    test_endpoint "GET" "/statistics/period?user_id=$USER_ID&period=month&start_date=$start_date&end_date=$end_date" "" "Статистика с разбивкой по тегам"
+
+    test_endpoint "DELETE" "/tags/$WORK_TAG_ID" "" "Удаление тега"
+fi
+
+print_stats
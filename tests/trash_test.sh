@@ -0,0 +1,59 @@
+#!/bin/bash
+
+# Тесты для корзины
+
+source "$(dirname "$0")/common.sh"
+
+echo "=== Trash эндпоинты ==="
+
+# Создаем тестового пользователя
+USER_ID=$(create_test_user "trash_test_$(date +%s)@example.com" "trashtest")
+if [ -z "$USER_ID" ]; then
+    echo "  ⚠ Не удалось создать пользователя, используем ID=1"
+    USER_ID=1
+fi
+
+current_date=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+current_month=$(date +%m | sed 's/^0//')
+current_year=$(date +%Y)
+
+test_endpoint "POST" "/categories/$USER_ID" '{"name":"Хобби"}' "Создание категории" "CATEGORY_ID"
+test_endpoint "POST" "/expenses" \
+    "{\"category_id\":${CATEGORY_ID:-1},\"amount\":1200,\"description\":\"Краски\",\"date\":\"$current_date\"}" \
+    "Создание расхода" "EXPENSE_ID"
+test_endpoint "POST" "/budgets?user_id=$USER_ID" \
+    "{\"amount\":5000,\"month\":$current_month,\"year\":$current_year}" \
+    "Создание бюджета" "BUDGET_ID"
+
+test_endpoint "GET" "/trash" "" "Пустая корзина"
+test_endpoint "GET" "/trash?type=unknown" "" "Корзина с неизвестным типом (ожидается 400)"
+
+if [ -n "$EXPENSE_ID" ]; then
+    test_endpoint "DELETE" "/expenses/$EXPENSE_ID" "" "Удаление расхода в корзину"
+    test_endpoint "GET" "/trash?type=expense" "" "Удаленные расходы"
+    test_endpoint "POST" "/trash/expense/$EXPENSE_ID/restore" "" "Восстановление расхода"
+    test_endpoint "GET" "/expenses/$EXPENSE_ID" "" "Получение восстановленного расхода"
+    test_endpoint "POST" "/trash/expense/$EXPENSE_ID/restore" "" "Повторное восстановление (ожидается 404)"
+fi
+
+if [ -n "$CATEGORY_ID" ] && [ -n "$EXPENSE_ID" ]; then
+    # Категорию с расходом из корзины нельзя удалить безвозвратно, а расход без категории — восстановить
+    test_endpoint "DELETE" "/expenses/$EXPENSE_ID" "" "Удаление расхода в корзину"
+    test_endpoint "DELETE" "/categories/$CATEGORY_ID" "" "Удаление категории в корзину"
+    test_endpoint "POST" "/trash/expense/$EXPENSE_ID/restore" "" "Восстановление расхода удаленной категории (ожидается 409)"
+    test_endpoint "DELETE" "/trash/category/$CATEGORY_ID" "" "Безвозвратное удаление используемой категории (ожидается 409)"
+    test_endpoint "POST" "/trash/category/$CATEGORY_ID/restore" "" "Восстановление категории"
+    test_endpoint "POST" "/trash/expense/$EXPENSE_ID/restore" "" "Восстановление расхода"
+fi
+
+if [ -n "$BUDGET_ID" ]; then
+    test_endpoint "DELETE" "/budgets/$BUDGET_ID" "" "Удаление бюджета в корзину"
+    test_endpoint "DELETE" "/trash/budget/$BUDGET_ID" "" "Безвозвратное удаление бюджета"
+    test_endpoint "POST" "/trash/budget/$BUDGET_ID/restore" "" "Восстановление удаленного безвозвратно бюджета (ожидается 404)"
+fi
+
+test_endpoint "POST" "/trash/expense/abc/restore" "" "Восстановление с некорректным ID (ожидается 400)"
+test_endpoint "DELETE" "/trash/expense/999999" "" "Удаление несуществующей записи (ожидается 404)"
+test_endpoint "DELETE" "/trash" "" "Очистка корзины"
+
+print_stats
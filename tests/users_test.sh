@@ -33,5 +33,44 @@ if [ -n "$USER_ID" ]; then
         "Обновление пользователя"
 fi
 
+# Выгрузка данных текущего пользователя
+test_endpoint "GET" "/users/me/export?format=json" "" "Выгрузка данных пользователя в JSON"
+test_endpoint "GET" "/users/me/export" "" "Выгрузка данных пользователя в ZIP"
+
+# Восстановление из выгрузки
+archive_file=$(mktemp)
+curl -s -o "$archive_file" "$BASE_URL/users/me/export?format=json"
+TOTAL=$((TOTAL + 1))
+echo -n "  Testing POST /users/me/import ... "
+code=$(curl -s -o /dev/null -w "%{http_code}" -X "POST" "$BASE_URL/users/me/import" -F "file=@$archive_file")
+if [ "$code" -ge 200 ] && [ "$code" -lt 300 ]; then
+    SUCCESS=$((SUCCESS + 1))
+    echo -e "${GREEN}✓${NC} ($code)"
+elif [ "$code" -ge 400 ] && [ "$code" -lt 500 ]; then
+    CLIENT_ERROR=$((CLIENT_ERROR + 1))
+    echo -e "${YELLOW}⚠${NC} ($code) - Client Error"
+else
+    SERVER_ERROR=$((SERVER_ERROR + 1))
+    echo -e "${RED}✗${NC} ($code)"
+fi
+echo "    → Восстановление данных из выгрузки"
+
+# Повторное восстановление того же архива не создает дубликатов
+TOTAL=$((TOTAL + 1))
+echo -n "  Testing POST /users/me/import (повторно) ... "
+body=$(curl -s -X "POST" "$BASE_URL/users/me/import" -F "file=@$archive_file")
+if echo "$body" | grep -q '"expenses":0,' && echo "$body" | grep -q '"incomes":0,'; then
+    SUCCESS=$((SUCCESS + 1))
+    echo -e "${GREEN}✓${NC}"
+else
+    CLIENT_ERROR=$((CLIENT_ERROR + 1))
+    echo -e "${YELLOW}⚠${NC} - записи созданы повторно"
+fi
+echo "    → Повторное восстановление пропускает существующие записи"
+rm -f "$archive_file"
+
+# Удаление учетной записи без подтверждения
+test_endpoint "DELETE" "/users/me" '{"confirm":"yes"}' "Удаление без подтверждения (ожидается 400)"
+
 print_stats
 